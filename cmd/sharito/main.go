@@ -3,13 +3,20 @@ package main
 import (
 	"backend/internal/configs"
 	"backend/internal/domain"
+	"backend/internal/i18n"
+	"backend/internal/infra/blob"
 	"backend/internal/infra/http"
+	"backend/internal/infra/imaging"
+	"backend/internal/infra/mail"
+	"backend/internal/infra/memory"
+	"backend/internal/infra/oidc"
 	"backend/internal/infra/postgres"
 	"backend/internal/infra/security"
 	"backend/pkg/logging"
 	"context"
 	"fmt"
 	"github.com/jessevdk/go-flags"
+	"github.com/sirupsen/logrus"
 	"os"
 	"os/signal"
 	"syscall"
@@ -34,6 +41,11 @@ func main() {
 		panic(err)
 	}
 
+	// Report missing translations
+	for lang, keys := range i18n.Missing() {
+		logger.WithField("language", lang).WithField("keys", keys).Warn("Missing translations, English is used instead!")
+	}
+
 	// Init PostgreSQL
 	db, err := postgres.NewAdapter(logger, config.Postgres)
 	if err != nil {
@@ -41,10 +53,43 @@ func main() {
 	}
 
 	// Init Security
-	sec, err := security.NewAdapter(logger, config.Security)
+	sec, err := security.NewAdapter(logger, config.Security, config.Service.AccessTokenTTL)
+	if err != nil {
+		logger.WithError(err).Fatal("Error while creating a new security adapter!")
+	}
+
+	// Init mailer
+	mailer, err := mail.NewAdapter(logger, config.Mail)
+	if err != nil {
+		logger.WithError(err).Fatal("Error while creating a new mail adapter!")
+	}
+
+	// Init OpenID Connect providers
+	oidcAdapter, err := oidc.NewAdapter(logger, config.OIDC)
+	if err != nil {
+		logger.WithError(err).Fatal("Error while creating a new OIDC adapter!")
+	}
+
+	// Init blob store
+	blobs, err := blob.NewAdapter(logger, config.Blob)
+	if err != nil {
+		logger.WithError(err).Fatal("Error while creating a new blob store adapter!")
+	}
+
+	// Init image processor
+	images, err := imaging.NewAdapter(logger, config.Imaging)
+	if err != nil {
+		logger.WithError(err).Fatal("Error while creating a new image processing adapter!")
+	}
+
+	// Init login attempts store
+	var loginAttempts domain.LoginAttemptStore = db
+	if config.Service.LoginAttemptsStore == "memory" {
+		loginAttempts = memory.NewLoginAttemptStore()
+	}
 
 	// Init service
-	service := domain.NewService(logger, db, sec)
+	service := domain.NewService(logger, config.Service, db, sec, mailer, loginAttempts, oidcAdapter, blobs, images)
 
 	// Init HTTP adapter
 	httpAdapter, err := http.NewAdapter(logger, config.HTTP, service)
@@ -52,6 +97,11 @@ func main() {
 		logger.WithError(err).Fatal("Error creating new HTTP adapter!")
 	}
 
+	// Send the queued emails in the background
+	outboxCtx, stopOutbox := context.WithCancel(context.Background())
+	defer stopOutbox()
+	go runOutbox(outboxCtx, logger, service, config.Service.OutboxInterval)
+
 	shutdown := make(chan error, 1)
 
 	go func(shutdown chan<- error) {
@@ -73,13 +123,35 @@ func main() {
 
 	logger.Info("Stopping application...")
 
+	stopOutbox()
+
 	if err := httpAdapter.Shutdown(ctx); err != nil {
 		logger.WithError(err).Error("Error shutting down the HTTP server!")
 	}
 
+	if err := sec.Close(); err != nil {
+		logger.WithError(err).Error("Error closing the security adapter!")
+	}
+
 	time.Sleep(time.Second)
 
 	logger.Info("The application stopped.")
 
 	fmt.Print(logger)
 }
+
+func runOutbox(ctx context.Context, logger logrus.FieldLogger, service domain.OutboxService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := service.SendPendingEmails(); err != nil {
+				logger.WithError(err).Error("Error while sending pending emails!")
+			}
+		}
+	}
+}
@@ -1,7 +1,12 @@
 package configs
 
 import (
+	"backend/internal/domain"
+	"backend/internal/infra/blob"
 	"backend/internal/infra/http"
+	"backend/internal/infra/imaging"
+	"backend/internal/infra/mail"
+	"backend/internal/infra/oidc"
 	"backend/internal/infra/postgres"
 	"backend/internal/infra/security"
 	"backend/pkg/logging"
@@ -11,9 +16,14 @@ import (
 
 type Config struct {
 	Logger   *logging.Config  `group:"Logger args" namespace:"logger" env-namespace:"SHARITO_LOGGER"`
+	Service  *domain.Config   `group:"Service args" namespace:"service" env-namespace:"SHARITO_SERVICE"`
 	HTTP     *http.Config     `group:"HTTP args" namespace:"http" env-namespace:"SHARITO_HTTP"`
 	Postgres *postgres.Config `group:"Postgres args" namespace:"postgres" env-namespace:"SHARITO_POSTGRES"`
 	Security *security.Config `group:"Security args" namespace:"security" env-namespace:"SHARITO_SECURITY"`
+	Mail     *mail.Config     `group:"Mail args" namespace:"mail" env-namespace:"SHARITO_MAIL"`
+	OIDC     *oidc.Config     `group:"OpenID Connect args" namespace:"oidc" env-namespace:"SHARITO_OIDC"`
+	Blob     *blob.Config     `group:"Blob store args" namespace:"blob" env-namespace:"SHARITO_BLOB"`
+	Imaging  *imaging.Config  `group:"Image processing args" namespace:"imaging" env-namespace:"SHARITO_IMAGING"`
 }
 
 func Parse() (*Config, error) {
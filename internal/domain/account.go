@@ -0,0 +1,97 @@
+package domain
+
+import "context"
+
+// UserExport is the personal data of the user, see ExportUserData.
+type UserExport struct {
+	User          *User
+	Products      []*Product
+	Orders        []*Order
+	ProductOrders []*Order // the orders of the products of the user
+	Sessions      []*Session
+	Identities    []*Identity
+}
+
+// ExportUserData collects everything the service stores about the current user.
+func (s *service) ExportUserData(ctx context.Context) (*UserExport, error) {
+	user, err := s.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := s.db.GetOwnedProducts(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.resolvePhotos(products...); err != nil {
+		return nil, err
+	}
+
+	orders, err := s.db.GetOrders(user.ID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	productOrders, err := s.db.GetOrders(user.ID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.db.GetActiveSessions(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	identities, err := s.db.GetIdentities(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UserExport{
+		User:          user,
+		Products:      products,
+		Orders:        orders,
+		ProductOrders: productOrders,
+		Sessions:      sessions,
+		Identities:    identities,
+	}, nil
+}
+
+// openOrderStatuses are the statuses of the orders that block the deletion of their parties.
+var openOrderStatuses = []OrderStatus{OrderStatusPending, OrderStatusApproved, OrderStatusActive}
+
+// DeleteUser erases the personal data of the current user. The user row stays anonymised
+// and the products are archived without their photos, so that the orders remain intact for the other party.
+func (s *service) DeleteUser(ctx context.Context, password string) error {
+	user, err := s.GetUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := s.verifyCurrentPassword(ctx, user, password); err != nil {
+		return err
+	}
+
+	// the photos and the avatar are deleted along with the personal data, their blobs are collected beforehand
+	products, err := s.db.GetOwnedProducts(user.ID)
+	if err != nil {
+		return err
+	}
+
+	// the open orders are checked along with the anonymization, so that none is placed in between
+	if err := s.db.AnonymizeUser(user.ID, openOrderStatuses); err != nil {
+		return err
+	}
+
+	var keys []string
+	if user.AvatarKey != nil {
+		keys = append(keys, *user.AvatarKey)
+	}
+	for _, product := range products {
+		keys = append(keys, photoBlobKeys(product.Photos...)...)
+	}
+	s.deleteBlobs(ctx, keys)
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package domain
+
+import "context"
+
+func (s *service) GetUsers(ctx context.Context, page, count int) ([]*User, int, error) {
+	return s.db.GetUsers(count, page*count)
+}
+
+// BlockUser prevents the user from signing in and signs them out everywhere.
+func (s *service) BlockUser(ctx context.Context, userID int, reason string) error {
+	if _, err := s.getSubordinateUser(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := s.db.BlockUser(userID, reason); err != nil {
+		return err
+	}
+
+	return s.db.RevokeUserSessions(userID, 0, RevokeReasonBlocked)
+}
+
+func (s *service) UnblockUser(ctx context.Context, userID int) error {
+	if _, err := s.getSubordinateUser(ctx, userID); err != nil {
+		return err
+	}
+
+	return s.db.UnblockUser(userID)
+}
+
+// SetUserRole changes the role of the user, the sessions are revoked so that the new role takes effect at once.
+func (s *service) SetUserRole(ctx context.Context, userID int, role Role) error {
+	if !role.Valid() {
+		return ErrInvalidInputData
+	}
+
+	if userID == ctx.Value(ContextUserID).(int) {
+		return ErrForbidden
+	}
+
+	user, err := s.db.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if user.Role == role {
+		return nil
+	}
+
+	if err := s.db.SetUserRole(userID, role); err != nil {
+		return err
+	}
+
+	return s.db.RevokeUserSessions(userID, 0, RevokeReasonRoleChanged)
+}
+
+// HideProduct takes the product down by moderation, unlike the archived products it can be brought back.
+func (s *service) HideProduct(ctx context.Context, productID int, reason string) error {
+	product, err := s.db.GetProductByID(productID)
+	if err != nil {
+		return err
+	}
+	if product == nil {
+		return ErrNoSuchProduct
+	}
+
+	return s.db.HideProduct(productID, reason)
+}
+
+func (s *service) UnhideProduct(ctx context.Context, productID int) error {
+	product, err := s.db.GetProductByID(productID)
+	if err != nil {
+		return err
+	}
+	if product == nil {
+		return ErrNoSuchProduct
+	}
+
+	return s.db.UnhideProduct(productID)
+}
+
+func (s *service) GetAllOrders(ctx context.Context, page, count int) ([]*Order, int, error) {
+	orders, total, err := s.db.GetAllOrders(count, page*count)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return orders, total, s.loadOrderRelations(orders)
+}
+
+// getSubordinateUser returns the user if the current user outranks them.
+func (s *service) getSubordinateUser(ctx context.Context, userID int) (*User, error) {
+	role, _ := ctx.Value(ContextRole).(Role)
+
+	user, err := s.db.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !role.Outranks(user.Role) {
+		return nil, ErrForbidden
+	}
+
+	return user, nil
+}
@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// maxAvailabilityWindow limits the period the availability calendar is requested for.
+const maxAvailabilityWindow = 366 * 24 * time.Hour
+
+type Interval struct {
+	From time.Time
+	To   time.Time
+}
+
+// Blackout is a period set by the owner when the product cannot be rented.
+type Blackout struct {
+	ID        int
+	ProductID int
+	From      time.Time
+	To        time.Time
+	Reason    *string
+	CreatedAt time.Time
+}
+
+type Availability struct {
+	From      time.Time
+	To        time.Time
+	Booked    []Interval
+	Blackouts []*Blackout
+	Free      []Interval
+}
+
+// freeIntervals returns the parts of the window not covered by any of the busy intervals.
+func freeIntervals(window Interval, busy []Interval) []Interval {
+	sorted := make([]Interval, len(busy))
+	copy(sorted, busy)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].From.Before(sorted[j].From)
+	})
+
+	free := make([]Interval, 0)
+	cursor := window.From
+	for _, v := range sorted {
+		if !v.To.After(cursor) {
+			continue
+		}
+		if !v.From.Before(window.To) {
+			break
+		}
+		if v.From.After(cursor) {
+			free = append(free, Interval{From: cursor, To: v.From})
+		}
+		cursor = v.To
+	}
+
+	if cursor.Before(window.To) {
+		free = append(free, Interval{From: cursor, To: window.To})
+	}
+
+	return free
+}
@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+type ProductSort string
+
+const (
+	// ProductSortRelevance puts the best matches of the search first, the products are listed by id without a search
+	ProductSortRelevance ProductSort = "relevance"
+	ProductSortPriceAsc  ProductSort = "price_asc"
+	ProductSortPriceDesc ProductSort = "price_desc"
+	ProductSortNewest    ProductSort = "newest"
+	// ProductSortRating puts the products with the best reviews of their owners first
+	ProductSortRating ProductSort = "rating"
+)
+
+func (s ProductSort) Valid() bool {
+	switch s {
+	case ProductSortRelevance, ProductSortPriceAsc, ProductSortPriceDesc, ProductSortNewest, ProductSortRating:
+		return true
+	}
+
+	return false
+}
+
+// ProductQuery filters and sorts the listed products, the empty fields do not filter.
+type ProductQuery struct {
+	Search     string
+	MinPerHour *float64
+	MaxPerHour *float64
+	Category   ProductCategory
+	OwnerID    *int
+	// AvailableFrom and AvailableTo leave only the products free for the whole window, they are set together
+	AvailableFrom *time.Time
+	AvailableTo   *time.Time
+	Sort          ProductSort
+}
+
+// Normalize trims the search and fills in the default sort.
+func (q *ProductQuery) Normalize() {
+	q.Search = strings.TrimSpace(q.Search)
+	if q.Sort == "" {
+		q.Sort = ProductSortRelevance
+	}
+}
+
+func (q *ProductQuery) Validate() error {
+	if !q.Sort.Valid() {
+		return ErrInvalidInputData
+	}
+
+	if q.Category != "" && !q.Category.Valid() {
+		return ErrInvalidInputData
+	}
+
+	if q.MinPerHour != nil && q.MaxPerHour != nil && *q.MinPerHour > *q.MaxPerHour {
+		return ErrInvalidInputData
+	}
+
+	if (q.AvailableFrom == nil) != (q.AvailableTo == nil) {
+		return ErrInvalidInputData
+	}
+
+	if q.AvailableFrom != nil && !q.AvailableFrom.Before(*q.AvailableTo) {
+		return ErrInvalidInputData
+	}
+
+	return nil
+}
@@ -0,0 +1,31 @@
+package domain
+
+import "time"
+
+type Config struct {
+	AccessTokenTTL        time.Duration `long:"access-token-ttl" env:"ACCESS_TOKEN_TTL" default:"15m" description:"Access token lifetime"`
+	RefreshTokenTTL       time.Duration `long:"refresh-token-ttl" env:"REFRESH_TOKEN_TTL" default:"720h" description:"Refresh token lifetime, the session expires if it is not refreshed during this period"`
+	PasswordResetTTL      time.Duration `long:"password-reset-ttl" env:"PASSWORD_RESET_TTL" default:"1h" description:"Password reset link lifetime"`
+	EmailVerificationTTL  time.Duration `long:"email-verification-ttl" env:"EMAIL_VERIFICATION_TTL" default:"72h" description:"Email verification link lifetime"`
+	RequireVerifiedEmail  bool          `long:"require-verified-email" env:"REQUIRE_VERIFIED_EMAIL" description:"Allow only the users with a verified email to list and rent products"`
+	TwoFactorChallengeTTL time.Duration `long:"two-factor-challenge-ttl" env:"TWO_FACTOR_CHALLENGE_TTL" default:"5m" description:"Time to enter the two-factor authentication code after the password"`
+	OIDCLoginTTL          time.Duration `long:"oidc-login-ttl" env:"OIDC_LOGIN_TTL" default:"10m" description:"Time to authenticate at the identity provider"`
+	PublicURL             string        `long:"public-url" env:"PUBLIC_URL" default:"http://localhost:3000" description:"Base URL of the web application the links in the emails point to"`
+
+	MaxPhotoSize     int64         `long:"max-photo-size" env:"MAX_PHOTO_SIZE" default:"10485760" description:"Max size of an uploaded photo in bytes"`
+	MaxProductPhotos int           `long:"max-product-photos" env:"MAX_PRODUCT_PHOTOS" default:"10" description:"Max number of photos of a product"`
+	PhotoURLTTL      time.Duration `long:"photo-url-ttl" env:"PHOTO_URL_TTL" default:"1h" description:"Lifetime of the signed photo URLs"`
+
+	OutboxInterval    time.Duration `long:"outbox-interval" env:"OUTBOX_INTERVAL" default:"10s" description:"How often the pending emails are sent"`
+	OutboxBatchSize   int           `long:"outbox-batch-size" env:"OUTBOX_BATCH_SIZE" default:"20" description:"Max number of emails sent at once"`
+	OutboxMaxAttempts int           `long:"outbox-max-attempts" env:"OUTBOX_MAX_ATTEMPTS" default:"10" description:"Number of attempts to send an email before giving up"`
+
+	LoginAttemptsStore      string        `long:"login-attempts-store" env:"LOGIN_ATTEMPTS_STORE" choice:"postgres" choice:"memory" default:"postgres" description:"Where the failed login attempts are counted, the memory store is not shared between instances"`
+	LoginFailureWindow      time.Duration `long:"login-failure-window" env:"LOGIN_FAILURE_WINDOW" default:"1h" description:"Failed login attempts are forgotten after this period without failures"`
+	LoginBackoffThreshold   int           `long:"login-backoff-threshold" env:"LOGIN_BACKOFF_THRESHOLD" default:"3" description:"Number of failed login attempts after which the next attempts are delayed"`
+	LoginBackoffBase        time.Duration `long:"login-backoff-base" env:"LOGIN_BACKOFF_BASE" default:"1s" description:"Delay after the first throttled failure, it doubles with every next one"`
+	LoginBackoffMax         time.Duration `long:"login-backoff-max" env:"LOGIN_BACKOFF_MAX" default:"5m" description:"Max delay between login attempts"`
+	LoginLockoutThreshold   int           `long:"login-lockout-threshold" env:"LOGIN_LOCKOUT_THRESHOLD" default:"10" description:"Number of failed login attempts after which the account is locked"`
+	LoginIPLockoutThreshold int           `long:"login-ip-lockout-threshold" env:"LOGIN_IP_LOCKOUT_THRESHOLD" default:"100" description:"Number of failed login attempts after which the IP is locked"`
+	LoginLockoutDuration    time.Duration `long:"login-lockout-duration" env:"LOGIN_LOCKOUT_DURATION" default:"15m" description:"Login lockout duration"`
+}
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"context"
+	"net/url"
+)
+
+// VerifyEmail confirms the email of the token owner.
+func (s *service) VerifyEmail(ctx context.Context, token string) error {
+	t, err := s.useUserToken(token, TokenPurposeEmailVerification)
+	if err != nil {
+		return err
+	}
+
+	return s.db.VerifyUserEmail(t.UserID)
+}
+
+// ResendVerificationEmail sends a new verification link to the current user unless the email is already verified.
+func (s *service) ResendVerificationEmail(ctx context.Context) error {
+	user, err := s.GetUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	return s.sendVerificationEmail(ctx, user)
+}
+
+func (s *service) sendVerificationEmail(ctx context.Context, user *User) error {
+	token, err := s.issueUserToken(user.ID, TokenPurposeEmailVerification, s.config.EmailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	link := s.config.PublicURL + "/verify-email?token=" + url.QueryEscape(token)
+
+	return s.enqueueEmail(ctx, user, "email.verification.subject", "email.verification.body",
+		user.FirstName, link, int(s.config.EmailVerificationTTL.Hours()))
+}
+
+// checkEmailVerified returns ErrEmailNotVerified if the service requires verified emails
+// and the current user has not verified theirs.
+func (s *service) checkEmailVerified(ctx context.Context) error {
+	if !s.config.RequireVerifiedEmail {
+		return nil
+	}
+
+	user, err := s.GetUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	if user.EmailVerifiedAt == nil {
+		return ErrEmailNotVerified
+	}
+
+	return nil
+}
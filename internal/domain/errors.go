@@ -1,17 +1,138 @@
 package domain
 
-import "fmt"
+import (
+	"errors"
+	"strings"
+)
+
+// Error is a domain error identified by a machine readable code.
+type Error struct {
+	Code    string
+	Message string
+}
+
+// registered are the errors created by NewError, see Errors.
+var registered []*Error
+
+func NewError(code, message string) *Error {
+	err := &Error{
+		Code:    code,
+		Message: message,
+	}
+	registered = append(registered, err)
+
+	return err
+}
+
+// Errors returns every error created by NewError, so that their translations can be checked.
+func Errors() []*Error {
+	return append([]*Error(nil), registered...)
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
 
 var (
 	// StatusBadRequest
-	ErrInvalidInputData = fmt.Errorf("invalid input data")
-	ErrNoSuchUser       = fmt.Errorf("no such user error")
+	ErrInvalidInputData     = NewError("invalid_input_data", "invalid input data")
+	ErrInvalidToken         = NewError("invalid_token", "invalid or expired token")
+	ErrInvalidTwoFactorCode = NewError("invalid_two_factor_code", "invalid two-factor authentication code")
+	ErrWrongPassword        = NewError("wrong_password", "wrong current password")
+	ErrInvalidPhotoOrder    = NewError("invalid_photo_order", "every photo of the product has to be listed once")
+
+	// StatusNotFound
+	ErrNoSuchUser     = NewError("no_such_user", "no such user error")
+	ErrNoSuchProduct  = NewError("no_such_product", "no such product error")
+	ErrNoSuchOrder    = NewError("no_such_order", "no such order error")
+	ErrNoSuchBlackout = NewError("no_such_blackout", "no such blackout error")
+	ErrNoSuchSession  = NewError("no_such_session", "no such session error")
+	ErrNoSuchProvider = NewError("no_such_provider", "no such identity provider error")
+	ErrNoSuchIdentity = NewError("no_such_identity", "no such identity error")
+	ErrNoSuchPhoto    = NewError("no_such_photo", "no such photo error")
+
+	// StatusConflict
+	ErrProductUnavailable   = NewError("product_unavailable", "product is unavailable for the requested period")
+	ErrIllegalOrderStatus   = NewError("illegal_order_status", "illegal order status transition")
+	ErrLoginTaken           = NewError("login_taken", "login is already taken")
+	ErrEmailTaken           = NewError("email_taken", "email is already taken")
+	ErrTwoFactorEnabled     = NewError("two_factor_enabled", "two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled  = NewError("two_factor_not_enabled", "two-factor authentication is not enabled")
+	ErrTwoFactorNotEnrolled = NewError("two_factor_not_enrolled", "two-factor authentication is not enrolled")
+	ErrIdentityTaken        = NewError("identity_taken", "identity is already linked to a user")
+	ErrIdentityNotLinked    = NewError("identity_not_linked", "a user with this email exists, the identity has to be linked")
+	ErrLastLoginMethod      = NewError("last_login_method", "the only way to sign in cannot be removed")
+	ErrNotReviewable        = NewError("not_reviewable", "only completed orders can be reviewed")
+	ErrAlreadyReviewed      = NewError("already_reviewed", "the order is already reviewed")
+	ErrActiveRentals        = NewError("active_rentals", "the user has active rentals")
+	ErrTooManyPhotos        = NewError("too_many_photos", "the product has too many photos")
 
 	// StatusInternalServerError
-	ErrInternalSecurity = fmt.Errorf("internal security error")
-	ErrInternalDatabase = fmt.Errorf("internal database error")
-	ErrJWT              = fmt.Errorf("jwt creating error")
+	ErrInternal         = NewError("internal", "internal error")
+	ErrInternalSecurity = NewError("internal_security", "internal security error")
+	ErrInternalDatabase = NewError("internal_database", "internal database error")
+	ErrJWT              = NewError("jwt", "jwt creating error")
+	ErrInternalStorage  = NewError("internal_storage", "internal blob storage error")
+
+	// StatusBadGateway
+	ErrIdentityProvider = NewError("identity_provider", "identity provider error")
 
 	// StatusUnauthorized
-	ErrUnauthorized = fmt.Errorf("unauthorized")
+	ErrUnauthorized       = NewError("unauthorized", "unauthorized")
+	ErrInvalidCredentials = NewError("invalid_credentials", "invalid login or password")
+
+	// StatusTooManyRequests
+	ErrTooManyLoginAttempts = NewError("too_many_login_attempts", "too many login attempts")
+
+	// StatusForbidden
+	ErrForbidden        = NewError("forbidden", "forbidden")
+	ErrEmailNotVerified = NewError("email_not_verified", "email is not verified")
+	ErrUserBlocked      = NewError("user_blocked", "user is blocked")
+
+	// StatusRequestEntityTooLarge
+	ErrPhotoTooLarge = NewError("photo_too_large", "photo is too large")
+
+	// StatusUnsupportedMediaType
+	ErrUnsupportedPhotoType = NewError("unsupported_photo_type", "unsupported photo type")
 )
+
+// FieldError describes a single invalid field of the input data.
+type FieldError struct {
+	Field   string
+	Code    string
+	Message string
+}
+
+// ValidationError lists all invalid fields of the input data, it is an ErrInvalidInputData.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func NewValidationError(fields ...FieldError) *ValidationError {
+	return &ValidationError{
+		Fields: fields,
+	}
+}
+
+func (e *ValidationError) Error() string {
+	ff := make([]string, 0, len(e.Fields))
+	for _, v := range e.Fields {
+		ff = append(ff, v.Field+": "+v.Message)
+	}
+
+	return ErrInvalidInputData.Error() + ": " + strings.Join(ff, ", ")
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidInputData
+}
+
+// AsError returns the domain error err is or wraps, ErrInternal is returned for unknown errors.
+func AsError(err error) *Error {
+	var derr *Error
+	if errors.As(err, &derr) {
+		return derr
+	}
+
+	return ErrInternal
+}
@@ -14,27 +14,211 @@ type Database interface {
 	UserRepository
 	ProductRepository
 	OrderRepository
+	BlackoutRepository
+	SessionRepository
+	UserTokenRepository
+	TwoFactorRepository
+	IdentityRepository
+	AdminRepository
+	ReviewRepository
+	OutboxRepository
+	LoginAttemptStore
 }
 
 type UserRepository interface {
 	SaveUser(user *User) (int, error)
 	GetUserByLogin(login string) (*User, error)
 	GetUserByID(id int) (*User, error)
+	GetUserByEmail(email string) (*User, error)
+	UpdateUser(user *User) error
+	// ChangeUserPassword sets the password, revokes the outstanding password reset tokens
+	// and the sessions except exceptSessionID in one transaction.
+	ChangeUserPassword(id int, passwordHash, salt []byte, exceptSessionID int, reason string) error
+	// UpdateUserAvatar sets the blob key of the avatar of the user and returns the previous one.
+	UpdateUserAvatar(id int, key *string) (*string, error)
+	VerifyUserEmail(id int) error
+	// AnonymizeUser erases the personal data of the user, signs them out and archives their products.
+	// ErrActiveRentals is returned if the user has orders in the open statuses as a renter or as an owner.
+	AnonymizeUser(id int, openStatuses []OrderStatus) error
 }
 
 type ProductRepository interface {
 	SaveProduct(product *Product) (int, error)
 	GetProductByID(id int) (*Product, error)
-	GetProductsWithPagination(limit, offset int, search string) ([]*Product, int, error)
-	RentProduct(productID, userID int, from, to time.Time) error
+	UpdateProduct(product *Product) error
+	ArchiveProduct(id int) error
+	// GetUserProducts returns the listed products of the owner with their main photos.
+	GetUserProducts(ownerID int) ([]*Product, error)
+	// GetOwnedProducts returns all products of the owner including the archived ones.
+	GetOwnedProducts(ownerID int) ([]*Product, error)
+	// SaveProductPhoto saves the photo with its variants as the last one of the product,
+	// ErrTooManyPhotos is returned if the product already has maxPhotos photos.
+	SaveProductPhoto(photo *Photo, maxPhotos int) (int, error)
+	CountProductPhotos(productID int) (int, error)
+	// ReorderProductPhotos sets the positions of the photos of the product to their indices.
+	ReorderProductPhotos(productID int, photoIDs []int) error
+	// SetCoverPhoto makes the photo the only cover photo of the product.
+	SetCoverPhoto(productID, photoID int) error
+	UpdatePhotoCaption(id int, caption *string) error
+	// DeleteProductPhoto deletes the photo with its variants.
+	DeleteProductPhoto(id int) error
+	GetProductsWithPagination(limit, offset int, query *ProductQuery) ([]*Product, int, error)
+	RentProduct(productID, userID int, from, to time.Time, price Money) error
+	GetBookedIntervals(productID int, from, to time.Time) ([]Interval, error)
 }
 
 type OrderRepository interface {
 	GetOrders(userID int, isMine bool) ([]*Order, error)
+	GetOrderByID(id int) (*Order, error)
+	UpdateOrderStatus(id int, from, to OrderStatus) error
+	CancelOrder(id int, from OrderStatus, cancelledBy int, refundAmount Money) error
+}
+
+type BlackoutRepository interface {
+	SaveBlackout(blackout *Blackout) (int, error)
+	GetBlackoutByID(id int) (*Blackout, error)
+	GetBlackouts(productID int, from, to time.Time) ([]*Blackout, error)
+	UpdateBlackout(blackout *Blackout) error
+	DeleteBlackout(id int) error
+}
+
+type SessionRepository interface {
+	SaveSession(session *Session) (int, error)
+	GetSessionByID(id int) (*Session, error)
+	GetActiveSessions(userID int) ([]*Session, error)
+	TouchSession(id int, expiresAt time.Time) error
+	RevokeSession(id int, reason string) error
+	RevokeUserSessions(userID, exceptID int, reason string) error
+	SaveRefreshToken(token *RefreshToken) error
+	GetRefreshTokenByHash(hash []byte) (*RefreshToken, error)
+	// UseRefreshToken marks the token used, false is returned if it has already been used.
+	UseRefreshToken(id int) (bool, error)
+}
+
+type UserTokenRepository interface {
+	SaveUserToken(token *UserToken) error
+	GetUserTokenByHash(purpose string, hash []byte) (*UserToken, error)
+	// UseUserToken marks the token used, false is returned if it has already been used.
+	UseUserToken(id int) (bool, error)
+	// RevokeUserTokens marks the outstanding tokens of the purpose used.
+	RevokeUserTokens(userID int, purpose string) error
+}
+
+type TwoFactorRepository interface {
+	// SetTOTPSecret saves the pending secret unless two-factor authentication is already enabled.
+	SetTOTPSecret(userID int, secret []byte) error
+	EnableTOTP(userID int) error
+	// DisableTOTP removes the secret and the recovery codes.
+	DisableTOTP(userID int) error
+	// UseTOTPStep remembers the last used time step, false is returned if it is not newer than the last one.
+	UseTOTPStep(userID int, step int64) (bool, error)
+	ReplaceRecoveryCodes(userID int, hashes [][]byte) error
+	// UseRecoveryCode marks the code used, false is returned if there is no such unused code.
+	UseRecoveryCode(userID int, hash []byte) (bool, error)
+}
+
+type IdentityRepository interface {
+	// SaveUserWithIdentity registers the user of the external identity.
+	SaveUserWithIdentity(user *User, identity *Identity, emailVerified bool) (int, error)
+	SaveIdentity(identity *Identity) error
+	GetIdentity(provider, subject string) (*Identity, error)
+	GetIdentities(userID int) ([]*Identity, error)
+	DeleteIdentity(id int) error
+	SaveOIDCLogin(login *OIDCLogin) error
+	GetOIDCLoginByStateHash(hash []byte) (*OIDCLogin, error)
+	// UseOIDCLogin marks the login used, false is returned if it has already been used.
+	UseOIDCLogin(id int) (bool, error)
+}
+
+type AdminRepository interface {
+	GetUsers(limit, offset int) ([]*User, int, error)
+	BlockUser(id int, reason string) error
+	UnblockUser(id int) error
+	SetUserRole(id int, role Role) error
+	HideProduct(id int, reason string) error
+	UnhideProduct(id int) error
+	GetAllOrders(limit, offset int) ([]*Order, int, error)
+}
+
+type ReviewRepository interface {
+	SaveReview(review *Review) (int, error)
+	GetRatingSummary(userID int) (*RatingSummary, error)
+}
+
+type OutboxRepository interface {
+	EnqueueEmail(email *Email) error
+	// ClaimPendingEmails returns the emails due to be sent and postpones them,
+	// so that they are not picked up concurrently.
+	ClaimPendingEmails(limit, maxAttempts int) ([]*Email, error)
+	MarkEmailSent(id int) error
+	MarkEmailFailed(id int, reason string, retryAt time.Time) error
+}
+
+// LoginAttemptStore keeps the failed login counters and the audit of the failed attempts.
+type LoginAttemptStore interface {
+	// GetLoginThrottle returns the counter of the key, a zero one if there were no failures.
+	GetLoginThrottle(key string) (*LoginThrottle, error)
+	// RegisterLoginFailure increments the counter of the key,
+	// it starts over if the last failure happened more than window ago.
+	RegisterLoginFailure(key string, window time.Duration) (*LoginThrottle, error)
+	// UndoLoginFailure takes back one failure of the key, the attempts are counted before the password is checked.
+	UndoLoginFailure(key string) error
+	ResetLoginFailures(key string) error
+	SaveLoginAttempt(attempt *LoginAttempt) error
+}
+
+// OIDC runs the OpenID Connect authorization code flow with PKCE against the configured providers.
+type OIDC interface {
+	Providers() []string
+	// AuthCodeURL returns the URL of the provider the user is redirected to.
+	AuthCodeURL(provider, state, nonce, codeVerifier string) (string, error)
+	// Exchange exchanges the authorization code for the verified identity of the user.
+	Exchange(ctx context.Context, provider, code, codeVerifier, nonce string) (*ExternalIdentity, error)
+}
+
+// BlobStore keeps the uploaded files.
+type BlobStore interface {
+	Put(ctx context.Context, key, contentType string, data []byte) error
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns the URL the blob can be downloaded by until ttl passes.
+	SignedURL(key string, ttl time.Duration) (string, error)
+	// OpenSigned returns the blob of a signed URL the service serves itself. ErrInvalidToken is returned
+	// for the wrong and expired signatures, and by the stores whose URLs point to the store directly.
+	OpenSigned(ctx context.Context, key string, expires int64, signature string) (*Blob, error)
+}
+
+// ImageProcessor prepares the uploaded photos for serving.
+type ImageProcessor interface {
+	// ProcessPhoto strips the metadata of the photo and makes its variants,
+	// ErrUnsupportedPhotoType is returned if the photo cannot be decoded.
+	ProcessPhoto(data []byte) (*ProcessedPhoto, error)
+}
+
+type Mailer interface {
+	Send(email *Email) error
 }
 
 type Security interface {
 	HashPassword(password string) ([]byte, []byte, error)
 	VerifyPassword(salt []byte, passwordHash []byte, password string) bool
-	GenerateNewJWT(id int, duration time.Duration) (string, error)
+	GenerateNewJWT(claims *TokenClaims, duration time.Duration) (string, error)
+	// ParseJWT verifies the token signature and expiration.
+	ParseJWT(token string) (*TokenClaims, error)
+	// PublicKeys returns the keys tokens can be verified with.
+	PublicKeys() []*PublicKey
+	// GenerateOpaqueToken returns a random URL safe token.
+	GenerateOpaqueToken() (string, error)
+	// HashOpaqueToken returns the hash the opaque token is stored by.
+	HashOpaqueToken(token string) []byte
+	// GenerateTOTPSecret returns a random secret of the authenticator app.
+	GenerateTOTPSecret() ([]byte, error)
+	// TOTPKeyURI returns the otpauth:// URI the authenticator app is set up with.
+	TOTPKeyURI(secret []byte, account string) string
+	// ValidateTOTP checks the code at the given time and returns the time step it belongs to.
+	ValidateTOTP(secret []byte, code string, at time.Time) (int64, bool)
+	GenerateRecoveryCode() (string, error)
+	// HashRecoveryCode returns the hash the recovery code is stored by.
+	HashRecoveryCode(code string) []byte
+	// Close stops the background rotation of the JWT signing keys.
+	Close() error
 }
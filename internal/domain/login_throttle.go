@@ -0,0 +1,183 @@
+package domain
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Failed login attempt reasons.
+const (
+	LoginFailureUnknownLogin  = "unknown_login"
+	LoginFailureWrongPassword = "wrong_password"
+	LoginFailureThrottled     = "throttled"
+	LoginFailureWrongCode     = "wrong_two_factor_code"
+)
+
+// maxBackoffShift keeps the exponential backoff from overflowing.
+const maxBackoffShift = 30
+
+// LoginThrottle counts the consecutive failed login attempts by an account or an IP.
+type LoginThrottle struct {
+	Key           string
+	Failures      int
+	LastFailureAt time.Time
+}
+
+// LoginAttempt is the audit record of a failed login attempt.
+type LoginAttempt struct {
+	ID        int
+	Login     string
+	UserID    *int
+	IP        string
+	UserAgent string
+	Reason    string
+	CreatedAt time.Time
+}
+
+// ThrottleError is returned when the login is temporarily blocked, it is an ErrTooManyLoginAttempts.
+type ThrottleError struct {
+	RetryAfter time.Duration
+}
+
+func (e *ThrottleError) Error() string {
+	return ErrTooManyLoginAttempts.Error()
+}
+
+func (e *ThrottleError) Unwrap() error {
+	return ErrTooManyLoginAttempts
+}
+
+// blockedUntil returns the time the next login attempt is allowed at.
+// Past the backoff threshold every failure doubles the delay, past the lockout threshold the key is locked.
+func (s *service) blockedUntil(throttle *LoginThrottle, lockoutThreshold int) time.Time {
+	if time.Since(throttle.LastFailureAt) > s.config.LoginFailureWindow {
+		return time.Time{}
+	}
+
+	switch {
+	case throttle.Failures >= lockoutThreshold:
+		return throttle.LastFailureAt.Add(s.config.LoginLockoutDuration)
+	case throttle.Failures >= s.config.LoginBackoffThreshold:
+		shift := throttle.Failures - s.config.LoginBackoffThreshold
+		if shift > maxBackoffShift {
+			shift = maxBackoffShift
+		}
+
+		delay := s.config.LoginBackoffBase << uint(shift)
+		if delay > s.config.LoginBackoffMax {
+			delay = s.config.LoginBackoffMax
+		}
+		return throttle.LastFailureAt.Add(delay)
+	}
+
+	return time.Time{}
+}
+
+// loginKeys returns the throttle keys of the account and of the client IP with their lockout thresholds.
+func (s *service) loginKeys(ctx context.Context, login string) map[string]int {
+	keys := map[string]int{
+		accountLoginKey(login): s.config.LoginLockoutThreshold,
+	}
+
+	if client, ok := ctx.Value(ContextClient).(*Client); ok && client.IP != "" {
+		keys["ip:"+client.IP] = s.config.LoginIPLockoutThreshold
+	}
+
+	return keys
+}
+
+func accountLoginKey(login string) string {
+	return "account:" + strings.ToLower(login)
+}
+
+// registerLoginAttempt counts the attempt as failed before the password is verified and returns a ThrottleError
+// if any of the keys is blocked. The counter is incremented and read back in one step, so that the concurrent
+// attempts cannot all pass the check of the same count.
+func (s *service) registerLoginAttempt(keys map[string]int) error {
+	// failures are the counts the attempt was checked against
+	failures := make(map[string]int, len(keys))
+	var until time.Time
+	for key, lockoutThreshold := range keys {
+		throttle, err := s.loginAttempts.GetLoginThrottle(key)
+		if err != nil {
+			return err
+		}
+
+		if t := s.blockedUntil(throttle, lockoutThreshold); t.After(until) {
+			until = t
+		}
+		if time.Since(throttle.LastFailureAt) <= s.config.LoginFailureWindow {
+			failures[key] = throttle.Failures
+		}
+	}
+
+	// the blocked attempts are not counted, otherwise retrying would extend the block
+	if err := throttleError(until); err != nil {
+		return err
+	}
+
+	for key, lockoutThreshold := range keys {
+		registered, err := s.loginAttempts.RegisterLoginFailure(key, s.config.LoginFailureWindow)
+		if err != nil {
+			return err
+		}
+
+		// the other attempts counted since the check failed just now as far as this one knows
+		if registered.Failures > failures[key]+1 {
+			concurrent := &LoginThrottle{Key: key, Failures: registered.Failures - 1, LastFailureAt: registered.LastFailureAt}
+			if t := s.blockedUntil(concurrent, lockoutThreshold); t.After(until) {
+				until = t
+			}
+		}
+	}
+
+	return throttleError(until)
+}
+
+// throttleError returns a ThrottleError if the login is blocked until the time.
+func throttleError(until time.Time) error {
+	if retryAfter := time.Until(until); retryAfter > 0 {
+		return &ThrottleError{RetryAfter: retryAfter}
+	}
+
+	return nil
+}
+
+// auditLogin saves the record of the failed login attempt.
+func (s *service) auditLogin(ctx context.Context, attempt *LoginAttempt) error {
+	if client, ok := ctx.Value(ContextClient).(*Client); ok {
+		attempt.IP = client.IP
+		attempt.UserAgent = client.UserAgent
+	}
+
+	s.logger.WithField("login", attempt.Login).WithField("ip", attempt.IP).WithField("reason", attempt.Reason).
+		Warn("Failed login attempt!")
+
+	return s.loginAttempts.SaveLoginAttempt(attempt)
+}
+
+// failLogin audits the failed attempt, which registerLoginAttempt has already counted, and returns res,
+// the error the client gets.
+func (s *service) failLogin(ctx context.Context, attempt *LoginAttempt, res error) error {
+	if err := s.auditLogin(ctx, attempt); err != nil {
+		return err
+	}
+
+	return res
+}
+
+// dummyPassword returns the hash verified against when the login is unknown,
+// so that unknown logins cannot be told apart by the response time.
+func (s *service) dummyPassword() ([]byte, []byte) {
+	s.dummyPasswordOnce.Do(func() {
+		hash, salt, err := s.security.HashPassword("dummy password")
+		if err != nil {
+			s.logger.WithError(err).Error("Error while hashing dummy password!")
+			return
+		}
+		s.dummyPasswordHash, s.dummyPasswordSalt = hash, salt
+	})
+
+	return s.dummyPasswordHash, s.dummyPasswordSalt
+}
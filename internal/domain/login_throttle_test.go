@@ -0,0 +1,149 @@
+package domain
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestThrottleConfig() *Config {
+	return &Config{
+		LoginFailureWindow:    time.Hour,
+		LoginBackoffThreshold: 3,
+		LoginBackoffBase:      time.Second,
+		LoginBackoffMax:       5 * time.Minute,
+		LoginLockoutThreshold: 10,
+		LoginLockoutDuration:  15 * time.Minute,
+	}
+}
+
+func TestBlockedUntil(t *testing.T) {
+	s := &service{config: newTestThrottleConfig()}
+	last := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name      string
+		failures  int
+		last      time.Time
+		threshold int
+		want      time.Time
+	}{
+		{name: "no failures", threshold: 10},
+		{name: "below backoff", failures: 2, last: last, threshold: 10},
+		{name: "backoff starts", failures: 3, last: last, threshold: 10, want: last.Add(time.Second)},
+		{name: "backoff doubles", failures: 4, last: last, threshold: 10, want: last.Add(2 * time.Second)},
+		{name: "before lockout", failures: 9, last: last, threshold: 10, want: last.Add(64 * time.Second)},
+		{name: "lockout", failures: 10, last: last, threshold: 10, want: last.Add(15 * time.Minute)},
+		{name: "past lockout", failures: 50, last: last, threshold: 10, want: last.Add(15 * time.Minute)},
+		{name: "IP threshold", failures: 10, last: last, threshold: 100, want: last.Add(128 * time.Second)},
+		{name: "backoff max", failures: 20, last: last, threshold: 100, want: last.Add(5 * time.Minute)},
+		{name: "backoff overflow", failures: 99, last: last, threshold: 100, want: last.Add(5 * time.Minute)},
+		{name: "forgotten", failures: 50, last: time.Now().Add(-2 * time.Hour), threshold: 10},
+	}
+
+	for _, tt := range tests {
+		throttle := &LoginThrottle{Key: "account:user", Failures: tt.failures, LastFailureAt: tt.last}
+		if got := s.blockedUntil(throttle, tt.threshold); !got.Equal(tt.want) {
+			t.Errorf("blockedUntil(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// testLoginAttemptStore counts the failures in memory like the memory store.
+type testLoginAttemptStore struct {
+	mu        sync.Mutex
+	throttles map[string]LoginThrottle
+}
+
+func (s *testLoginAttemptStore) GetLoginThrottle(key string) (*LoginThrottle, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	throttle := s.throttles[key]
+	throttle.Key = key
+	return &throttle, nil
+}
+
+func (s *testLoginAttemptStore) RegisterLoginFailure(key string, window time.Duration) (*LoginThrottle, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	throttle := s.throttles[key]
+	throttle.Key = key
+	throttle.Failures++
+	throttle.LastFailureAt = time.Now()
+	s.throttles[key] = throttle
+	return &throttle, nil
+}
+
+func (s *testLoginAttemptStore) UndoLoginFailure(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	throttle := s.throttles[key]
+	throttle.Failures--
+	s.throttles[key] = throttle
+	return nil
+}
+
+func (s *testLoginAttemptStore) ResetLoginFailures(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.throttles, key)
+	return nil
+}
+
+func (s *testLoginAttemptStore) SaveLoginAttempt(attempt *LoginAttempt) error {
+	return nil
+}
+
+func TestRegisterLoginAttemptConcurrently(t *testing.T) {
+	tests := []struct {
+		name             string
+		backoffThreshold int
+		want             int
+	}{
+		// the attempts past the threshold come right after the others, so the backoff blocks them
+		{name: "backoff", backoffThreshold: 3, want: 3},
+		{name: "lockout", backoffThreshold: 100, want: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := newTestThrottleConfig()
+			config.LoginBackoffThreshold = tt.backoffThreshold
+			store := &testLoginAttemptStore{throttles: make(map[string]LoginThrottle)}
+			s := &service{config: config, loginAttempts: store}
+
+			const attempts = 50
+			var wg sync.WaitGroup
+			errs := make([]error, attempts)
+			ready := make(chan struct{})
+			for i := 0; i < attempts; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					<-ready
+					errs[i] = s.registerLoginAttempt(map[string]int{"account:user": config.LoginLockoutThreshold})
+				}(i)
+			}
+			close(ready)
+			wg.Wait()
+
+			passed := 0
+			for i, err := range errs {
+				switch err.(type) {
+				case nil:
+					passed++
+				case *ThrottleError:
+				default:
+					t.Errorf("registerLoginAttempt() #%d error = %v", i, err)
+				}
+			}
+			if passed != tt.want {
+				t.Errorf("%d of %d concurrent attempts passed, want %d", passed, attempts, tt.want)
+			}
+		})
+	}
+}
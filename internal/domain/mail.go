@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"backend/internal/i18n"
+	"context"
+	"time"
+)
+
+// outboxRetryDelay is the delay before the first retry of a failed email, it doubles with every attempt.
+const outboxRetryDelay = time.Minute
+
+// Email is a plain text message queued in the outbox.
+type Email struct {
+	ID      int
+	To      string
+	Subject string
+	Body    string
+	// Attempts counts the attempts to send the email including the current one.
+	Attempts int
+}
+
+// SendPendingEmails sends a batch of the queued emails, failed ones are retried with a backoff.
+func (s *service) SendPendingEmails() error {
+	emails, err := s.db.ClaimPendingEmails(s.config.OutboxBatchSize, s.config.OutboxMaxAttempts)
+	if err != nil {
+		return err
+	}
+
+	for _, email := range emails {
+		if err := s.mailer.Send(email); err != nil {
+			s.logger.WithError(err).WithField("email_id", email.ID).Warn("Error while sending email!")
+
+			retryAt := time.Now().Add(outboxRetryDelay << uint(email.Attempts-1))
+			if err := s.db.MarkEmailFailed(email.ID, err.Error(), retryAt); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := s.db.MarkEmailSent(email.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// enqueueEmail localizes the message for the user and puts it into the outbox.
+func (s *service) enqueueEmail(ctx context.Context, user *User, subjectKey, bodyKey string, args ...interface{}) error {
+	lang := s.userLanguage(ctx, user)
+
+	return s.db.EnqueueEmail(&Email{
+		To:      user.Email,
+		Subject: i18n.Localize(lang, subjectKey),
+		Body:    i18n.Localize(lang, bodyKey, args...),
+	})
+}
+
+// userLanguage returns the preferred language of the user or the language of the request.
+func (s *service) userLanguage(ctx context.Context, user *User) i18n.Language {
+	if user.Language != nil {
+		if lang, ok := i18n.ParseLanguage(*user.Language); ok {
+			return lang
+		}
+	}
+
+	if lang, ok := ctx.Value(ContextLanguage).(i18n.Language); ok {
+		return lang
+	}
+
+	return i18n.Fallback
+}
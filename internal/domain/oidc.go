@@ -0,0 +1,322 @@
+package domain
+
+import (
+	"backend/internal/i18n"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxGeneratedLoginAttempts limits the attempts to find a free login for the users registered by OIDC.
+const maxGeneratedLoginAttempts = 5
+
+var loginCharsRegexp = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// ExternalIdentity is the user verified by an OpenID Connect provider.
+type ExternalIdentity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	FirstName     string
+	LastName      string
+}
+
+// Identity links the user to an account of an OpenID Connect provider.
+type Identity struct {
+	ID        int
+	UserID    int
+	Provider  string
+	Subject   string
+	Email     *string
+	CreatedAt time.Time
+}
+
+// OIDCRedirect starts the authorization code flow at the provider. The browser token is kept by the browser
+// starting the flow and sent back along with the state, so that the flow cannot be finished in another browser.
+type OIDCRedirect struct {
+	URL          string
+	BrowserToken string
+}
+
+// OIDCLogin keeps the state of the authorization code flow between the redirect to the provider and the callback.
+// UserID is set when an identity is being linked to the existing user.
+type OIDCLogin struct {
+	ID               int
+	StateHash        []byte
+	BrowserTokenHash []byte
+	Provider         string
+	CodeVerifier     string
+	Nonce            string
+	UserID           *int
+	ExpiresAt        time.Time
+	UsedAt           *time.Time
+}
+
+func (s *service) GetOIDCProviders() []string {
+	return s.oidc.Providers()
+}
+
+// StartOIDCLogin returns the URL of the provider the user authenticates at.
+func (s *service) StartOIDCLogin(ctx context.Context, provider string) (*OIDCRedirect, error) {
+	return s.startOIDC(provider, nil)
+}
+
+// FinishOIDCLogin signs in the user of the external identity, registering a new user for unknown identities.
+// The identity is not linked to the existing user with the same email automatically,
+// the user has to sign in and link it, otherwise anyone controlling the email at the provider would take over the account.
+// The browser token ties the login to the browser that started it, without it a victim could be made
+// to finish the login started by an attacker and end up signed in to the account of the attacker.
+func (s *service) FinishOIDCLogin(ctx context.Context, state, browserToken, code string) (*Tokens, *Challenge, error) {
+	login, external, err := s.finishOIDC(ctx, state, browserToken, code)
+	if err != nil {
+		return nil, nil, err
+	}
+	if login.UserID != nil {
+		return nil, nil, ErrInvalidToken
+	}
+
+	identity, err := s.db.GetIdentity(external.Provider, external.Subject)
+	if err != nil && !errors.Is(err, ErrNoSuchIdentity) {
+		return nil, nil, err
+	}
+
+	var user *User
+	if identity != nil {
+		if user, err = s.db.GetUserByID(identity.UserID); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		if user, err = s.registerExternalUser(ctx, external); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if user.TOTPEnabledAt != nil {
+		challenge, err := s.challengeLogin(user)
+		return nil, challenge, err
+	}
+
+	tokens, err := s.startSession(ctx, user.ID)
+	return tokens, nil, err
+}
+
+// StartIdentityLink returns the URL of the provider the current user authenticates at to link the identity.
+func (s *service) StartIdentityLink(ctx context.Context, provider string) (*OIDCRedirect, error) {
+	userID := ctx.Value(ContextUserID).(int)
+	return s.startOIDC(provider, &userID)
+}
+
+// LinkIdentity links the external identity to the current user.
+func (s *service) LinkIdentity(ctx context.Context, state, browserToken, code string) error {
+	userID := ctx.Value(ContextUserID).(int)
+
+	login, external, err := s.finishOIDC(ctx, state, browserToken, code)
+	if err != nil {
+		return err
+	}
+	if login.UserID == nil || *login.UserID != userID {
+		return ErrInvalidToken
+	}
+
+	return s.db.SaveIdentity(&Identity{
+		UserID:   userID,
+		Provider: external.Provider,
+		Subject:  external.Subject,
+		Email:    nonEmpty(external.Email),
+	})
+}
+
+func (s *service) GetIdentities(ctx context.Context) ([]*Identity, error) {
+	userID := ctx.Value(ContextUserID).(int)
+	return s.db.GetIdentities(userID)
+}
+
+// UnlinkIdentity removes the identity unless it is the only way the user can sign in.
+func (s *service) UnlinkIdentity(ctx context.Context, identityID int) error {
+	user, err := s.GetUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	identities, err := s.db.GetIdentities(user.ID)
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for _, v := range identities {
+		if v.ID == identityID {
+			found = true
+		}
+	}
+	if !found {
+		return ErrNoSuchIdentity
+	}
+
+	if user.PasswordHash == nil && len(identities) == 1 {
+		return ErrLastLoginMethod
+	}
+
+	return s.db.DeleteIdentity(identityID)
+}
+
+func (s *service) startOIDC(provider string, userID *int) (*OIDCRedirect, error) {
+	state, err := s.security.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	browserToken, err := s.security.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	codeVerifier, err := s.security.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := s.security.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	url, err := s.oidc.AuthCodeURL(provider, state, nonce, codeVerifier)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.SaveOIDCLogin(&OIDCLogin{
+		StateHash:        s.security.HashOpaqueToken(state),
+		BrowserTokenHash: s.security.HashOpaqueToken(browserToken),
+		Provider:         provider,
+		CodeVerifier:     codeVerifier,
+		Nonce:            nonce,
+		UserID:           userID,
+		ExpiresAt:        time.Now().Add(s.config.OIDCLoginTTL),
+	}); err != nil {
+		return nil, err
+	}
+
+	return &OIDCRedirect{
+		URL:          url,
+		BrowserToken: browserToken,
+	}, nil
+}
+
+// finishOIDC checks the state returned by the provider along with the browser token of the browser
+// that started the flow, and exchanges the code for the identity.
+func (s *service) finishOIDC(ctx context.Context, state, browserToken, code string) (*OIDCLogin, *ExternalIdentity, error) {
+	login, err := s.db.GetOIDCLoginByStateHash(s.security.HashOpaqueToken(state))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if login.UsedAt != nil || !time.Now().Before(login.ExpiresAt) {
+		return nil, nil, ErrInvalidToken
+	}
+
+	// the callback of a login started in another browser is rejected before the login is used up
+	if subtle.ConstantTimeCompare(login.BrowserTokenHash, s.security.HashOpaqueToken(browserToken)) != 1 {
+		return nil, nil, ErrInvalidToken
+	}
+
+	used, err := s.db.UseOIDCLogin(login.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !used {
+		return nil, nil, ErrInvalidToken
+	}
+
+	external, err := s.oidc.Exchange(ctx, login.Provider, code, login.CodeVerifier, login.Nonce)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return login, external, nil
+}
+
+// registerExternalUser creates the user of the external identity, the login is derived from the email.
+func (s *service) registerExternalUser(ctx context.Context, external *ExternalIdentity) (*User, error) {
+	if external.Email == "" {
+		return nil, ErrInvalidInputData
+	}
+
+	if _, err := s.db.GetUserByEmail(external.Email); err == nil {
+		return nil, ErrIdentityNotLinked
+	} else if !errors.Is(err, ErrNoSuchUser) {
+		return nil, err
+	}
+
+	user := &User{
+		FirstName: external.FirstName,
+		LastName:  external.LastName,
+		Email:     external.Email,
+	}
+	if lang, ok := ctx.Value(ContextLanguage).(i18n.Language); ok {
+		l := string(lang)
+		user.Language = &l
+	}
+
+	identity := &Identity{
+		Provider: external.Provider,
+		Subject:  external.Subject,
+		Email:    nonEmpty(external.Email),
+	}
+
+	base := loginFromEmail(external.Email)
+	for i := 0; i < maxGeneratedLoginAttempts; i++ {
+		user.Login = base
+		if i > 0 {
+			suffix, err := s.security.GenerateOpaqueToken()
+			if err != nil {
+				return nil, err
+			}
+			user.Login = base + "-" + suffix[:4]
+		}
+
+		userID, err := s.db.SaveUserWithIdentity(user, identity, external.EmailVerified)
+		if errors.Is(err, ErrLoginTaken) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return s.db.GetUserByID(userID)
+	}
+
+	return nil, ErrLoginTaken
+}
+
+// loginFromEmail returns the local part of the email cleaned up to match the login format.
+func loginFromEmail(email string) string {
+	login := email
+	if i := strings.Index(email, "@"); i >= 0 {
+		login = email[:i]
+	}
+
+	login = loginCharsRegexp.ReplaceAllString(login, "")
+	for len(login) < 3 {
+		login += strconv.Itoa(len(login))
+	}
+	if len(login) > 50 {
+		login = login[:50]
+	}
+
+	return login
+}
+
+func nonEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+
+	return &s
+}
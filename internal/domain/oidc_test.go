@@ -0,0 +1,130 @@
+package domain
+
+import (
+	"context"
+	"crypto/sha256"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// testSecurity generates predictable tokens, the other methods are not implemented.
+type testSecurity struct {
+	Security
+	tokens int
+}
+
+func (s *testSecurity) GenerateOpaqueToken() (string, error) {
+	s.tokens++
+	return "token-" + strconv.Itoa(s.tokens), nil
+}
+
+func (s *testSecurity) HashOpaqueToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
+
+type testOIDC struct {
+	OIDC
+}
+
+func (o *testOIDC) AuthCodeURL(provider, state, nonce, codeVerifier string) (string, error) {
+	return "https://provider.example.com/authorize?state=" + state, nil
+}
+
+func (o *testOIDC) Exchange(ctx context.Context, provider, code, codeVerifier, nonce string) (*ExternalIdentity, error) {
+	return &ExternalIdentity{Provider: provider, Subject: "subject", Email: "user@example.com"}, nil
+}
+
+// testOIDCDatabase keeps the OIDC logins and the linked identities in memory.
+type testOIDCDatabase struct {
+	Database
+	logins     []*OIDCLogin
+	identities []*Identity
+}
+
+func (d *testOIDCDatabase) SaveOIDCLogin(login *OIDCLogin) error {
+	login.ID = len(d.logins) + 1
+	d.logins = append(d.logins, login)
+	return nil
+}
+
+func (d *testOIDCDatabase) GetOIDCLoginByStateHash(hash []byte) (*OIDCLogin, error) {
+	for _, login := range d.logins {
+		if string(login.StateHash) == string(hash) {
+			copied := *login
+			return &copied, nil
+		}
+	}
+
+	return nil, ErrInvalidToken
+}
+
+func (d *testOIDCDatabase) UseOIDCLogin(id int) (bool, error) {
+	login := d.logins[id-1]
+	if login.UsedAt != nil {
+		return false, nil
+	}
+
+	now := time.Now()
+	login.UsedAt = &now
+	return true, nil
+}
+
+func (d *testOIDCDatabase) SaveIdentity(identity *Identity) error {
+	d.identities = append(d.identities, identity)
+	return nil
+}
+
+func newTestLogger() logrus.FieldLogger {
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+	return logger
+}
+
+func TestOIDCBrowserToken(t *testing.T) {
+	db := &testOIDCDatabase{}
+	s := &service{
+		logger:   newTestLogger(),
+		config:   &Config{OIDCLoginTTL: time.Minute},
+		db:       db,
+		security: &testSecurity{},
+		oidc:     &testOIDC{},
+	}
+	ctx := context.WithValue(context.Background(), ContextUserID, 1)
+
+	redirect, err := s.StartIdentityLink(ctx, "google")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if redirect.BrowserToken == "" {
+		t.Fatal("StartIdentityLink() returned no browser token")
+	}
+	state := "token-1"
+
+	// the callback opened in another browser has no or another browser token
+	for _, browserToken := range []string{"", "token-5", state} {
+		if err := s.LinkIdentity(ctx, state, browserToken, "code"); err != ErrInvalidToken {
+			t.Errorf("LinkIdentity() with browser token %q error = %v, want %v", browserToken, err, ErrInvalidToken)
+		}
+		if _, _, err := s.FinishOIDCLogin(ctx, state, browserToken, "code"); err != ErrInvalidToken {
+			t.Errorf("FinishOIDCLogin() with browser token %q error = %v, want %v", browserToken, err, ErrInvalidToken)
+		}
+	}
+	if db.logins[0].UsedAt != nil {
+		t.Fatal("login used up by a rejected callback")
+	}
+
+	if err := s.LinkIdentity(ctx, state, redirect.BrowserToken, "code"); err != nil {
+		t.Fatalf("LinkIdentity() error = %v", err)
+	}
+	if len(db.identities) != 1 || db.identities[0].UserID != 1 {
+		t.Errorf("identities = %+v, want one of user 1", db.identities)
+	}
+
+	if err := s.LinkIdentity(ctx, state, redirect.BrowserToken, "code"); err != ErrInvalidToken {
+		t.Errorf("LinkIdentity() reusing the state error = %v, want %v", err, ErrInvalidToken)
+	}
+}
@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type testOrder struct {
+	productID int
+	from, to  time.Time
+	price     Money
+}
+
+// testOrdersDatabase rejects the overlapping orders of a product the way the orders_no_overlap constraint does.
+type testOrdersDatabase struct {
+	Database
+	products map[int]*Product
+	orders   []testOrder
+}
+
+func (d *testOrdersDatabase) GetProductByID(productID int) (*Product, error) {
+	product, ok := d.products[productID]
+	if !ok {
+		return nil, ErrNoSuchProduct
+	}
+	return product, nil
+}
+
+func (d *testOrdersDatabase) RentProduct(productID, userID int, from, to time.Time, price Money) error {
+	for _, o := range d.orders {
+		if o.productID == productID && o.from.Before(to) && from.Before(o.to) {
+			return ErrProductUnavailable
+		}
+	}
+
+	d.orders = append(d.orders, testOrder{productID: productID, from: from, to: to, price: price})
+	return nil
+}
+
+func TestRentProductOverlap(t *testing.T) {
+	archivedAt := time.Now()
+	db := &testOrdersDatabase{
+		products: map[int]*Product{
+			1: {ID: 1, PerHour: 100},
+			2: {ID: 2, PerHour: 100},
+			3: {ID: 3, PerHour: 100, ArchivedAt: &archivedAt},
+		},
+	}
+	s := &service{
+		logger:  newTestLogger(),
+		config:  &Config{},
+		db:      db,
+		pricing: NewPricing(nil),
+	}
+	ctx := context.WithValue(context.Background(), ContextUserID, 1)
+	start := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		productID int
+		from, to  time.Time
+		want      error
+	}{
+		{name: "first booking", productID: 1, from: start, to: start.Add(2 * time.Hour)},
+		{name: "same period", productID: 1, from: start, to: start.Add(2 * time.Hour), want: ErrProductUnavailable},
+		{name: "starts inside", productID: 1, from: start.Add(time.Hour), to: start.Add(3 * time.Hour), want: ErrProductUnavailable},
+		{name: "ends inside", productID: 1, from: start.Add(-time.Hour), to: start.Add(time.Minute), want: ErrProductUnavailable},
+		{name: "covers", productID: 1, from: start.Add(-time.Hour), to: start.Add(3 * time.Hour), want: ErrProductUnavailable},
+		{name: "inside", productID: 1, from: start.Add(time.Minute), to: start.Add(time.Hour), want: ErrProductUnavailable},
+		{name: "right after", productID: 1, from: start.Add(2 * time.Hour), to: start.Add(3 * time.Hour)},
+		{name: "right before", productID: 1, from: start.Add(-time.Hour), to: start},
+		{name: "another product", productID: 2, from: start, to: start.Add(2 * time.Hour)},
+		{name: "inverted", productID: 2, from: start.Add(5 * time.Hour), to: start.Add(4 * time.Hour), want: ErrInvalidInputData},
+		{name: "empty", productID: 2, from: start.Add(5 * time.Hour), to: start.Add(5 * time.Hour), want: ErrInvalidInputData},
+		{name: "archived product", productID: 3, from: start, to: start.Add(time.Hour), want: ErrNoSuchProduct},
+	}
+
+	for _, tt := range tests {
+		if err := s.RentProduct(ctx, tt.productID, tt.from, tt.to); err != tt.want {
+			t.Errorf("RentProduct(%s) error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	if len(db.orders) != 4 {
+		t.Fatalf("%d orders saved, want 4", len(db.orders))
+	}
+	if db.orders[0].price != 20000 {
+		t.Errorf("price = %d, want the quote total 20000", db.orders[0].price)
+	}
+}
@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"net/url"
+)
+
+// ForgotPassword emails a password reset link to the user.
+// Unknown emails are silently ignored, so that the registered ones cannot be enumerated.
+func (s *service) ForgotPassword(ctx context.Context, email string) error {
+	user, err := s.db.GetUserByEmail(email)
+	if err != nil {
+		if errors.Is(err, ErrNoSuchUser) {
+			return nil
+		}
+		return err
+	}
+
+	token, err := s.issueUserToken(user.ID, TokenPurposePasswordReset, s.config.PasswordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	link := s.config.PublicURL + "/reset-password?token=" + url.QueryEscape(token)
+
+	return s.enqueueEmail(ctx, user, "email.password_reset.subject", "email.password_reset.body",
+		user.FirstName, link, int(s.config.PasswordResetTTL.Minutes()))
+}
+
+// ResetPassword sets the new password of the token owner and signs them out everywhere.
+// The other reset links of the user stop working too.
+func (s *service) ResetPassword(ctx context.Context, token, password string) error {
+	t, err := s.useUserToken(token, TokenPurposePasswordReset)
+	if err != nil {
+		return err
+	}
+
+	passwordHash, salt, err := s.security.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	return s.db.ChangeUserPassword(t.UserID, passwordHash, salt, 0, RevokeReasonPasswordReset)
+}
@@ -0,0 +1,337 @@
+package domain
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Photo is a product photo, either uploaded to the blob store or an external one added before the uploads.
+type Photo struct {
+	ID        int
+	ProductID int
+	// URL is the external URL, or the signed URL of the uploaded photo
+	URL         string
+	BlobKey     *string
+	ContentType string
+	Size        int64
+	Width       int
+	Height      int
+	// Blurhash and DominantColor are the placeholders shown while the photo loads
+	Blurhash      string
+	DominantColor string
+	Variants      []*PhotoVariant
+	// Position orders the photos of the product, the cover photo is the main one regardless of it
+	Position  int
+	Cover     bool
+	Caption   *string
+	CreatedAt time.Time
+}
+
+type PhotoVariantName string
+
+const (
+	PhotoVariantThumbnail PhotoVariantName = "thumbnail"
+	PhotoVariantCard      PhotoVariantName = "card"
+	PhotoVariantFull      PhotoVariantName = "full"
+)
+
+// PhotoVariantNames are the variants made of every uploaded photo, from the smallest one.
+var PhotoVariantNames = []PhotoVariantName{PhotoVariantThumbnail, PhotoVariantCard, PhotoVariantFull}
+
+// PhotoVariant is a resized copy of the uploaded photo.
+type PhotoVariant struct {
+	Name PhotoVariantName
+	// URL is the signed URL of the variant
+	URL         string
+	BlobKey     string
+	ContentType string
+	Width       int
+	Height      int
+	Size        int64
+}
+
+// Image is an encoded image.
+type Image struct {
+	ContentType string
+	Data        []byte
+	Width       int
+	Height      int
+}
+
+// ProcessedPhoto is the uploaded photo prepared for serving, see ImageProcessor.
+type ProcessedPhoto struct {
+	// Original is the uploaded photo without its metadata
+	Original      *Image
+	Variants      map[PhotoVariantName]*Image
+	Blurhash      string
+	DominantColor string
+}
+
+// Blob is an opened file of the blob store, the reader must close the body.
+type Blob struct {
+	ContentType string
+	Size        int64
+	Body        io.ReadCloser
+}
+
+// photoExtensions maps the accepted photo types to the extensions of their blob keys.
+// WebP is not accepted, the image processor cannot decode it.
+var photoExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+}
+
+// UploadProductPhoto stores the photo of the product of the current user along with its variants.
+// The type is sniffed from the content, the one declared by the client is not trusted.
+// The metadata of the photo is stripped, it may give away the location it was taken at.
+func (s *service) UploadProductPhoto(ctx context.Context, productID int, data []byte) (*Photo, error) {
+	if _, err := s.getEditableProduct(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	if int64(len(data)) > s.config.MaxPhotoSize {
+		return nil, ErrPhotoTooLarge
+	}
+
+	if _, ok := photoExtensions[http.DetectContentType(data)]; !ok {
+		return nil, ErrUnsupportedPhotoType
+	}
+
+	// the count is checked early to spare the processing, SaveProductPhoto checks it again
+	count, err := s.db.CountProductPhotos(productID)
+	if err != nil {
+		return nil, err
+	}
+
+	if count >= s.config.MaxProductPhotos {
+		return nil, ErrTooManyPhotos
+	}
+
+	processed, err := s.images.ProcessPhoto(data)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := s.security.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	base := "products/" + strconv.Itoa(productID) + "/" + name
+	key := base + photoExtensions[processed.Original.ContentType]
+	photo := &Photo{
+		ProductID:     productID,
+		BlobKey:       &key,
+		ContentType:   processed.Original.ContentType,
+		Size:          int64(len(processed.Original.Data)),
+		Width:         processed.Original.Width,
+		Height:        processed.Original.Height,
+		Blurhash:      processed.Blurhash,
+		DominantColor: processed.DominantColor,
+	}
+
+	if err := s.blobs.Put(ctx, key, processed.Original.ContentType, processed.Original.Data); err != nil {
+		return nil, err
+	}
+	keys := []string{key}
+
+	for _, variantName := range PhotoVariantNames {
+		image, ok := processed.Variants[variantName]
+		if !ok {
+			continue
+		}
+
+		variant := &PhotoVariant{
+			Name:        variantName,
+			BlobKey:     base + "_" + string(variantName) + photoExtensions[image.ContentType],
+			ContentType: image.ContentType,
+			Width:       image.Width,
+			Height:      image.Height,
+			Size:        int64(len(image.Data)),
+		}
+
+		if err := s.blobs.Put(ctx, variant.BlobKey, image.ContentType, image.Data); err != nil {
+			s.deleteBlobs(ctx, keys)
+			return nil, err
+		}
+		keys = append(keys, variant.BlobKey)
+		photo.Variants = append(photo.Variants, variant)
+	}
+
+	photo.ID, err = s.db.SaveProductPhoto(photo, s.config.MaxProductPhotos)
+	if err != nil {
+		// the blobs would be orphaned otherwise
+		s.deleteBlobs(ctx, keys)
+		return nil, err
+	}
+
+	if err := s.resolvePhotoURL(photo); err != nil {
+		return nil, err
+	}
+
+	return photo, nil
+}
+
+// deleteBlobs removes the blobs left behind by a failed upload, the errors are only logged.
+func (s *service) deleteBlobs(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		if err := s.blobs.Delete(ctx, key); err != nil {
+			s.logger.WithError(err).WithField("key", key).Error("Error while deleting blob!")
+		}
+	}
+}
+
+// ReorderProductPhotos sets the order of the photos of the product of the current user,
+// every photo of the product has to be listed once.
+func (s *service) ReorderProductPhotos(ctx context.Context, productID int, photoIDs []int) error {
+	product, err := s.getEditableProduct(ctx, productID)
+	if err != nil {
+		return err
+	}
+
+	if len(photoIDs) != len(product.Photos) {
+		return ErrInvalidPhotoOrder
+	}
+
+	listed := make(map[int]bool, len(photoIDs))
+	for _, id := range photoIDs {
+		listed[id] = true
+	}
+	for _, photo := range product.Photos {
+		if !listed[photo.ID] {
+			return ErrInvalidPhotoOrder
+		}
+	}
+
+	return s.db.ReorderProductPhotos(productID, photoIDs)
+}
+
+// SetCoverPhoto makes the photo the main one of the product of the current user.
+func (s *service) SetCoverPhoto(ctx context.Context, productID, photoID int) error {
+	if _, err := s.getOwnPhoto(ctx, productID, photoID); err != nil {
+		return err
+	}
+
+	return s.db.SetCoverPhoto(productID, photoID)
+}
+
+// UpdatePhotoCaption sets the caption of the photo of the product of the current user, an empty one removes it.
+func (s *service) UpdatePhotoCaption(ctx context.Context, productID, photoID int, caption *string) error {
+	if _, err := s.getOwnPhoto(ctx, productID, photoID); err != nil {
+		return err
+	}
+
+	if caption != nil && *caption == "" {
+		caption = nil
+	}
+
+	return s.db.UpdatePhotoCaption(photoID, caption)
+}
+
+// DeleteProductPhoto removes the photo of the product of the current user along with its blobs.
+func (s *service) DeleteProductPhoto(ctx context.Context, productID, photoID int) error {
+	photo, err := s.getOwnPhoto(ctx, productID, photoID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.DeleteProductPhoto(photoID); err != nil {
+		return err
+	}
+
+	// the photo is gone already, the blobs left behind are only logged
+	s.deleteBlobs(ctx, photoBlobKeys(photo))
+
+	return nil
+}
+
+// photoBlobKeys returns the keys of the uploaded photo and its variants, the external photos have none.
+func photoBlobKeys(photos ...*Photo) []string {
+	var keys []string
+	for _, photo := range photos {
+		if photo.BlobKey != nil {
+			keys = append(keys, *photo.BlobKey)
+		}
+		for _, variant := range photo.Variants {
+			keys = append(keys, variant.BlobKey)
+		}
+	}
+
+	return keys
+}
+
+// getEditableProduct returns the product of the current user unless it is archived.
+func (s *service) getEditableProduct(ctx context.Context, productID int) (*Product, error) {
+	product, err := s.getOwnProduct(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	if product.ArchivedAt != nil {
+		return nil, ErrNoSuchProduct
+	}
+
+	return product, nil
+}
+
+// getOwnPhoto returns the photo if it belongs to the given product of the current user.
+func (s *service) getOwnPhoto(ctx context.Context, productID, photoID int) (*Photo, error) {
+	product, err := s.getEditableProduct(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, photo := range product.Photos {
+		if photo.ID == photoID {
+			return photo, nil
+		}
+	}
+
+	return nil, ErrNoSuchPhoto
+}
+
+// OpenBlob returns the blob of a signed URL served by the service itself.
+func (s *service) OpenBlob(ctx context.Context, key string, expires int64, signature string) (*Blob, error) {
+	return s.blobs.OpenSigned(ctx, key, expires, signature)
+}
+
+// resolvePhotos signs the URLs of the uploaded photos of the products.
+func (s *service) resolvePhotos(products ...*Product) error {
+	for _, product := range products {
+		if product == nil {
+			continue
+		}
+
+		for _, photo := range product.Photos {
+			if err := s.resolvePhotoURL(photo); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *service) resolvePhotoURL(photo *Photo) error {
+	if photo.BlobKey == nil {
+		return nil
+	}
+
+	url, err := s.blobs.SignedURL(*photo.BlobKey, s.config.PhotoURLTTL)
+	if err != nil {
+		return err
+	}
+	photo.URL = url
+
+	for _, variant := range photo.Variants {
+		variant.URL, err = s.blobs.SignedURL(variant.BlobKey, s.config.PhotoURLTTL)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,192 @@
+package domain
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Money is an amount in minor currency units (kopecks).
+type Money int64
+
+func NewMoney(amount float64) Money {
+	return Money(math.Round(amount * 100))
+}
+
+func (m Money) Float64() float64 {
+	return float64(m) / 100
+}
+
+// Percent returns the given percentage of m rounded to the nearest minor unit.
+func (m Money) Percent(percent float64) Money {
+	return Money(math.Round(float64(m) * percent / 100))
+}
+
+type PriceUnit string
+
+const (
+	PriceUnitHour PriceUnit = "hour"
+	PriceUnitDay  PriceUnit = "day"
+	PriceUnitWeek PriceUnit = "week"
+)
+
+var priceUnitHours = map[PriceUnit]int{
+	PriceUnitHour: 1,
+	PriceUnitDay:  24,
+	PriceUnitWeek: 7 * 24,
+}
+
+type QuoteLine struct {
+	Unit     PriceUnit
+	Quantity int
+	Rate     Money
+	Amount   Money
+}
+
+type Quote struct {
+	From            time.Time
+	To              time.Time
+	Hours           int
+	Lines           []*QuoteLine
+	Subtotal        Money
+	DiscountPercent float64
+	Discount        Money
+	Total           Money
+}
+
+// Discount is applied to rentals lasting at least MinDuration.
+type Discount struct {
+	MinDuration time.Duration
+	Percent     float64
+}
+
+var DefaultDiscounts = []Discount{
+	{MinDuration: 7 * 24 * time.Hour, Percent: 5},
+	{MinDuration: 28 * 24 * time.Hour, Percent: 10},
+}
+
+// Pricing computes the order price at booking time.
+type Pricing struct {
+	discounts []Discount
+}
+
+func NewPricing(discounts []Discount) *Pricing {
+	dd := make([]Discount, len(discounts))
+	copy(dd, discounts)
+	sort.Slice(dd, func(i, j int) bool {
+		return dd[i].MinDuration < dd[j].MinDuration
+	})
+
+	return &Pricing{
+		discounts: dd,
+	}
+}
+
+// Quote returns the itemised price of renting the product from `from` till `to`.
+// Started hours are billed in full, and the cheapest combination
+// of the hourly, daily and weekly rates of the product is used.
+func (p *Pricing) Quote(product *Product, from, to time.Time) (*Quote, error) {
+	if from.IsZero() || to.IsZero() || !from.Before(to) {
+		return nil, ErrInvalidInputData
+	}
+
+	hours := int(math.Ceil(to.Sub(from).Hours()))
+
+	rates := map[PriceUnit]Money{
+		PriceUnitHour: NewMoney(product.PerHour),
+	}
+	if product.PerDay != nil {
+		rates[PriceUnitDay] = NewMoney(*product.PerDay)
+	}
+	if product.PerWeek != nil {
+		rates[PriceUnitWeek] = NewMoney(*product.PerWeek)
+	}
+
+	quantities := cheapestQuantities(hours, rates)
+
+	q := &Quote{
+		From:  from,
+		To:    to,
+		Hours: hours,
+		Lines: make([]*QuoteLine, 0),
+	}
+
+	for _, unit := range []PriceUnit{PriceUnitWeek, PriceUnitDay, PriceUnitHour} {
+		if quantities[unit] == 0 {
+			continue
+		}
+
+		line := &QuoteLine{
+			Unit:     unit,
+			Quantity: quantities[unit],
+			Rate:     rates[unit],
+			Amount:   rates[unit] * Money(quantities[unit]),
+		}
+		q.Lines = append(q.Lines, line)
+		q.Subtotal += line.Amount
+	}
+
+	for _, d := range p.discounts {
+		if to.Sub(from) >= d.MinDuration {
+			q.DiscountPercent = d.Percent
+		}
+	}
+
+	q.Discount = q.Subtotal.Percent(q.DiscountPercent)
+	q.Total = q.Subtotal - q.Discount
+
+	return q, nil
+}
+
+// cheapestQuantities splits the rental hours into weeks, days and hours so that the total is minimal.
+// A longer unit may cover more time than rented if it is still cheaper than the shorter ones.
+func cheapestQuantities(hours int, rates map[PriceUnit]Money) map[PriceUnit]int {
+	ceilDiv := func(a, b int) int {
+		return (a + b - 1) / b
+	}
+
+	maxWeeks := 0
+	if _, ok := rates[PriceUnitWeek]; ok {
+		maxWeeks = ceilDiv(hours, priceUnitHours[PriceUnitWeek])
+	}
+
+	var best map[PriceUnit]int
+	bestCost := Money(math.MaxInt64)
+
+	for weeks := 0; weeks <= maxWeeks; weeks++ {
+		rest := hours - weeks*priceUnitHours[PriceUnitWeek]
+		if rest < 0 {
+			rest = 0
+		}
+
+		daysCandidates := []int{0}
+		if _, ok := rates[PriceUnitDay]; ok {
+			daysCandidates = append(daysCandidates,
+				rest/priceUnitHours[PriceUnitDay],
+				ceilDiv(rest, priceUnitHours[PriceUnitDay]),
+			)
+		}
+
+		for _, days := range daysCandidates {
+			restHours := rest - days*priceUnitHours[PriceUnitDay]
+			if restHours < 0 {
+				restHours = 0
+			}
+
+			cost := rates[PriceUnitWeek]*Money(weeks) +
+				rates[PriceUnitDay]*Money(days) +
+				rates[PriceUnitHour]*Money(restHours)
+
+			if cost < bestCost {
+				bestCost = cost
+				best = map[PriceUnit]int{
+					PriceUnitWeek: weeks,
+					PriceUnitDay:  days,
+					PriceUnitHour: restHours,
+				}
+			}
+		}
+	}
+
+	return best
+}
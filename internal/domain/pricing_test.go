@@ -0,0 +1,224 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func rate(v float64) *float64 {
+	return &v
+}
+
+func TestQuote(t *testing.T) {
+	start := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	hourly := &Product{PerHour: 100}
+	daily := &Product{PerHour: 100, PerDay: rate(1000)}
+	weekly := &Product{PerHour: 100, PerDay: rate(1000), PerWeek: rate(5000)}
+
+	tests := []struct {
+		name         string
+		product      *Product
+		duration     time.Duration
+		wantLines    []QuoteLine
+		wantHours    int
+		wantSubtotal Money
+		wantPercent  float64
+		wantTotal    Money
+	}{
+		{
+			name:         "a minute is a started hour",
+			product:      hourly,
+			duration:     time.Minute,
+			wantLines:    []QuoteLine{{Unit: PriceUnitHour, Quantity: 1, Rate: 10000, Amount: 10000}},
+			wantHours:    1,
+			wantSubtotal: 10000,
+			wantTotal:    10000,
+		},
+		{
+			name:         "exactly an hour",
+			product:      hourly,
+			duration:     time.Hour,
+			wantLines:    []QuoteLine{{Unit: PriceUnitHour, Quantity: 1, Rate: 10000, Amount: 10000}},
+			wantHours:    1,
+			wantSubtotal: 10000,
+			wantTotal:    10000,
+		},
+		{
+			name:         "a second past the hour",
+			product:      hourly,
+			duration:     time.Hour + time.Second,
+			wantLines:    []QuoteLine{{Unit: PriceUnitHour, Quantity: 2, Rate: 10000, Amount: 20000}},
+			wantHours:    2,
+			wantSubtotal: 20000,
+			wantTotal:    20000,
+		},
+		{
+			name:         "a day without per_day",
+			product:      hourly,
+			duration:     day,
+			wantLines:    []QuoteLine{{Unit: PriceUnitHour, Quantity: 24, Rate: 10000, Amount: 240000}},
+			wantHours:    24,
+			wantSubtotal: 240000,
+			wantTotal:    240000,
+		},
+		{
+			name:         "a day is cheaper than 23 hours",
+			product:      daily,
+			duration:     23 * time.Hour,
+			wantLines:    []QuoteLine{{Unit: PriceUnitDay, Quantity: 1, Rate: 100000, Amount: 100000}},
+			wantHours:    23,
+			wantSubtotal: 100000,
+			wantTotal:    100000,
+		},
+		{
+			name:     "a day and an hour",
+			product:  daily,
+			duration: 25 * time.Hour,
+			wantLines: []QuoteLine{
+				{Unit: PriceUnitDay, Quantity: 1, Rate: 100000, Amount: 100000},
+				{Unit: PriceUnitHour, Quantity: 1, Rate: 10000, Amount: 10000},
+			},
+			wantHours:    25,
+			wantSubtotal: 110000,
+			wantTotal:    110000,
+		},
+		{
+			name:         "an hour short of the week discount",
+			product:      weekly,
+			duration:     7*day - time.Hour,
+			wantLines:    []QuoteLine{{Unit: PriceUnitWeek, Quantity: 1, Rate: 500000, Amount: 500000}},
+			wantHours:    167,
+			wantSubtotal: 500000,
+			wantTotal:    500000,
+		},
+		{
+			name:         "exactly a week",
+			product:      weekly,
+			duration:     7 * day,
+			wantLines:    []QuoteLine{{Unit: PriceUnitWeek, Quantity: 1, Rate: 500000, Amount: 500000}},
+			wantHours:    168,
+			wantSubtotal: 500000,
+			wantPercent:  5,
+			wantTotal:    475000,
+		},
+		{
+			name:         "a week without per_week",
+			product:      daily,
+			duration:     7 * day,
+			wantLines:    []QuoteLine{{Unit: PriceUnitDay, Quantity: 7, Rate: 100000, Amount: 700000}},
+			wantHours:    168,
+			wantSubtotal: 700000,
+			wantPercent:  5,
+			wantTotal:    665000,
+		},
+		{
+			name:         "a week without per_day and per_week",
+			product:      &Product{PerHour: 33.33},
+			duration:     7 * day,
+			wantLines:    []QuoteLine{{Unit: PriceUnitHour, Quantity: 168, Rate: 3333, Amount: 559944}},
+			wantHours:    168,
+			wantSubtotal: 559944,
+			wantPercent:  5,
+			// the discount of 27997.2 kopecks is rounded
+			wantTotal: 531947,
+		},
+		{
+			name:         "per_week dearer than the days",
+			product:      &Product{PerHour: 100, PerDay: rate(1000), PerWeek: rate(8000)},
+			duration:     7 * day,
+			wantLines:    []QuoteLine{{Unit: PriceUnitDay, Quantity: 7, Rate: 100000, Amount: 700000}},
+			wantHours:    168,
+			wantSubtotal: 700000,
+			wantPercent:  5,
+			wantTotal:    665000,
+		},
+		{
+			name:         "exactly four weeks",
+			product:      weekly,
+			duration:     28 * day,
+			wantLines:    []QuoteLine{{Unit: PriceUnitWeek, Quantity: 4, Rate: 500000, Amount: 2000000}},
+			wantHours:    672,
+			wantSubtotal: 2000000,
+			wantPercent:  10,
+			wantTotal:    1800000,
+		},
+	}
+
+	// the discounts are sorted by the pricing, the longest matching one applies
+	p := NewPricing([]Discount{DefaultDiscounts[1], DefaultDiscounts[0]})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := p.Quote(tt.product, start, start.Add(tt.duration))
+			if err != nil {
+				t.Fatalf("Quote() error = %v", err)
+			}
+
+			lines := make([]QuoteLine, 0, len(q.Lines))
+			for _, l := range q.Lines {
+				lines = append(lines, *l)
+			}
+			if !reflect.DeepEqual(lines, tt.wantLines) {
+				t.Errorf("lines = %+v, want %+v", lines, tt.wantLines)
+			}
+			if q.Hours != tt.wantHours {
+				t.Errorf("hours = %d, want %d", q.Hours, tt.wantHours)
+			}
+			if q.Subtotal != tt.wantSubtotal {
+				t.Errorf("subtotal = %d, want %d", q.Subtotal, tt.wantSubtotal)
+			}
+			if q.DiscountPercent != tt.wantPercent {
+				t.Errorf("discount percent = %v, want %v", q.DiscountPercent, tt.wantPercent)
+			}
+			if q.Total != tt.wantTotal || q.Subtotal-q.Discount != q.Total {
+				t.Errorf("total = %d with discount %d, want %d", q.Total, q.Discount, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestQuoteInvalidPeriod(t *testing.T) {
+	start := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+	p := NewPricing(DefaultDiscounts)
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+	}{
+		{name: "empty", from: start, to: start},
+		{name: "inverted", from: start, to: start.Add(-time.Hour)},
+		{name: "no start", to: start},
+		{name: "no end", from: start},
+	}
+
+	for _, tt := range tests {
+		if _, err := p.Quote(&Product{PerHour: 100}, tt.from, tt.to); err != ErrInvalidInputData {
+			t.Errorf("Quote(%s) error = %v, want %v", tt.name, err, ErrInvalidInputData)
+		}
+	}
+}
+
+func TestMoneyRounding(t *testing.T) {
+	tests := []struct {
+		name string
+		got  Money
+		want Money
+	}{
+		{name: "NewMoney(19.99)", got: NewMoney(19.99), want: 1999},
+		{name: "NewMoney(0.1 + 0.2)", got: NewMoney(0.1 + 0.2), want: 30},
+		{name: "NewMoney(0.005)", got: NewMoney(0.005), want: 1},
+		{name: "NewMoney(0.004)", got: NewMoney(0.004), want: 0},
+		{name: "3330.Percent(5)", got: Money(3330).Percent(5), want: 167},
+		{name: "3329.Percent(5)", got: Money(3329).Percent(5), want: 166},
+		{name: "1.Percent(50)", got: Money(1).Percent(50), want: 1},
+		{name: "1.Percent(49)", got: Money(1).Percent(49), want: 0},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %d, want %d", tt.name, tt.got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package domain
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// avatarVariant is the variant of the uploaded photo kept as the avatar, the original is not stored.
+const avatarVariant = PhotoVariantCard
+
+// UpdateProfile saves the name, email and language of the current user.
+// A new email has to be verified again, the links sent to the old one stop working.
+func (s *service) UpdateProfile(ctx context.Context, profile *User) error {
+	user, err := s.GetUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	emailChanged := !strings.EqualFold(user.Email, profile.Email)
+
+	user.FirstName = profile.FirstName
+	user.LastName = profile.LastName
+	user.Email = profile.Email
+	user.Language = profile.Language
+	if emailChanged {
+		user.EmailVerifiedAt = nil
+	}
+
+	if err := s.db.UpdateUser(user); err != nil {
+		return err
+	}
+
+	if !emailChanged {
+		return nil
+	}
+
+	if err := s.db.RevokeUserTokens(user.ID, TokenPurposeEmailVerification); err != nil {
+		return err
+	}
+
+	// the profile is already saved, the link can be requested again
+	if err := s.sendVerificationEmail(ctx, user); err != nil {
+		s.logger.WithError(err).WithField("user_id", user.ID).Error("Error while sending verification email!")
+	}
+
+	return nil
+}
+
+// UploadAvatar replaces the avatar of the current user with the photo, which goes through the same checks
+// and metadata stripping as the product photos.
+func (s *service) UploadAvatar(ctx context.Context, data []byte) (*User, error) {
+	user, err := s.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(data)) > s.config.MaxPhotoSize {
+		return nil, ErrPhotoTooLarge
+	}
+
+	if _, ok := photoExtensions[http.DetectContentType(data)]; !ok {
+		return nil, ErrUnsupportedPhotoType
+	}
+
+	processed, err := s.images.ProcessPhoto(data)
+	if err != nil {
+		return nil, err
+	}
+
+	image, ok := processed.Variants[avatarVariant]
+	if !ok {
+		image = processed.Original
+	}
+
+	name, err := s.security.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	key := "users/" + strconv.Itoa(user.ID) + "/" + name + photoExtensions[image.ContentType]
+	if err := s.blobs.Put(ctx, key, image.ContentType, image.Data); err != nil {
+		return nil, err
+	}
+
+	previous, err := s.db.UpdateUserAvatar(user.ID, &key)
+	if err != nil {
+		s.deleteBlobs(ctx, []string{key})
+		return nil, err
+	}
+
+	if previous != nil {
+		s.deleteBlobs(ctx, []string{*previous})
+	}
+
+	user.AvatarKey = &key
+	if err := s.resolveAvatars(user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// DeleteAvatar removes the avatar of the current user along with its blob.
+func (s *service) DeleteAvatar(ctx context.Context) error {
+	userID := ctx.Value(ContextUserID).(int)
+
+	previous, err := s.db.UpdateUserAvatar(userID, nil)
+	if err != nil {
+		return err
+	}
+
+	if previous != nil {
+		s.deleteBlobs(ctx, []string{*previous})
+	}
+
+	return nil
+}
+
+// resolveAvatars signs the URLs of the avatars of the users.
+func (s *service) resolveAvatars(users ...*User) error {
+	for _, user := range users {
+		if user == nil || user.AvatarKey == nil {
+			continue
+		}
+
+		url, err := s.blobs.SignedURL(*user.AvatarKey, s.config.PhotoURLTTL)
+		if err != nil {
+			return err
+		}
+		user.Avatar = &url
+	}
+
+	return nil
+}
+
+// ChangePassword sets the new password of the current user and signs out the other sessions.
+// The users registered by an external identity have no password, they set one without the current password.
+func (s *service) ChangePassword(ctx context.Context, currentPassword, newPassword string) error {
+	user, err := s.GetUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := s.verifyCurrentPassword(ctx, user, currentPassword); err != nil {
+		return err
+	}
+
+	passwordHash, salt, err := s.security.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	sessionID := ctx.Value(ContextSessionID).(int)
+	return s.db.ChangeUserPassword(user.ID, passwordHash, salt, sessionID, RevokeReasonPasswordChanged)
+}
+
+// verifyCurrentPassword confirms a sensitive action of the user with their password,
+// the users without a password pass.
+func (s *service) verifyCurrentPassword(ctx context.Context, user *User, password string) error {
+	if user.PasswordHash == nil {
+		return nil
+	}
+
+	// the guesses count towards the lockout of the account
+	keys := map[string]int{
+		accountLoginKey(user.Login): s.config.LoginLockoutThreshold,
+	}
+	if err := s.registerLoginAttempt(keys); err != nil {
+		return err
+	}
+
+	if !s.security.VerifyPassword(user.Salt, user.PasswordHash, password) {
+		attempt := &LoginAttempt{Login: user.Login, UserID: &user.ID, Reason: LoginFailureWrongPassword}
+		return s.failLogin(ctx, attempt, ErrWrongPassword)
+	}
+
+	return s.loginAttempts.ResetLoginFailures(accountLoginKey(user.Login))
+}
+
+// PublicProfile is what the other users see of the user.
+type PublicProfile struct {
+	User     *User
+	Products []*Product
+	Rating   *RatingSummary
+}
+
+// GetPublicProfile returns the profile of the user with their listed products, blocked users have none.
+func (s *service) GetPublicProfile(userID int) (*PublicProfile, error) {
+	user, err := s.db.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.BlockedAt != nil || user.DeletedAt != nil {
+		return nil, ErrNoSuchUser
+	}
+
+	products, err := s.db.GetUserProducts(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.resolvePhotos(products...); err != nil {
+		return nil, err
+	}
+
+	if err := s.resolveAvatars(user); err != nil {
+		return nil, err
+	}
+
+	rating, err := s.db.GetRatingSummary(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PublicProfile{
+		User:     user,
+		Products: products,
+		Rating:   rating,
+	}, nil
+}
@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// Review is the rating one party of a completed order gives to the other one.
+type Review struct {
+	ID        int
+	OrderID   int
+	AuthorID  int
+	SubjectID int
+	Rating    int
+	Comment   *string
+	CreatedAt time.Time
+}
+
+type RatingSummary struct {
+	Average float64
+	Count   int
+}
@@ -0,0 +1,65 @@
+package domain
+
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
+)
+
+// Permission allows an action of the admin API.
+type Permission string
+
+const (
+	PermissionViewUsers        Permission = "users:view"
+	PermissionBlockUsers       Permission = "users:block"
+	PermissionManageRoles      Permission = "users:roles"
+	PermissionModerateProducts Permission = "products:moderate"
+	PermissionViewAllOrders    Permission = "orders:view_all"
+)
+
+// rolePermissions lists the permissions of every role, the users have none.
+var rolePermissions = map[Role][]Permission{
+	RoleModerator: {
+		PermissionViewUsers,
+		PermissionBlockUsers,
+		PermissionModerateProducts,
+		PermissionViewAllOrders,
+	},
+	RoleAdmin: {
+		PermissionViewUsers,
+		PermissionBlockUsers,
+		PermissionManageRoles,
+		PermissionModerateProducts,
+		PermissionViewAllOrders,
+	},
+}
+
+// roleRanks orders the roles, a user can only block the users of lower roles.
+var roleRanks = map[Role]int{
+	RoleUser:      0,
+	RoleModerator: 1,
+	RoleAdmin:     2,
+}
+
+func (r Role) Valid() bool {
+	_, ok := roleRanks[r]
+	return ok
+}
+
+// Can reports whether the role has the permission.
+func (r Role) Can(p Permission) bool {
+	for _, v := range rolePermissions[r] {
+		if v == p {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Outranks reports whether the role is higher than the other one.
+func (r Role) Outranks(other Role) bool {
+	return roleRanks[r] > roleRanks[other]
+}
@@ -1,8 +1,11 @@
 package domain
 
 import (
+	"backend/internal/i18n"
 	"context"
+	"errors"
 	"github.com/sirupsen/logrus"
+	"sync"
 	"time"
 )
 
@@ -10,110 +13,327 @@ type Service interface {
 	UserService
 	AuthService
 	ProductService
+	OrderService
+	OutboxService
+	AdminService
 }
 
 type AuthService interface {
-	Register(user *User) (string, error)
-	Login(user *User) (string, error)
+	Register(ctx context.Context, user *User) (*Tokens, error)
+	// Login returns the challenge instead of the tokens if the user has two-factor authentication enabled.
+	Login(ctx context.Context, user *User) (*Tokens, *Challenge, error)
+	LoginTwoFactor(ctx context.Context, challengeToken, code string) (*Tokens, error)
+	Refresh(ctx context.Context, refreshToken string) (*Tokens, error)
+	Logout(ctx context.Context) error
+	Authenticate(token string) (*TokenClaims, error)
+	GetPublicKeys() []*PublicKey
+	ForgotPassword(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token, password string) error
+	VerifyEmail(ctx context.Context, token string) error
+	ResendVerificationEmail(ctx context.Context) error
+	GetOIDCProviders() []string
+	StartOIDCLogin(ctx context.Context, provider string) (*OIDCRedirect, error)
+	FinishOIDCLogin(ctx context.Context, state, browserToken, code string) (*Tokens, *Challenge, error)
 }
 
 type UserService interface {
 	GetUser(ctx context.Context) (*User, error)
+	UpdateProfile(ctx context.Context, profile *User) error
+	UploadAvatar(ctx context.Context, data []byte) (*User, error)
+	DeleteAvatar(ctx context.Context) error
+	ChangePassword(ctx context.Context, currentPassword, newPassword string) error
+	GetPublicProfile(userID int) (*PublicProfile, error)
+	ExportUserData(ctx context.Context) (*UserExport, error)
+	DeleteUser(ctx context.Context, password string) error
+	GetSessions(ctx context.Context) ([]*Session, error)
+	RevokeSession(ctx context.Context, sessionID int) error
+	RevokeOtherSessions(ctx context.Context) error
+	EnrollTwoFactor(ctx context.Context) (*TwoFactorEnrollment, error)
+	ConfirmTwoFactor(ctx context.Context, code string) ([]string, error)
+	DisableTwoFactor(ctx context.Context, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, code string) ([]string, error)
+	GetIdentities(ctx context.Context) ([]*Identity, error)
+	StartIdentityLink(ctx context.Context, provider string) (*OIDCRedirect, error)
+	LinkIdentity(ctx context.Context, state, browserToken, code string) error
+	UnlinkIdentity(ctx context.Context, identityID int) error
 }
 
 type ProductService interface {
 	AddProduct(ctx context.Context, product *Product) (int, error)
-	GetProductAndOwnerUserByProductID(productID int) (*Product, *User, error)
-	GetProductsWithPagination(page, count int, search string) ([]*Product, int, error)
+	UpdateProduct(ctx context.Context, product *Product) error
+	ArchiveProduct(ctx context.Context, productID int) error
+	GetProductAndOwnerUserByProductID(ctx context.Context, productID int) (*Product, *User, error)
+	GetProductsWithPagination(page, count int, query *ProductQuery) ([]*Product, int, error)
 	RentProduct(ctx context.Context, productID int, from, to time.Time) error
+	QuoteProduct(productID int, from, to time.Time) (*Quote, error)
+	GetAvailability(ctx context.Context, productID int, from, to time.Time) (*Availability, error)
+	GetBlackouts(ctx context.Context, productID int) ([]*Blackout, error)
+	AddBlackout(ctx context.Context, blackout *Blackout) (int, error)
+	UpdateBlackout(ctx context.Context, blackout *Blackout) error
+	DeleteBlackout(ctx context.Context, productID, blackoutID int) error
 	GetOrders(ctx context.Context, isMine bool) ([]*Order, error)
+	UploadProductPhoto(ctx context.Context, productID int, data []byte) (*Photo, error)
+	ReorderProductPhotos(ctx context.Context, productID int, photoIDs []int) error
+	SetCoverPhoto(ctx context.Context, productID, photoID int) error
+	UpdatePhotoCaption(ctx context.Context, productID, photoID int, caption *string) error
+	DeleteProductPhoto(ctx context.Context, productID, photoID int) error
+	OpenBlob(ctx context.Context, key string, expires int64, signature string) (*Blob, error)
+}
+
+type OrderService interface {
+	ApproveOrder(ctx context.Context, orderID int) error
+	RejectOrder(ctx context.Context, orderID int) error
+	StartOrder(ctx context.Context, orderID int) error
+	CompleteOrder(ctx context.Context, orderID int) error
+	CancelOrder(ctx context.Context, orderID int) error
+	ReviewOrder(ctx context.Context, orderID int, review *Review) (int, error)
+}
+
+type AdminService interface {
+	GetUsers(ctx context.Context, page, count int) ([]*User, int, error)
+	BlockUser(ctx context.Context, userID int, reason string) error
+	UnblockUser(ctx context.Context, userID int) error
+	SetUserRole(ctx context.Context, userID int, role Role) error
+	HideProduct(ctx context.Context, productID int, reason string) error
+	UnhideProduct(ctx context.Context, productID int) error
+	GetAllOrders(ctx context.Context, page, count int) ([]*Order, int, error)
+}
+
+type OutboxService interface {
+	SendPendingEmails() error
 }
 
 type service struct {
-	logger   logrus.FieldLogger
-	db       Database
-	security Security
+	logger        logrus.FieldLogger
+	config        *Config
+	db            Database
+	security      Security
+	mailer        Mailer
+	loginAttempts LoginAttemptStore
+	oidc          OIDC
+	blobs         BlobStore
+	images        ImageProcessor
+	pricing       *Pricing
+
+	dummyPasswordOnce sync.Once
+	dummyPasswordHash []byte
+	dummyPasswordSalt []byte
 }
 
-func NewService(logger logrus.FieldLogger, db Database, security Security) Service {
+func NewService(logger logrus.FieldLogger, config *Config, db Database, security Security, mailer Mailer, loginAttempts LoginAttemptStore, oidc OIDC, blobs BlobStore, images ImageProcessor) Service {
 	s := &service{
-		logger:   logger,
-		db:       db,
-		security: security,
+		logger:        logger,
+		config:        config,
+		db:            db,
+		security:      security,
+		mailer:        mailer,
+		loginAttempts: loginAttempts,
+		oidc:          oidc,
+		blobs:         blobs,
+		images:        images,
+		pricing:       NewPricing(DefaultDiscounts),
 	}
 
 	return s
 }
 
-func (s *service) Register(user *User) (string, error) {
+func (s *service) Register(ctx context.Context, user *User) (*Tokens, error) {
+	if user.Language != nil && !i18n.Supported(i18n.Language(*user.Language)) {
+		return nil, ErrInvalidInputData
+	}
+
 	// get hash password
 	passwordHash, salt, err := s.security.HashPassword(user.Password)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	user.PasswordHash = passwordHash
 	user.Salt = salt
 
 	userID, err := s.db.SaveUser(user)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
+	user.ID = userID
 
-	token, err := s.security.GenerateNewJWT(userID, 24*time.Hour)
-	if err != nil {
-		return "", nil
+	// the user is registered anyway, the link can be requested again
+	if err := s.sendVerificationEmail(ctx, user); err != nil {
+		s.logger.WithError(err).WithField("user_id", userID).Error("Error while sending verification email!")
 	}
 
-	return token, nil
+	return s.startSession(ctx, userID)
 }
 
-func (s *service) Login(u *User) (string, error) {
+// Login is throttled per account and per IP, failed attempts are audited.
+func (s *service) Login(ctx context.Context, u *User) (*Tokens, *Challenge, error) {
+	keys := s.loginKeys(ctx, u.Login)
+	if err := s.registerLoginAttempt(keys); err != nil {
+		if aerr := s.auditLogin(ctx, &LoginAttempt{Login: u.Login, Reason: LoginFailureThrottled}); aerr != nil {
+			return nil, nil, aerr
+		}
+		return nil, nil, err
+	}
+
 	user, err := s.db.GetUserByLogin(u.Login)
 	if err != nil {
-		return "", err
+		if !errors.Is(err, ErrNoSuchUser) {
+			return nil, nil, err
+		}
+
+		hash, salt := s.dummyPassword()
+		s.security.VerifyPassword(salt, hash, u.Password)
+		// the error is the same for unknown logins and wrong passwords
+		return nil, nil, s.failLogin(ctx, &LoginAttempt{Login: u.Login, Reason: LoginFailureUnknownLogin}, ErrInvalidCredentials)
 	}
 
 	if !s.security.VerifyPassword(user.Salt, user.PasswordHash, u.Password) {
-		return "", ErrInvalidInputData
+		return nil, nil, s.failLogin(ctx, &LoginAttempt{Login: u.Login, UserID: &user.ID, Reason: LoginFailureWrongPassword}, ErrInvalidCredentials)
 	}
 
-	token, err := s.security.GenerateNewJWT(user.ID, 24*time.Hour)
-	if err != nil {
-		return "", nil
+	// the IP is not reset, otherwise one valid account would unlock the guessing of the others,
+	// only this attempt is taken back
+	for key := range keys {
+		if key == accountLoginKey(u.Login) {
+			err = s.loginAttempts.ResetLoginFailures(key)
+		} else {
+			err = s.loginAttempts.UndoLoginFailure(key)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if user.TOTPEnabledAt != nil {
+		challenge, err := s.challengeLogin(user)
+		return nil, challenge, err
 	}
 
-	return token, nil
+	tokens, err := s.startSession(ctx, user.ID)
+	return tokens, nil, err
 }
 
+// GetUser returns the current user with the signed URL of their avatar.
 func (s *service) GetUser(ctx context.Context) (*User, error) {
 	userID := ctx.Value(ContextUserID).(int)
-	return s.db.GetUserByID(userID)
+	user, err := s.db.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.resolveAvatars(user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
 }
 
 func (s *service) AddProduct(ctx context.Context, product *Product) (int, error) {
+	if err := s.checkEmailVerified(ctx); err != nil {
+		return 0, err
+	}
+
 	// get user_id like owner
 	userID := ctx.Value(ContextUserID).(int)
 	product.OwnerID = userID
 
+	if product.CancellationPolicy == "" {
+		product.CancellationPolicy = CancellationPolicyFlexible
+	}
+	if !product.CancellationPolicy.Valid() {
+		return 0, ErrInvalidInputData
+	}
+
+	if product.Category == "" {
+		product.Category = ProductCategoryOther
+	}
+	if !product.Category.Valid() {
+		return 0, ErrInvalidInputData
+	}
+
 	return s.db.SaveProduct(product)
 }
 
-func (s *service) GetProductAndOwnerUserByProductID(productID int) (*Product, *User, error) {
+func (s *service) UpdateProduct(ctx context.Context, product *Product) error {
+	current, err := s.getOwnProduct(ctx, product.ID)
+	if err != nil {
+		return err
+	}
+
+	if current.ArchivedAt != nil {
+		return ErrNoSuchProduct
+	}
+
+	product.OwnerID = current.OwnerID
+
+	if product.CancellationPolicy == "" {
+		product.CancellationPolicy = current.CancellationPolicy
+	}
+	if !product.CancellationPolicy.Valid() {
+		return ErrInvalidInputData
+	}
+
+	if product.Category == "" {
+		product.Category = current.Category
+	}
+	if !product.Category.Valid() {
+		return ErrInvalidInputData
+	}
+
+	return s.db.UpdateProduct(product)
+}
+
+// ArchiveProduct takes the product down, it stays resolvable for the historic orders.
+func (s *service) ArchiveProduct(ctx context.Context, productID int) error {
+	product, err := s.getOwnProduct(ctx, productID)
+	if err != nil {
+		return err
+	}
+
+	if product.ArchivedAt != nil {
+		return nil
+	}
+
+	return s.db.ArchiveProduct(productID)
+}
+
+func (s *service) GetProductAndOwnerUserByProductID(ctx context.Context, productID int) (*Product, *User, error) {
 	product, err := s.db.GetProductByID(productID)
 	if err != nil {
 		return nil, nil, err
 	}
+	if product == nil || !canSeeProduct(ctx, product) {
+		return nil, nil, ErrNoSuchProduct
+	}
+
+	if err := s.resolvePhotos(product); err != nil {
+		return nil, nil, err
+	}
 
 	user, err := s.db.GetUserByID(product.OwnerID)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	if err := s.resolveAvatars(user); err != nil {
+		return nil, nil, err
+	}
+
 	return product, user, nil
 }
 
-func (s *service) GetProductsWithPagination(page, count int, search string) ([]*Product, int, error) {
-	return s.db.GetProductsWithPagination(count, page*count, search)
+// GetProductsWithPagination returns the listed products matching the query.
+func (s *service) GetProductsWithPagination(page, count int, query *ProductQuery) ([]*Product, int, error) {
+	query.Normalize()
+	if err := query.Validate(); err != nil {
+		return nil, 0, err
+	}
+
+	products, total, err := s.db.GetProductsWithPagination(count, page*count, query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return products, total, s.resolvePhotos(products...)
 }
 
 func (s *service) GetOrders(ctx context.Context, isMine bool) ([]*Order, error) {
@@ -124,24 +344,292 @@ func (s *service) GetOrders(ctx context.Context, isMine bool) ([]*Order, error)
 		return nil, err
 	}
 
+	return orders, s.loadOrderRelations(orders)
+}
+
+// loadOrderRelations fills in the products and the renters of the orders.
+func (s *service) loadOrderRelations(orders []*Order) error {
 	for i, v := range orders {
 		product, err := s.db.GetProductByID(v.ProductID)
 		if err != nil {
-			return nil, err
+			return err
+		}
+		if err := s.resolvePhotos(product); err != nil {
+			return err
 		}
 		orders[i].Product = product
 
 		user, err := s.db.GetUserByID(v.UserID)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		orders[i].User = user
 	}
 
-	return orders, nil
+	return nil
 }
 
 func (s *service) RentProduct(ctx context.Context, productID int, from, to time.Time) error {
 	userID := ctx.Value(ContextUserID).(int)
-	return s.db.RentProduct(productID, userID, from, to)
+
+	if err := s.checkEmailVerified(ctx); err != nil {
+		return err
+	}
+
+	quote, err := s.QuoteProduct(productID, from, to)
+	if err != nil {
+		return err
+	}
+
+	// the price is fixed at booking time,
+	// overlapping bookings are rejected atomically by the database
+	return s.db.RentProduct(productID, userID, from, to, quote.Total)
+}
+
+func (s *service) QuoteProduct(productID int, from, to time.Time) (*Quote, error) {
+	product, err := s.db.GetProductByID(productID)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil || product.ArchivedAt != nil || product.HiddenAt != nil {
+		return nil, ErrNoSuchProduct
+	}
+
+	return s.pricing.Quote(product, from, to)
+}
+
+func (s *service) ApproveOrder(ctx context.Context, orderID int) error {
+	return s.changeOrderStatusByOwner(ctx, orderID, OrderStatusApproved)
+}
+
+func (s *service) RejectOrder(ctx context.Context, orderID int) error {
+	return s.changeOrderStatusByOwner(ctx, orderID, OrderStatusRejected)
+}
+
+func (s *service) StartOrder(ctx context.Context, orderID int) error {
+	return s.changeOrderStatusByOwner(ctx, orderID, OrderStatusActive)
+}
+
+func (s *service) CompleteOrder(ctx context.Context, orderID int) error {
+	return s.changeOrderStatusByOwner(ctx, orderID, OrderStatusCompleted)
+}
+
+// ReviewOrder rates the other party of the completed order, the renter rates the owner and vice versa.
+func (s *service) ReviewOrder(ctx context.Context, orderID int, review *Review) (int, error) {
+	userID := ctx.Value(ContextUserID).(int)
+
+	order, err := s.db.GetOrderByID(orderID)
+	if err != nil {
+		return 0, err
+	}
+
+	product, err := s.db.GetProductByID(order.ProductID)
+	if err != nil {
+		return 0, err
+	}
+	if product == nil {
+		return 0, ErrNoSuchProduct
+	}
+
+	switch userID {
+	case order.UserID:
+		review.SubjectID = product.OwnerID
+	case product.OwnerID:
+		review.SubjectID = order.UserID
+	default:
+		return 0, ErrForbidden
+	}
+
+	if order.Status != OrderStatusCompleted {
+		return 0, ErrNotReviewable
+	}
+
+	review.OrderID = order.ID
+	review.AuthorID = userID
+
+	return s.db.SaveReview(review)
+}
+
+// changeOrderStatusByOwner moves the order to the next status on behalf of the product owner.
+func (s *service) changeOrderStatusByOwner(ctx context.Context, orderID int, next OrderStatus) error {
+	order, err := s.db.GetOrderByID(orderID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.getOwnProduct(ctx, order.ProductID); err != nil {
+		return err
+	}
+
+	if !order.Status.CanTransitionTo(next) {
+		return ErrIllegalOrderStatus
+	}
+
+	return s.db.UpdateOrderStatus(order.ID, order.Status, next)
+}
+
+// CancelOrder cancels the order on behalf of either the renter or the product owner.
+// The renter gets a refund according to the product cancellation policy,
+// while the owner cancelling always refunds the full price.
+func (s *service) CancelOrder(ctx context.Context, orderID int) error {
+	userID := ctx.Value(ContextUserID).(int)
+
+	order, err := s.db.GetOrderByID(orderID)
+	if err != nil {
+		return err
+	}
+
+	product, err := s.db.GetProductByID(order.ProductID)
+	if err != nil {
+		return err
+	}
+	if product == nil {
+		return ErrNoSuchProduct
+	}
+
+	var fraction float64
+	switch userID {
+	case product.OwnerID:
+		fraction = 1
+	case order.UserID:
+		fraction = product.CancellationPolicy.RefundFraction(time.Until(order.OrderStart))
+	default:
+		return ErrForbidden
+	}
+
+	if !order.Status.CanTransitionTo(OrderStatusCancelled) {
+		return ErrIllegalOrderStatus
+	}
+
+	refund := order.Price.Percent(fraction * 100)
+
+	return s.db.CancelOrder(order.ID, order.Status, userID, refund)
+}
+
+func (s *service) GetAvailability(ctx context.Context, productID int, from, to time.Time) (*Availability, error) {
+	if !from.Before(to) || to.Sub(from) > maxAvailabilityWindow {
+		return nil, ErrInvalidInputData
+	}
+
+	product, err := s.db.GetProductByID(productID)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil || !canSeeProduct(ctx, product) {
+		return nil, ErrNoSuchProduct
+	}
+
+	booked, err := s.db.GetBookedIntervals(productID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	blackouts, err := s.db.GetBlackouts(productID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	busy := make([]Interval, 0, len(booked)+len(blackouts))
+	busy = append(busy, booked...)
+	for _, v := range blackouts {
+		busy = append(busy, Interval{From: v.From, To: v.To})
+	}
+
+	return &Availability{
+		From:      from,
+		To:        to,
+		Booked:    booked,
+		Blackouts: blackouts,
+		Free:      freeIntervals(Interval{From: from, To: to}, busy),
+	}, nil
+}
+
+func (s *service) GetBlackouts(ctx context.Context, productID int) ([]*Blackout, error) {
+	if _, err := s.getOwnProduct(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	return s.db.GetBlackouts(productID, time.Time{}, time.Time{})
+}
+
+func (s *service) AddBlackout(ctx context.Context, blackout *Blackout) (int, error) {
+	if !blackout.From.Before(blackout.To) {
+		return 0, ErrInvalidInputData
+	}
+
+	if _, err := s.getOwnProduct(ctx, blackout.ProductID); err != nil {
+		return 0, err
+	}
+
+	return s.db.SaveBlackout(blackout)
+}
+
+func (s *service) UpdateBlackout(ctx context.Context, blackout *Blackout) error {
+	if !blackout.From.Before(blackout.To) {
+		return ErrInvalidInputData
+	}
+
+	if _, err := s.getOwnBlackout(ctx, blackout.ProductID, blackout.ID); err != nil {
+		return err
+	}
+
+	return s.db.UpdateBlackout(blackout)
+}
+
+func (s *service) DeleteBlackout(ctx context.Context, productID, blackoutID int) error {
+	if _, err := s.getOwnBlackout(ctx, productID, blackoutID); err != nil {
+		return err
+	}
+
+	return s.db.DeleteBlackout(blackoutID)
+}
+
+// canSeeProduct reports whether the current user may see the product,
+// the hidden products are only visible to their owners and the moderators.
+func canSeeProduct(ctx context.Context, product *Product) bool {
+	if product.HiddenAt == nil {
+		return true
+	}
+
+	userID, _ := ctx.Value(ContextUserID).(int)
+	role, _ := ctx.Value(ContextRole).(Role)
+
+	return userID == product.OwnerID || role.Can(PermissionModerateProducts)
+}
+
+// getOwnProduct returns the product if it belongs to the current user.
+func (s *service) getOwnProduct(ctx context.Context, productID int) (*Product, error) {
+	userID := ctx.Value(ContextUserID).(int)
+
+	product, err := s.db.GetProductByID(productID)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, ErrNoSuchProduct
+	}
+
+	if product.OwnerID != userID {
+		return nil, ErrForbidden
+	}
+
+	return product, nil
+}
+
+// getOwnBlackout returns the blackout if it belongs to the given product of the current user.
+func (s *service) getOwnBlackout(ctx context.Context, productID, blackoutID int) (*Blackout, error) {
+	if _, err := s.getOwnProduct(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	blackout, err := s.db.GetBlackoutByID(blackoutID)
+	if err != nil {
+		return nil, err
+	}
+
+	if blackout.ProductID != productID {
+		return nil, ErrNoSuchBlackout
+	}
+
+	return blackout, nil
 }
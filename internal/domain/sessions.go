@@ -0,0 +1,239 @@
+package domain
+
+import (
+	"context"
+	"crypto"
+	"time"
+)
+
+// Session revoke reasons.
+const (
+	RevokeReasonLogout            = "logout"
+	RevokeReasonUser              = "revoked_by_user"
+	RevokeReasonRefreshTokenReuse = "refresh_token_reuse"
+	RevokeReasonPasswordReset     = "password_reset"
+	RevokeReasonPasswordChanged   = "password_changed"
+	RevokeReasonBlocked           = "blocked"
+	RevokeReasonRoleChanged       = "role_changed"
+)
+
+// Client describes the device the request is made from.
+type Client struct {
+	IP        string
+	UserAgent string
+}
+
+// Session is created on every login and lives as long as its refresh tokens are rotated.
+type Session struct {
+	ID           int
+	UserID       int
+	UserAgent    string
+	IP           string
+	CreatedAt    time.Time
+	LastUsedAt   time.Time
+	ExpiresAt    time.Time
+	RevokedAt    *time.Time
+	RevokeReason *string
+}
+
+func (s *Session) Active() bool {
+	return s.RevokedAt == nil && time.Now().Before(s.ExpiresAt)
+}
+
+// RefreshToken is a single-use token exchanged for a new pair of tokens.
+type RefreshToken struct {
+	ID        int
+	SessionID int
+	Hash      []byte
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+}
+
+// TokenClaims are encoded into the access token.
+type TokenClaims struct {
+	UserID    int
+	SessionID int
+	Role      Role
+}
+
+// PublicKey verifies the tokens signed with the private key identified by KID.
+type PublicKey struct {
+	KID       string
+	Algorithm string
+	Key       crypto.PublicKey
+}
+
+type Tokens struct {
+	AccessToken           string
+	AccessTokenExpiresAt  time.Time
+	RefreshToken          string
+	RefreshTokenExpiresAt time.Time
+}
+
+func (s *service) Refresh(ctx context.Context, refreshToken string) (*Tokens, error) {
+	token, err := s.db.GetRefreshTokenByHash(s.security.HashOpaqueToken(refreshToken))
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := s.db.GetSessionByID(token.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !session.Active() || !time.Now().Before(token.ExpiresAt) {
+		return nil, ErrUnauthorized
+	}
+
+	// an already used token means it was stolen, so the whole session is revoked
+	used, err := s.db.UseRefreshToken(token.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !used {
+		s.logger.WithField("session_id", session.ID).Warn("Refresh token reuse detected, revoking the session!")
+		if err := s.db.RevokeSession(session.ID, RevokeReasonRefreshTokenReuse); err != nil {
+			return nil, err
+		}
+		return nil, ErrUnauthorized
+	}
+
+	return s.rotateTokens(session)
+}
+
+func (s *service) Logout(ctx context.Context) error {
+	sessionID := ctx.Value(ContextSessionID).(int)
+	return s.db.RevokeSession(sessionID, RevokeReasonLogout)
+}
+
+func (s *service) GetSessions(ctx context.Context) ([]*Session, error) {
+	userID := ctx.Value(ContextUserID).(int)
+	return s.db.GetActiveSessions(userID)
+}
+
+func (s *service) RevokeSession(ctx context.Context, sessionID int) error {
+	userID := ctx.Value(ContextUserID).(int)
+
+	session, err := s.db.GetSessionByID(sessionID)
+	if err != nil {
+		return err
+	}
+
+	if session.UserID != userID {
+		return ErrNoSuchSession
+	}
+
+	return s.db.RevokeSession(sessionID, RevokeReasonUser)
+}
+
+// RevokeOtherSessions revokes all sessions of the user except the current one.
+func (s *service) RevokeOtherSessions(ctx context.Context) error {
+	userID := ctx.Value(ContextUserID).(int)
+	sessionID := ctx.Value(ContextSessionID).(int)
+
+	return s.db.RevokeUserSessions(userID, sessionID, RevokeReasonUser)
+}
+
+// Authenticate verifies the access token and makes sure it belongs to an active session of the user.
+func (s *service) Authenticate(token string) (*TokenClaims, error) {
+	claims, err := s.security.ParseJWT(token)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := s.db.GetSessionByID(claims.SessionID)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+
+	if session.UserID != claims.UserID || !session.Active() {
+		return nil, ErrUnauthorized
+	}
+
+	return claims, nil
+}
+
+func (s *service) GetPublicKeys() []*PublicKey {
+	return s.security.PublicKeys()
+}
+
+// startSession creates a new session of the user and issues its first tokens.
+func (s *service) startSession(ctx context.Context, userID int) (*Tokens, error) {
+	user, err := s.db.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.BlockedAt != nil {
+		return nil, ErrUserBlocked
+	}
+
+	session := &Session{
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(s.config.RefreshTokenTTL),
+	}
+
+	if client, ok := ctx.Value(ContextClient).(*Client); ok {
+		session.IP = client.IP
+		session.UserAgent = client.UserAgent
+	}
+
+	sessionID, err := s.db.SaveSession(session)
+	if err != nil {
+		return nil, err
+	}
+	session.ID = sessionID
+
+	return s.rotateTokens(session)
+}
+
+// rotateTokens issues a new access token and a new refresh token of the session, prolonging it.
+// Blocked users get no tokens.
+func (s *service) rotateTokens(session *Session) (*Tokens, error) {
+	user, err := s.db.GetUserByID(session.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.BlockedAt != nil {
+		return nil, ErrUserBlocked
+	}
+
+	now := time.Now()
+
+	refreshToken, err := s.security.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := &Tokens{
+		AccessTokenExpiresAt:  now.Add(s.config.AccessTokenTTL),
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: now.Add(s.config.RefreshTokenTTL),
+	}
+
+	if err := s.db.SaveRefreshToken(&RefreshToken{
+		SessionID: session.ID,
+		Hash:      s.security.HashOpaqueToken(refreshToken),
+		ExpiresAt: tokens.RefreshTokenExpiresAt,
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.TouchSession(session.ID, tokens.RefreshTokenExpiresAt); err != nil {
+		return nil, err
+	}
+
+	tokens.AccessToken, err = s.security.GenerateNewJWT(&TokenClaims{
+		UserID:    session.UserID,
+		SessionID: session.ID,
+		Role:      user.Role,
+	}, s.config.AccessTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
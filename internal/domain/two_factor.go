@@ -0,0 +1,226 @@
+package domain
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+const recoveryCodesCount = 10
+
+// TwoFactorEnrollment is the pending TOTP secret shown to the user to set up the authenticator app.
+type TwoFactorEnrollment struct {
+	Secret []byte
+	KeyURI string
+}
+
+// Challenge is issued by Login instead of the tokens when the user has two-factor authentication enabled,
+// it is exchanged together with a TOTP or recovery code for the tokens.
+type Challenge struct {
+	Token     string
+	ExpiresAt time.Time
+}
+
+// EnrollTwoFactor generates a new TOTP secret, it is not required at login until confirmed.
+func (s *service) EnrollTwoFactor(ctx context.Context) (*TwoFactorEnrollment, error) {
+	user, err := s.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TOTPEnabledAt != nil {
+		return nil, ErrTwoFactorEnabled
+	}
+
+	secret, err := s.security.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.SetTOTPSecret(user.ID, secret); err != nil {
+		return nil, err
+	}
+
+	return &TwoFactorEnrollment{
+		Secret: secret,
+		KeyURI: s.security.TOTPKeyURI(secret, user.Login),
+	}, nil
+}
+
+// ConfirmTwoFactor enables two-factor authentication once the user proves the app is set up,
+// the recovery codes are returned only here.
+func (s *service) ConfirmTwoFactor(ctx context.Context, code string) ([]string, error) {
+	user, err := s.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TOTPEnabledAt != nil {
+		return nil, ErrTwoFactorEnabled
+	}
+	if user.TOTPSecret == nil {
+		return nil, ErrTwoFactorNotEnrolled
+	}
+
+	if err := s.verifySecondFactor(ctx, user, code); err != nil {
+		return nil, err
+	}
+
+	codes, err := s.newRecoveryCodes(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.EnableTOTP(user.ID); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func (s *service) DisableTwoFactor(ctx context.Context, code string) error {
+	user, err := s.getTwoFactorUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := s.verifySecondFactor(ctx, user, code); err != nil {
+		return err
+	}
+
+	return s.db.DisableTOTP(user.ID)
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes, the old ones stop working.
+func (s *service) RegenerateRecoveryCodes(ctx context.Context, code string) ([]string, error) {
+	user, err := s.getTwoFactorUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.verifySecondFactor(ctx, user, code); err != nil {
+		return nil, err
+	}
+
+	return s.newRecoveryCodes(user.ID)
+}
+
+// LoginTwoFactor completes the login started by Login.
+func (s *service) LoginTwoFactor(ctx context.Context, challengeToken, code string) (*Tokens, error) {
+	t, err := s.getUserToken(challengeToken, TokenPurposeLoginChallenge)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.db.GetUserByID(t.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TOTPEnabledAt == nil {
+		return nil, ErrInvalidToken
+	}
+
+	if err := s.verifySecondFactor(ctx, user, code); err != nil {
+		return nil, err
+	}
+
+	used, err := s.db.UseUserToken(t.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !used {
+		return nil, ErrInvalidToken
+	}
+
+	return s.startSession(ctx, user.ID)
+}
+
+// challengeLogin issues the challenge the second factor is checked against.
+func (s *service) challengeLogin(user *User) (*Challenge, error) {
+	token, err := s.issueUserToken(user.ID, TokenPurposeLoginChallenge, s.config.TwoFactorChallengeTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Challenge{
+		Token:     token,
+		ExpiresAt: time.Now().Add(s.config.TwoFactorChallengeTTL),
+	}, nil
+}
+
+// verifySecondFactor checks the TOTP code or, once two-factor authentication is enabled, the recovery code.
+// A code is accepted only once, the failures are throttled like the failed logins.
+func (s *service) verifySecondFactor(ctx context.Context, user *User, code string) error {
+	keys := map[string]int{
+		"2fa:" + strconv.Itoa(user.ID): s.config.LoginLockoutThreshold,
+	}
+	if err := s.registerLoginAttempt(keys); err != nil {
+		return err
+	}
+
+	ok, err := s.checkSecondFactor(user, code)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		attempt := &LoginAttempt{Login: user.Login, UserID: &user.ID, Reason: LoginFailureWrongCode}
+		return s.failLogin(ctx, attempt, ErrInvalidTwoFactorCode)
+	}
+
+	for key := range keys {
+		if err := s.loginAttempts.ResetLoginFailures(key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *service) checkSecondFactor(user *User, code string) (bool, error) {
+	if step, ok := s.security.ValidateTOTP(user.TOTPSecret, code, time.Now()); ok {
+		// the step is remembered, so that an intercepted code cannot be replayed
+		return s.db.UseTOTPStep(user.ID, step)
+	}
+
+	if user.TOTPEnabledAt == nil {
+		return false, nil
+	}
+
+	return s.db.UseRecoveryCode(user.ID, s.security.HashRecoveryCode(code))
+}
+
+func (s *service) newRecoveryCodes(userID int) ([]string, error) {
+	codes := make([]string, 0, recoveryCodesCount)
+	hashes := make([][]byte, 0, recoveryCodesCount)
+	for i := 0; i < recoveryCodesCount; i++ {
+		code, err := s.security.GenerateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+
+		codes = append(codes, code)
+		hashes = append(hashes, s.security.HashRecoveryCode(code))
+	}
+
+	if err := s.db.ReplaceRecoveryCodes(userID, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// getTwoFactorUser returns the current user if two-factor authentication is enabled.
+func (s *service) getTwoFactorUser(ctx context.Context) (*User, error) {
+	user, err := s.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TOTPEnabledAt == nil {
+		return nil, ErrTwoFactorNotEnabled
+	}
+
+	return user, nil
+}
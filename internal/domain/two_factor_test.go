@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+// testTOTPSecurity accepts the code "123456" at the time step it is set to.
+type testTOTPSecurity struct {
+	Security
+	step int64
+}
+
+func (s *testTOTPSecurity) ValidateTOTP(secret []byte, code string, at time.Time) (int64, bool) {
+	return s.step, code == "123456"
+}
+
+func (s *testTOTPSecurity) HashRecoveryCode(code string) []byte {
+	return []byte(code)
+}
+
+// testTOTPDatabase remembers the last used time step like the totp_last_step column.
+type testTOTPDatabase struct {
+	Database
+	lastStep *int64
+}
+
+func (d *testTOTPDatabase) UseTOTPStep(userID int, step int64) (bool, error) {
+	if d.lastStep != nil && *d.lastStep >= step {
+		return false, nil
+	}
+
+	d.lastStep = &step
+	return true, nil
+}
+
+func (d *testTOTPDatabase) UseRecoveryCode(userID int, hash []byte) (bool, error) {
+	return false, nil
+}
+
+func TestCheckSecondFactorReusedStep(t *testing.T) {
+	sec := &testTOTPSecurity{}
+	s := &service{
+		logger:   newTestLogger(),
+		db:       &testTOTPDatabase{},
+		security: sec,
+	}
+	enabledAt := time.Now()
+	user := &User{ID: 1, TOTPEnabledAt: &enabledAt}
+
+	tests := []struct {
+		name string
+		step int64
+		code string
+		want bool
+	}{
+		{name: "first code", step: 100, code: "123456", want: true},
+		{name: "same step reused", step: 100, code: "123456", want: false},
+		{name: "older step", step: 99, code: "123456", want: false},
+		{name: "next step", step: 101, code: "123456", want: true},
+		{name: "wrong code", step: 102, code: "654321", want: false},
+		{name: "step after wrong code", step: 102, code: "123456", want: true},
+	}
+
+	for _, tt := range tests {
+		sec.step = tt.step
+		got, err := s.checkSecondFactor(user, tt.code)
+		if err != nil {
+			t.Fatalf("%s: checkSecondFactor() error = %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: checkSecondFactor() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -4,35 +4,178 @@ import "time"
 
 type ContextKey string
 
-const ContextUserID ContextKey = "ctx_user_id"
+const (
+	ContextUserID    ContextKey = "ctx_user_id"
+	ContextSessionID ContextKey = "ctx_session_id"
+	ContextClient    ContextKey = "ctx_client"
+	ContextLanguage  ContextKey = "ctx_language"
+	ContextRole      ContextKey = "ctx_role"
+)
 
 type User struct {
-	ID           int
-	FirstName    string
-	LastName     string
-	Login        string
-	Email        string
-	Password     string
-	PasswordHash []byte
-	Salt         []byte
+	ID              int
+	FirstName       string
+	LastName        string
+	Login           string
+	Email           string
+	Password        string
+	PasswordHash    []byte
+	Salt            []byte
+	Language        *string
+	EmailVerifiedAt *time.Time
+	TOTPSecret      []byte
+	TOTPEnabledAt   *time.Time
+	Role            Role
+	BlockedAt       *time.Time
+	BlockReason     *string
+	// Avatar is the signed URL of the uploaded avatar
+	Avatar    *string
+	AvatarKey *string
+	CreatedAt time.Time
+	DeletedAt *time.Time
+}
+
+// DisplayName is the name shown to the other users, the last name is shortened to its initial.
+func (u *User) DisplayName() string {
+	for _, r := range u.LastName {
+		return u.FirstName + " " + string(r) + "."
+	}
+
+	return u.FirstName
 }
 
 type Product struct {
-	ID          int
-	OwnerID     int
-	Name        string
-	PerHour     float64
-	Description *string
-	Photos      []string
+	ID                 int
+	OwnerID            int
+	Name               string
+	PerHour            float64
+	PerDay             *float64
+	PerWeek            *float64
+	Description        *string
+	Photos             []*Photo
+	CancellationPolicy CancellationPolicy
+	Category           ProductCategory
+	ArchivedAt         *time.Time
+	HiddenAt           *time.Time
+	// Snippet is the part of the description matching the search, it is HTML with the matches in <mark>
+	Snippet *string
+}
+
+type CancellationPolicy string
+
+const (
+	CancellationPolicyFlexible CancellationPolicy = "flexible"
+	CancellationPolicyModerate CancellationPolicy = "moderate"
+	CancellationPolicyStrict   CancellationPolicy = "strict"
+)
+
+func (p CancellationPolicy) Valid() bool {
+	switch p {
+	case CancellationPolicyFlexible, CancellationPolicyModerate, CancellationPolicyStrict:
+		return true
+	}
+
+	return false
+}
+
+// RefundFraction returns the refundable part of the order price
+// when the renter cancels the order `notice` before its start.
+func (p CancellationPolicy) RefundFraction(notice time.Duration) float64 {
+	const day = 24 * time.Hour
+
+	switch p {
+	case CancellationPolicyFlexible:
+		switch {
+		case notice >= day:
+			return 1
+		case notice > 0:
+			return 0.5
+		}
+	case CancellationPolicyModerate:
+		switch {
+		case notice >= 5*day:
+			return 1
+		case notice >= day:
+			return 0.5
+		}
+	case CancellationPolicyStrict:
+		if notice >= 7*day {
+			return 0.5
+		}
+	}
+
+	return 0
+}
+
+type ProductCategory string
+
+const (
+	ProductCategoryTools       ProductCategory = "tools"
+	ProductCategoryElectronics ProductCategory = "electronics"
+	ProductCategorySports      ProductCategory = "sports"
+	ProductCategoryOutdoor     ProductCategory = "outdoor"
+	ProductCategoryVehicles    ProductCategory = "vehicles"
+	ProductCategoryHome        ProductCategory = "home"
+	ProductCategoryEvents      ProductCategory = "events"
+	ProductCategoryOther       ProductCategory = "other"
+)
+
+func (c ProductCategory) Valid() bool {
+	switch c {
+	case ProductCategoryTools, ProductCategoryElectronics, ProductCategorySports, ProductCategoryOutdoor,
+		ProductCategoryVehicles, ProductCategoryHome, ProductCategoryEvents, ProductCategoryOther:
+		return true
+	}
+
+	return false
+}
+
+type OrderStatus string
+
+const (
+	OrderStatusPending   OrderStatus = "pending"
+	OrderStatusApproved  OrderStatus = "approved"
+	OrderStatusRejected  OrderStatus = "rejected"
+	OrderStatusActive    OrderStatus = "active"
+	OrderStatusCompleted OrderStatus = "completed"
+	OrderStatusCancelled OrderStatus = "cancelled"
+)
+
+// orderTransitions describes the order lifecycle:
+// pending -> approved/rejected -> active -> completed/cancelled.
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	OrderStatusPending:  {OrderStatusApproved, OrderStatusRejected, OrderStatusCancelled},
+	OrderStatusApproved: {OrderStatusActive, OrderStatusCancelled},
+	OrderStatusActive:   {OrderStatusCompleted, OrderStatusCancelled},
+}
+
+// CanTransitionTo reports whether an order in status s may be moved to next.
+func (s OrderStatus) CanTransitionTo(next OrderStatus) bool {
+	for _, v := range orderTransitions[s] {
+		if v == next {
+			return true
+		}
+	}
+
+	return false
 }
 
 type Order struct {
-	ID         int
-	OrderStart time.Time
-	OrderEnd   time.Time
-	UserID     int
-	ProductID  int
-	Price      float64
-	User       *User
-	Product    *Product
+	ID           int
+	OrderStart   time.Time
+	OrderEnd     time.Time
+	UserID       int
+	ProductID    int
+	Price        Money
+	Status       OrderStatus
+	CreatedAt    time.Time
+	ApprovedAt   *time.Time
+	RejectedAt   *time.Time
+	ActivatedAt  *time.Time
+	CompletedAt  *time.Time
+	CancelledAt  *time.Time
+	CancelledBy  *int
+	RefundAmount *Money
+	User         *User
+	Product      *Product
 }
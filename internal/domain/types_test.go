@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRefundFraction(t *testing.T) {
+	const day = 24 * time.Hour
+
+	tests := []struct {
+		policy CancellationPolicy
+		notice time.Duration
+		want   float64
+	}{
+		{policy: CancellationPolicyFlexible, notice: -time.Hour, want: 0},
+		{policy: CancellationPolicyFlexible, notice: 0, want: 0},
+		{policy: CancellationPolicyFlexible, notice: time.Second, want: 0.5},
+		{policy: CancellationPolicyFlexible, notice: day - time.Second, want: 0.5},
+		{policy: CancellationPolicyFlexible, notice: day, want: 1},
+		{policy: CancellationPolicyFlexible, notice: 7 * day, want: 1},
+
+		{policy: CancellationPolicyModerate, notice: -time.Hour, want: 0},
+		{policy: CancellationPolicyModerate, notice: 0, want: 0},
+		{policy: CancellationPolicyModerate, notice: day - time.Second, want: 0},
+		{policy: CancellationPolicyModerate, notice: day, want: 0.5},
+		{policy: CancellationPolicyModerate, notice: 5*day - time.Second, want: 0.5},
+		{policy: CancellationPolicyModerate, notice: 5 * day, want: 1},
+		{policy: CancellationPolicyModerate, notice: 7 * day, want: 1},
+
+		{policy: CancellationPolicyStrict, notice: -time.Hour, want: 0},
+		{policy: CancellationPolicyStrict, notice: 0, want: 0},
+		{policy: CancellationPolicyStrict, notice: day, want: 0},
+		{policy: CancellationPolicyStrict, notice: 5 * day, want: 0},
+		{policy: CancellationPolicyStrict, notice: 7*day - time.Second, want: 0},
+		{policy: CancellationPolicyStrict, notice: 7 * day, want: 0.5},
+		{policy: CancellationPolicyStrict, notice: 30 * day, want: 0.5},
+
+		{policy: CancellationPolicy("unknown"), notice: 30 * day, want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := tt.policy.RefundFraction(tt.notice); got != tt.want {
+			t.Errorf("%s.RefundFraction(%v) = %v, want %v", tt.policy, tt.notice, got, tt.want)
+		}
+	}
+}
+
+func TestCanTransitionTo(t *testing.T) {
+	statuses := []OrderStatus{
+		OrderStatusPending,
+		OrderStatusApproved,
+		OrderStatusRejected,
+		OrderStatusActive,
+		OrderStatusCompleted,
+		OrderStatusCancelled,
+	}
+
+	// every pair not listed is forbidden, the rejected, completed and cancelled orders are final
+	allowed := map[[2]OrderStatus]bool{
+		{OrderStatusPending, OrderStatusApproved}:   true,
+		{OrderStatusPending, OrderStatusRejected}:   true,
+		{OrderStatusPending, OrderStatusCancelled}:  true,
+		{OrderStatusApproved, OrderStatusActive}:    true,
+		{OrderStatusApproved, OrderStatusCancelled}: true,
+		{OrderStatusActive, OrderStatusCompleted}:   true,
+		{OrderStatusActive, OrderStatusCancelled}:   true,
+	}
+
+	for _, from := range statuses {
+		for _, to := range statuses {
+			want := allowed[[2]OrderStatus{from, to}]
+			if got := from.CanTransitionTo(to); got != want {
+				t.Errorf("%s.CanTransitionTo(%s) = %v, want %v", from, to, got, want)
+			}
+		}
+	}
+
+	for _, s := range statuses {
+		if s.CanTransitionTo("unknown") || OrderStatus("unknown").CanTransitionTo(s) {
+			t.Errorf("transition between %s and an unknown status allowed", s)
+		}
+	}
+}
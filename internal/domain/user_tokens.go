@@ -0,0 +1,73 @@
+package domain
+
+import "time"
+
+// User token purposes.
+const (
+	TokenPurposePasswordReset     = "password_reset"
+	TokenPurposeEmailVerification = "email_verification"
+	TokenPurposeLoginChallenge    = "login_challenge"
+)
+
+// UserToken is a single-use token sent to the user by email, only its hash is stored.
+type UserToken struct {
+	ID        int
+	UserID    int
+	Purpose   string
+	Hash      []byte
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+}
+
+// issueUserToken stores a new token of the user and returns its plain value.
+func (s *service) issueUserToken(userID int, purpose string, ttl time.Duration) (string, error) {
+	token, err := s.security.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.db.SaveUserToken(&UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		Hash:      s.security.HashOpaqueToken(token),
+		ExpiresAt: time.Now().Add(ttl),
+	}); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// getUserToken returns the token if it can be used,
+// ErrInvalidToken is returned for unknown, expired and already used tokens.
+func (s *service) getUserToken(token, purpose string) (*UserToken, error) {
+	t, err := s.db.GetUserTokenByHash(purpose, s.security.HashOpaqueToken(token))
+	if err != nil {
+		return nil, err
+	}
+
+	if t.UsedAt != nil || !time.Now().Before(t.ExpiresAt) {
+		return nil, ErrInvalidToken
+	}
+
+	return t, nil
+}
+
+// useUserToken marks the token used and returns it.
+func (s *service) useUserToken(token, purpose string) (*UserToken, error) {
+	t, err := s.getUserToken(token, purpose)
+	if err != nil {
+		return nil, err
+	}
+
+	used, err := s.db.UseUserToken(t.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !used {
+		return nil, ErrInvalidToken
+	}
+
+	return t, nil
+}
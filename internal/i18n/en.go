@@ -0,0 +1,54 @@
+package i18n
+
+var en = map[string]string{
+	// errors
+	"invalid_input_data":      "Invalid input data!",
+	"invalid_token":           "The link is invalid or expired!",
+	"invalid_two_factor_code": "Invalid authentication code!",
+	"wrong_password":          "The current password is wrong!",
+	"invalid_photo_order":     "Every photo of the product has to be listed once!",
+	"no_such_user":            "User not found!",
+	"no_such_product":         "Product not found!",
+	"no_such_order":           "Order not found!",
+	"no_such_session":         "Session not found!",
+	"no_such_blackout":        "Blackout period not found!",
+	"route_not_found":         "Page not found!",
+	"method_not_allowed":      "Method not allowed!",
+	"product_unavailable":     "The product is unavailable for the selected period!",
+	"illegal_order_status":    "The order status cannot be changed this way!",
+	"login_taken":             "This login is already taken!",
+	"email_taken":             "This email is already registered!",
+	"two_factor_enabled":      "Two-factor authentication is already enabled!",
+	"two_factor_not_enabled":  "Two-factor authentication is not enabled!",
+	"two_factor_not_enrolled": "Set up two-factor authentication first!",
+	"unauthorized":            "Authorization required!",
+	"invalid_credentials":     "Invalid login or password!",
+	"too_many_login_attempts": "Too many login attempts, try again later!",
+	"forbidden":               "Access denied!",
+	"email_not_verified":      "Please verify your email first!",
+	"user_blocked":            "Your account is blocked!",
+	"no_such_provider":        "Sign in method not found!",
+	"no_such_identity":        "Linked account not found!",
+	"no_such_photo":           "Photo not found!",
+	"identity_taken":          "This account is already linked to another user!",
+	"identity_not_linked":     "A user with this email already exists, sign in and link the account in the settings!",
+	"last_login_method":       "This is the only way to sign in, set a password first!",
+	"not_reviewable":          "Only completed orders can be reviewed!",
+	"already_reviewed":        "You have already reviewed this order!",
+	"active_rentals":          "You have active rentals, complete or cancel them first!",
+	"too_many_photos":         "The product already has the maximum number of photos!",
+	"photo_too_large":         "The photo is too large!",
+	"unsupported_photo_type":  "Only JPEG and PNG photos are supported!",
+	"identity_provider":       "The sign in service is unavailable, try again later!",
+	"internal":                "Internal error!",
+	"internal_security":       "Internal error!",
+	"internal_database":       "Internal error!",
+	"internal_storage":        "Internal error!",
+	"jwt":                     "Internal error!",
+
+	// emails
+	"email.password_reset.subject": "Password reset",
+	"email.password_reset.body":    "Hello, %s!\n\nTo set a new password follow the link:\n%s\n\nThe link is valid for %d minutes. If you did not request a password reset, just ignore this email.\n\nSharito",
+	"email.verification.subject":   "Email verification",
+	"email.verification.body":      "Hello, %s!\n\nTo confirm your email follow the link:\n%s\n\nThe link is valid for %d hours. If you did not register on Sharito, just ignore this email.\n\nSharito",
+}
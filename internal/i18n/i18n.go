@@ -0,0 +1,106 @@
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type Language string
+
+const (
+	English Language = "en"
+	Russian Language = "ru"
+
+	// Fallback is used for the messages missing in the requested language.
+	Fallback = English
+)
+
+// bundles contains messages keyed by error codes and message keys.
+var bundles = map[Language]map[string]string{
+	English: en,
+	Russian: ru,
+}
+
+// Supported reports whether there is a bundle for the language.
+func Supported(lang Language) bool {
+	_, ok := bundles[lang]
+	return ok
+}
+
+// ParseLanguage returns the supported language matching the tag like "ru" or "en-US".
+func ParseLanguage(tag string) (Language, bool) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+
+	lang := Language(tag)
+	return lang, Supported(lang)
+}
+
+// FromAcceptLanguage returns the most preferred supported language of the Accept-Language header.
+func FromAcceptLanguage(header string) (Language, bool) {
+	var (
+		best        Language
+		bestQuality float64
+	)
+
+	for _, part := range strings.Split(header, ",") {
+		tag, quality := part, 1.0
+		if i := strings.Index(part, ";"); i >= 0 {
+			tag = part[:i]
+			if q := strings.TrimSpace(part[i+1:]); strings.HasPrefix(q, "q=") {
+				v, err := strconv.ParseFloat(q[2:], 64)
+				if err != nil {
+					continue
+				}
+				quality = v
+			}
+		}
+
+		lang, ok := ParseLanguage(tag)
+		if ok && quality > bestQuality {
+			best, bestQuality = lang, quality
+		}
+	}
+
+	return best, best != ""
+}
+
+// Localize returns the message in the language, falling back to English and then to the key itself.
+func Localize(lang Language, key string, args ...interface{}) string {
+	msg, ok := bundles[lang][key]
+	if !ok {
+		msg, ok = bundles[Fallback][key]
+	}
+	if !ok {
+		return key
+	}
+
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+
+	return msg
+}
+
+// Missing returns the keys of the fallback bundle absent in the other bundles.
+func Missing() map[Language][]string {
+	missing := make(map[Language][]string)
+	for lang, bundle := range bundles {
+		if lang == Fallback {
+			continue
+		}
+
+		for key := range bundles[Fallback] {
+			if _, ok := bundle[key]; !ok {
+				missing[lang] = append(missing[lang], key)
+			}
+		}
+		sort.Strings(missing[lang])
+	}
+
+	return missing
+}
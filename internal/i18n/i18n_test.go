@@ -0,0 +1,21 @@
+package i18n_test
+
+import (
+	"backend/internal/domain"
+	"backend/internal/i18n"
+	"testing"
+)
+
+func TestMissingTranslations(t *testing.T) {
+	for lang, keys := range i18n.Missing() {
+		t.Errorf("%s lacks translations of %v", lang, keys)
+	}
+}
+
+func TestErrorMessages(t *testing.T) {
+	for _, err := range domain.Errors() {
+		if msg := i18n.Localize(i18n.English, err.Code); msg == err.Code {
+			t.Errorf("error %q has no English message", err.Code)
+		}
+	}
+}
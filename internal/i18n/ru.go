@@ -0,0 +1,54 @@
+package i18n
+
+var ru = map[string]string{
+	// errors
+	"invalid_input_data":      "Некорректные данные!",
+	"invalid_token":           "Ссылка недействительна или устарела!",
+	"invalid_two_factor_code": "Неверный код подтверждения!",
+	"wrong_password":          "Неверный текущий пароль!",
+	"invalid_photo_order":     "Каждая фотография товара должна быть указана один раз!",
+	"no_such_user":            "Пользователь не найден!",
+	"no_such_product":         "Товар не найден!",
+	"no_such_order":           "Заказ не найден!",
+	"no_such_session":         "Сессия не найдена!",
+	"no_such_blackout":        "Период недоступности не найден!",
+	"route_not_found":         "Страница не найдена!",
+	"method_not_allowed":      "Метод не поддерживается!",
+	"product_unavailable":     "Товар недоступен в выбранный период!",
+	"illegal_order_status":    "Недопустимое изменение статуса заказа!",
+	"login_taken":             "Этот логин уже занят!",
+	"email_taken":             "Этот email уже зарегистрирован!",
+	"two_factor_enabled":      "Двухфакторная аутентификация уже включена!",
+	"two_factor_not_enabled":  "Двухфакторная аутентификация не включена!",
+	"two_factor_not_enrolled": "Сначала настройте двухфакторную аутентификацию!",
+	"unauthorized":            "Необходима авторизация!",
+	"invalid_credentials":     "Неверный логин или пароль!",
+	"too_many_login_attempts": "Слишком много попыток входа, попробуйте позже!",
+	"forbidden":               "Доступ запрещён!",
+	"email_not_verified":      "Сначала подтвердите email!",
+	"user_blocked":            "Ваш аккаунт заблокирован!",
+	"no_such_provider":        "Способ входа не найден!",
+	"no_such_identity":        "Привязанный аккаунт не найден!",
+	"no_such_photo":           "Фотография не найдена!",
+	"identity_taken":          "Этот аккаунт уже привязан к другому пользователю!",
+	"identity_not_linked":     "Пользователь с таким email уже существует, войдите и привяжите аккаунт в настройках!",
+	"last_login_method":       "Это единственный способ входа, сначала задайте пароль!",
+	"not_reviewable":          "Отзыв можно оставить только о завершённом заказе!",
+	"already_reviewed":        "Вы уже оставили отзыв об этом заказе!",
+	"active_rentals":          "У вас есть незавершённые заказы, завершите или отмените их!",
+	"too_many_photos":         "У товара уже максимальное количество фотографий!",
+	"photo_too_large":         "Фотография слишком большая!",
+	"unsupported_photo_type":  "Поддерживаются только фотографии в форматах JPEG и PNG!",
+	"identity_provider":       "Сервис входа недоступен, попробуйте позже!",
+	"internal":                "Внутренняя ошибка!",
+	"internal_security":       "Внутренняя ошибка!",
+	"internal_database":       "Внутренняя ошибка!",
+	"internal_storage":        "Внутренняя ошибка!",
+	"jwt":                     "Внутренняя ошибка!",
+
+	// emails
+	"email.password_reset.subject": "Восстановление пароля",
+	"email.password_reset.body":    "Здравствуйте, %s!\n\nЧтобы задать новый пароль, перейдите по ссылке:\n%s\n\nСсылка действительна %d минут. Если вы не запрашивали восстановление пароля, просто проигнорируйте это письмо.\n\nSharito",
+	"email.verification.subject":   "Подтверждение email",
+	"email.verification.body":      "Здравствуйте, %s!\n\nЧтобы подтвердить email, перейдите по ссылке:\n%s\n\nСсылка действительна %d ч. Если вы не регистрировались на Sharito, просто проигнорируйте это письмо.\n\nSharito",
+}
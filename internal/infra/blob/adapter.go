@@ -0,0 +1,32 @@
+package blob
+
+import (
+	"backend/internal/domain"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"path"
+	"strings"
+)
+
+// NewAdapter returns the blob store of the configured driver.
+func NewAdapter(logger logrus.FieldLogger, config *Config) (domain.BlobStore, error) {
+	switch config.Driver {
+	case "local":
+		return newLocalStore(logger, config)
+	case "s3":
+		if config.S3Bucket == "" {
+			return nil, errors.New("s3 bucket is required")
+		}
+		return newS3Store(logger, config)
+	}
+
+	return nil, fmt.Errorf("unknown blob driver %q", config.Driver)
+}
+
+// validKey reports whether the key is a clean relative slash separated path,
+// so that it cannot escape the directory of the local store.
+func validKey(key string) bool {
+	return key != "" && key != "." && key != ".." && !strings.HasPrefix(key, "/") &&
+		!strings.HasPrefix(key, "../") && path.Clean(key) == key
+}
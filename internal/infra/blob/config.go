@@ -0,0 +1,19 @@
+package blob
+
+import "time"
+
+type Config struct {
+	Driver string `long:"driver" env:"DRIVER" description:"Where the uploaded files are kept: on the local filesystem or in an S3 compatible storage" choice:"local" choice:"s3" default:"local"`
+
+	Dir        string `long:"dir" env:"DIR" description:"Directory the files are kept in by the local driver" default:"blobs"`
+	BaseURL    string `long:"base-url" env:"BASE_URL" description:"Public URL the files of the local driver are served at" default:"http://localhost:8080/api/v1/blobs"`
+	SigningKey string `long:"signing-key" env:"SIGNING_KEY" description:"Secret the URLs of the local driver are signed with, a random one is used if empty"`
+
+	S3Endpoint        string        `long:"s3-endpoint" env:"S3_ENDPOINT" description:"S3 endpoint URL" default:"https://s3.amazonaws.com"`
+	S3Region          string        `long:"s3-region" env:"S3_REGION" description:"S3 region" default:"us-east-1"`
+	S3Bucket          string        `long:"s3-bucket" env:"S3_BUCKET" description:"S3 bucket"`
+	S3AccessKeyID     string        `long:"s3-access-key-id" env:"S3_ACCESS_KEY_ID" description:"S3 access key id"`
+	S3SecretAccessKey string        `long:"s3-secret-access-key" env:"S3_SECRET_ACCESS_KEY" description:"S3 secret access key"`
+	S3PathStyle       bool          `long:"s3-path-style" env:"S3_PATH_STYLE" description:"Address the bucket in the path instead of the host name, as the local S3 stand-ins require"`
+	S3Timeout         time.Duration `long:"s3-timeout" env:"S3_TIMEOUT" description:"Timeout of the requests to S3" default:"30s"`
+}
@@ -0,0 +1,172 @@
+package blob
+
+import (
+	"backend/internal/domain"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// localStore keeps the files in a directory, its signed URLs point to the service itself.
+// The content type is derived from the extension of the key.
+type localStore struct {
+	logger     logrus.FieldLogger
+	dir        string
+	baseURL    string
+	signingKey []byte
+}
+
+func newLocalStore(logger logrus.FieldLogger, config *Config) (*localStore, error) {
+	if err := os.MkdirAll(config.Dir, 0755); err != nil {
+		return nil, err
+	}
+
+	signingKey := []byte(config.SigningKey)
+	if len(signingKey) == 0 {
+		logger.Warn("The blob signing key is not set, the signed URLs stop working after restart!")
+
+		signingKey = make([]byte, 32)
+		if _, err := rand.Read(signingKey); err != nil {
+			return nil, err
+		}
+	}
+
+	return &localStore{
+		logger:     logger,
+		dir:        config.Dir,
+		baseURL:    strings.TrimSuffix(config.BaseURL, "/"),
+		signingKey: signingKey,
+	}, nil
+}
+
+func (s *localStore) Put(ctx context.Context, key, contentType string, data []byte) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		s.logger.WithError(err).Error("Error while creating blob directory!")
+		return domain.ErrInternalStorage
+	}
+
+	// the file appears at once, the readers never see a partially written one
+	tmp, err := ioutil.TempFile(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		s.logger.WithError(err).Error("Error while creating blob file!")
+		return domain.ErrInternalStorage
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		s.logger.WithError(err).Error("Error while writing blob file!")
+		return domain.ErrInternalStorage
+	}
+
+	if err := tmp.Close(); err != nil {
+		s.logger.WithError(err).Error("Error while writing blob file!")
+		return domain.ErrInternalStorage
+	}
+
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		s.logger.WithError(err).Error("Error while moving blob file!")
+		return domain.ErrInternalStorage
+	}
+
+	return nil
+}
+
+func (s *localStore) Delete(ctx context.Context, key string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+		s.logger.WithError(err).Error("Error while deleting blob file!")
+		return domain.ErrInternalStorage
+	}
+
+	return nil
+}
+
+func (s *localStore) SignedURL(key string, ttl time.Duration) (string, error) {
+	if !validKey(key) {
+		return "", domain.ErrInvalidInputData
+	}
+
+	expires := time.Now().Add(ttl).Unix()
+
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", s.sign(key, expires))
+
+	return s.baseURL + "/" + (&url.URL{Path: key}).EscapedPath() + "?" + query.Encode(), nil
+}
+
+func (s *localStore) OpenSigned(ctx context.Context, key string, expires int64, signature string) (*domain.Blob, error) {
+	if !validKey(key) || time.Now().Unix() > expires || !hmac.Equal([]byte(signature), []byte(s.sign(key, expires))) {
+		return nil, domain.ErrInvalidToken
+	}
+
+	name, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, domain.ErrInvalidToken
+		}
+		s.logger.WithError(err).Error("Error while opening blob file!")
+		return nil, domain.ErrInternalStorage
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		s.logger.WithError(err).Error("Error while opening blob file!")
+		return nil, domain.ErrInternalStorage
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	return &domain.Blob{
+		ContentType: contentType,
+		Size:        info.Size(),
+		Body:        f,
+	}, nil
+}
+
+// sign returns the signature of the URL of the key valid until expires.
+func (s *localStore) sign(key string, expires int64) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(key + "\n" + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *localStore) path(key string) (string, error) {
+	if !validKey(key) {
+		return "", domain.ErrInvalidInputData
+	}
+
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
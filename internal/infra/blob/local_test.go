@@ -0,0 +1,165 @@
+package blob
+
+import (
+	"backend/internal/domain"
+	"context"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{key: "products/1/photo.jpg", want: true},
+		{key: "photo.jpg", want: true},
+		{key: "products/1/..photo.jpg", want: true},
+		{key: "", want: false},
+		{key: ".", want: false},
+		{key: "..", want: false},
+		{key: "../photo.jpg", want: false},
+		{key: "../../etc/passwd", want: false},
+		{key: "products/../../photo.jpg", want: false},
+		{key: "products/1/../photo.jpg", want: false},
+		{key: "products/./photo.jpg", want: false},
+		{key: "/etc/passwd", want: false},
+		{key: "products//photo.jpg", want: false},
+		{key: "products/1/", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := validKey(tt.key); got != tt.want {
+			t.Errorf("validKey(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestLocalStorePathTraversal(t *testing.T) {
+	root, err := ioutil.TempDir("", "blobs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	s := newTestLocalStore(t, filepath.Join(root, "store"))
+
+	for _, key := range []string{"../outside.txt", "a/../../outside.txt", "/outside.txt"} {
+		if err := s.Put(context.Background(), key, "text/plain", []byte("data")); err != domain.ErrInvalidInputData {
+			t.Errorf("Put(%q) = %v, want %v", key, err, domain.ErrInvalidInputData)
+		}
+		if err := s.Delete(context.Background(), key); err != domain.ErrInvalidInputData {
+			t.Errorf("Delete(%q) = %v, want %v", key, err, domain.ErrInvalidInputData)
+		}
+		if _, err := s.SignedURL(key, time.Minute); err != domain.ErrInvalidInputData {
+			t.Errorf("SignedURL(%q) = %v, want %v", key, err, domain.ErrInvalidInputData)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(root, "outside.txt")); !os.IsNotExist(err) {
+		t.Errorf("file written outside the store: %v", err)
+	}
+}
+
+func TestLocalStoreOpenSigned(t *testing.T) {
+	dir, err := ioutil.TempDir("", "blobs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := newTestLocalStore(t, dir)
+
+	const key = "products/1/photo.jpg"
+	if err := s.Put(context.Background(), key, "image/jpeg", []byte("photo")); err != nil {
+		t.Fatal(err)
+	}
+
+	// the signed URL is taken apart the way the blob handler does it
+	signedURL, err := s.SignedURL(key, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(signedURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimPrefix(u.Path, "/api/v1/blobs/"); got != key {
+		t.Fatalf("signed URL path = %q, want key %q", got, key)
+	}
+	expires, err := strconv.ParseInt(u.Query().Get("expires"), 10, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature := u.Query().Get("signature")
+
+	blob, err := s.OpenSigned(context.Background(), key, expires, signature)
+	if err != nil {
+		t.Fatalf("OpenSigned() error = %v", err)
+	}
+	data, err := ioutil.ReadAll(blob.Body)
+	blob.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "photo" || blob.ContentType != "image/jpeg" || blob.Size != 5 {
+		t.Errorf("OpenSigned() = %q %q %d, want %q %q %d", data, blob.ContentType, blob.Size, "photo", "image/jpeg", 5)
+	}
+
+	past := time.Now().Add(-time.Second).Unix()
+
+	tests := []struct {
+		name      string
+		key       string
+		expires   int64
+		signature string
+	}{
+		{name: "expired", key: key, expires: past, signature: s.sign(key, past)},
+		{name: "extended expiry", key: key, expires: expires + 3600, signature: signature},
+		{name: "tampered signature", key: key, expires: expires, signature: strings.Repeat("0", len(signature))},
+		{name: "empty signature", key: key, expires: expires, signature: ""},
+		{name: "other key", key: "products/1/other.jpg", expires: expires, signature: signature},
+		{name: "traversal", key: "../" + key, expires: expires, signature: s.sign("../"+key, expires)},
+		{name: "missing file", key: "products/1/missing.jpg", expires: expires, signature: s.sign("products/1/missing.jpg", expires)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blob, err := s.OpenSigned(context.Background(), tt.key, tt.expires, tt.signature)
+			if err != domain.ErrInvalidToken {
+				if blob != nil {
+					blob.Body.Close()
+				}
+				t.Errorf("OpenSigned() error = %v, want %v", err, domain.ErrInvalidToken)
+			}
+		})
+	}
+
+	// a store with another signing key does not accept the signature
+	other := newTestLocalStore(t, dir)
+	if _, err := other.OpenSigned(context.Background(), key, expires, signature); err != domain.ErrInvalidToken {
+		t.Errorf("OpenSigned() with another signing key error = %v, want %v", err, domain.ErrInvalidToken)
+	}
+}
+
+func newTestLocalStore(t *testing.T, dir string) *localStore {
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+
+	s, err := newLocalStore(logger, &Config{
+		Dir:     dir,
+		BaseURL: "http://localhost:8080/api/v1/blobs/",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
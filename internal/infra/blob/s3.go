@@ -0,0 +1,119 @@
+package blob
+
+import (
+	"backend/internal/domain"
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// s3Store keeps the files in a bucket of an S3 compatible storage, its signed URLs point to the storage.
+type s3Store struct {
+	logger    logrus.FieldLogger
+	client    *http.Client
+	endpoint  *url.URL
+	bucket    string
+	pathStyle bool
+	creds     *credentials
+}
+
+func newS3Store(logger logrus.FieldLogger, config *Config) (*s3Store, error) {
+	endpoint, err := url.Parse(config.S3Endpoint)
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid s3 endpoint %q", config.S3Endpoint)
+	}
+
+	return &s3Store{
+		logger:    logger,
+		client:    &http.Client{Timeout: config.S3Timeout},
+		endpoint:  endpoint,
+		bucket:    config.S3Bucket,
+		pathStyle: config.S3PathStyle,
+		creds: &credentials{
+			accessKeyID:     config.S3AccessKeyID,
+			secretAccessKey: config.S3SecretAccessKey,
+			region:          config.S3Region,
+		},
+	}, nil
+}
+
+func (s *s3Store) Put(ctx context.Context, key, contentType string, data []byte) error {
+	if !validKey(key) {
+		return domain.ErrInvalidInputData
+	}
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key).String(), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Content-Type", contentType)
+
+	return s.do(r, hashHex(data))
+}
+
+func (s *s3Store) Delete(ctx context.Context, key string) error {
+	if !validKey(key) {
+		return domain.ErrInvalidInputData
+	}
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.objectURL(key).String(), nil)
+	if err != nil {
+		return err
+	}
+
+	return s.do(r, emptyPayloadHash)
+}
+
+func (s *s3Store) SignedURL(key string, ttl time.Duration) (string, error) {
+	if !validKey(key) {
+		return "", domain.ErrInvalidInputData
+	}
+
+	return s.creds.presign(http.MethodGet, s.objectURL(key), ttl, time.Now()), nil
+}
+
+// OpenSigned is not supported, the signed URLs are served by the storage.
+func (s *s3Store) OpenSigned(ctx context.Context, key string, expires int64, signature string) (*domain.Blob, error) {
+	return nil, domain.ErrInvalidToken
+}
+
+// objectURL addresses the object in the virtual-hosted style, or in the path style if configured.
+func (s *s3Store) objectURL(key string) *url.URL {
+	u := *s.endpoint
+	base := strings.TrimSuffix(u.Path, "/")
+	if s.pathStyle {
+		u.Path = base + "/" + s.bucket + "/" + key
+	} else {
+		u.Host = s.bucket + "." + u.Host
+		u.Path = base + "/" + key
+	}
+
+	return &u
+}
+
+func (s *s3Store) do(r *http.Request, payloadHash string) error {
+	s.creds.signRequest(r, payloadHash, time.Now())
+
+	res, err := s.client.Do(r)
+	if err != nil {
+		s.logger.WithError(err).Error("Error while requesting S3!")
+		return domain.ErrInternalStorage
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		body, _ := ioutil.ReadAll(io.LimitReader(res.Body, 4096))
+		s.logger.WithField("status", res.StatusCode).WithField("body", string(body)).
+			WithField("method", r.Method).Error("Unexpected S3 response!")
+		return domain.ErrInternalStorage
+	}
+
+	return nil
+}
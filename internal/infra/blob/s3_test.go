@@ -0,0 +1,285 @@
+package blob
+
+import (
+	"backend/internal/domain"
+	"context"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testBucket    = "photos"
+	testAccessKey = "AKIDTEST"
+	testSecretKey = "secret"
+	testRegion    = "eu-central-1"
+)
+
+// s3StandIn is a path-style S3 bucket that checks the signatures of the requests the way S3 does.
+type s3StandIn struct {
+	t     *testing.T
+	creds *credentials
+
+	mu      sync.Mutex
+	objects map[string]s3Object
+}
+
+type s3Object struct {
+	contentType string
+	data        []byte
+}
+
+func newS3StandIn(t *testing.T) (*s3StandIn, *httptest.Server) {
+	s := &s3StandIn{
+		t:       t,
+		creds:   &credentials{accessKeyID: testAccessKey, secretAccessKey: testSecretKey, region: testRegion},
+		objects: make(map[string]s3Object),
+	}
+
+	return s, httptest.NewServer(s)
+}
+
+func (s *s3StandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(r.URL.Path, "/"+testBucket+"/")
+	if key == r.URL.Path {
+		http.Error(w, "NoSuchBucket", http.StatusNotFound)
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if r.URL.Query().Get("X-Amz-Signature") != "" {
+		if r.Method != http.MethodGet || !s.validPresigned(r) {
+			http.Error(w, "SignatureDoesNotMatch", http.StatusForbidden)
+			return
+		}
+	} else if !s.validAuthorization(r, body) {
+		http.Error(w, "SignatureDoesNotMatch", http.StatusForbidden)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		s.objects[key] = s3Object{contentType: r.Header.Get("Content-Type"), data: body}
+	case http.MethodDelete:
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodGet:
+		object, ok := s.objects[key]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", object.contentType)
+		_, _ = w.Write(object.data)
+	default:
+		http.Error(w, "MethodNotAllowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// validAuthorization checks the authorization header against the canonical request rebuilt from the received one.
+func (s *s3StandIn) validAuthorization(r *http.Request, body []byte) bool {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, sigV4Algorithm+" ") {
+		s.t.Errorf("unexpected authorization %q", auth)
+		return false
+	}
+
+	params := map[string]string{}
+	for _, part := range strings.Split(strings.TrimPrefix(auth, sigV4Algorithm+" "), ", ") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return false
+		}
+		params[kv[0]] = kv[1]
+	}
+
+	now, err := time.Parse(sigV4DateLayout, r.Header.Get("X-Amz-Date"))
+	if err != nil {
+		s.t.Errorf("invalid X-Amz-Date: %v", err)
+		return false
+	}
+
+	scope := s.creds.scope(now)
+	if params["Credential"] != s.creds.accessKeyID+"/"+scope {
+		return false
+	}
+
+	payloadHash := r.Header.Get("X-Amz-Content-Sha256")
+	if payloadHash != hashHex(body) {
+		s.t.Errorf("X-Amz-Content-Sha256 = %q, want the hash of the body %q", payloadHash, hashHex(body))
+		return false
+	}
+
+	signedHeaders := strings.Split(params["SignedHeaders"], ";")
+	var headers strings.Builder
+	for _, name := range signedHeaders {
+		value := r.Header.Get(name)
+		if name == "host" {
+			value = r.Host
+		}
+		headers.WriteString(name + ":" + value + "\n")
+	}
+
+	canonicalRequest := strings.Join([]string{
+		r.Method,
+		canonicalURI(r.URL),
+		canonicalQuery(r.URL.Query()),
+		headers.String(),
+		params["SignedHeaders"],
+		payloadHash,
+	}, "\n")
+
+	return params["Signature"] == s.creds.signature(now, scope, canonicalRequest)
+}
+
+// validPresigned checks the signature and the expiry of a presigned URL.
+func (s *s3StandIn) validPresigned(r *http.Request) bool {
+	query := r.URL.Query()
+
+	now, err := time.Parse(sigV4DateLayout, query.Get("X-Amz-Date"))
+	if err != nil {
+		return false
+	}
+
+	expires, err := time.ParseDuration(query.Get("X-Amz-Expires") + "s")
+	if err != nil || time.Now().After(now.Add(expires)) {
+		return false
+	}
+
+	scope := s.creds.scope(now)
+	if query.Get("X-Amz-Algorithm") != sigV4Algorithm || query.Get("X-Amz-Credential") != s.creds.accessKeyID+"/"+scope ||
+		query.Get("X-Amz-SignedHeaders") != "host" {
+		return false
+	}
+
+	signature := query.Get("X-Amz-Signature")
+	query.Del("X-Amz-Signature")
+
+	canonicalRequest := strings.Join([]string{
+		r.Method,
+		canonicalURI(r.URL),
+		canonicalQuery(query),
+		"host:" + r.Host + "\n",
+		"host",
+		unsignedPayload,
+	}, "\n")
+
+	return signature == s.creds.signature(now, scope, canonicalRequest)
+}
+
+func (s *s3StandIn) object(key string) (s3Object, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	object, ok := s.objects[key]
+	return object, ok
+}
+
+func TestS3Store(t *testing.T) {
+	standIn, server := newS3StandIn(t)
+	defer server.Close()
+
+	store := newTestS3Store(t, server.URL, testSecretKey)
+
+	keys := []string{"products/1/photo.jpg", "products/1/a photo+1.jpg", "products/1/фото.jpg"}
+	for _, key := range keys {
+		if err := store.Put(context.Background(), key, "image/jpeg", []byte("photo of "+key)); err != nil {
+			t.Fatalf("Put(%q) error = %v", key, err)
+		}
+
+		object, ok := standIn.object(key)
+		if !ok || string(object.data) != "photo of "+key || object.contentType != "image/jpeg" {
+			t.Fatalf("object %q = %+v, %v after Put", key, object, ok)
+		}
+
+		signedURL, err := store.SignedURL(key, time.Minute)
+		if err != nil {
+			t.Fatalf("SignedURL(%q) error = %v", key, err)
+		}
+
+		res, err := http.Get(signedURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		if res.StatusCode != http.StatusOK || string(data) != "photo of "+key {
+			t.Fatalf("GET signed URL of %q = %d %q", key, res.StatusCode, data)
+		}
+
+		// a tampered presigned URL is rejected
+		res, err = http.Get(strings.Replace(signedURL, "X-Amz-Expires=60", "X-Amz-Expires=600", 1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		if res.StatusCode != http.StatusForbidden {
+			t.Errorf("GET tampered signed URL of %q = %d, want %d", key, res.StatusCode, http.StatusForbidden)
+		}
+
+		if err := store.Delete(context.Background(), key); err != nil {
+			t.Fatalf("Delete(%q) error = %v", key, err)
+		}
+		if _, ok := standIn.object(key); ok {
+			t.Fatalf("object %q exists after Delete", key)
+		}
+	}
+}
+
+func TestS3StoreWrongSecret(t *testing.T) {
+	standIn, server := newS3StandIn(t)
+	defer server.Close()
+
+	store := newTestS3Store(t, server.URL, "wrong")
+
+	if err := store.Put(context.Background(), "products/1/photo.jpg", "image/jpeg", []byte("photo")); err != domain.ErrInternalStorage {
+		t.Errorf("Put() error = %v, want %v", err, domain.ErrInternalStorage)
+	}
+	if _, ok := standIn.object("products/1/photo.jpg"); ok {
+		t.Error("object stored with a wrong signature")
+	}
+}
+
+func TestS3StoreInvalidKey(t *testing.T) {
+	store := newTestS3Store(t, "http://127.0.0.1:1", testSecretKey)
+
+	if err := store.Put(context.Background(), "../photo.jpg", "image/jpeg", []byte("photo")); err != domain.ErrInvalidInputData {
+		t.Errorf("Put() error = %v, want %v", err, domain.ErrInvalidInputData)
+	}
+	if _, err := store.SignedURL("/photo.jpg", time.Minute); err != domain.ErrInvalidInputData {
+		t.Errorf("SignedURL() error = %v, want %v", err, domain.ErrInvalidInputData)
+	}
+}
+
+func newTestS3Store(t *testing.T, endpoint, secret string) *s3Store {
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+
+	s, err := newS3Store(logger, &Config{
+		S3Endpoint:        endpoint,
+		S3Region:          testRegion,
+		S3Bucket:          testBucket,
+		S3AccessKeyID:     testAccessKey,
+		S3SecretAccessKey: secret,
+		S3PathStyle:       true,
+		S3Timeout:         5 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
@@ -0,0 +1,201 @@
+package blob
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AWS Signature Version 4, see https://docs.aws.amazon.com/general/latest/gr/sigv4_signing.html.
+const (
+	sigV4Algorithm   = "AWS4-HMAC-SHA256"
+	sigV4DateLayout  = "20060102T150405Z"
+	sigV4Service     = "s3"
+	unsignedPayload  = "UNSIGNED-PAYLOAD"
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+type credentials struct {
+	accessKeyID     string
+	secretAccessKey string
+	region          string
+}
+
+// signRequest adds the authorization header to the request, the payload is hashed by the caller.
+func (c *credentials) signRequest(r *http.Request, payloadHash string, now time.Time) {
+	now = now.UTC()
+	r.Header.Set("X-Amz-Date", now.Format(sigV4DateLayout))
+	r.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers, signedHeaders := canonicalHeaders(r)
+	canonicalRequest := strings.Join([]string{
+		r.Method,
+		canonicalURI(r.URL),
+		canonicalQuery(r.URL.Query()),
+		headers,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := c.scope(now)
+	signature := c.signature(now, scope, canonicalRequest)
+
+	r.Header.Set("Authorization", sigV4Algorithm+" Credential="+c.accessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+// presign returns the URL the request can be made by without credentials until expires passes,
+// only the host header is signed.
+func (c *credentials) presign(method string, u *url.URL, expires time.Duration, now time.Time) string {
+	now = now.UTC()
+	scope := c.scope(now)
+
+	query := u.Query()
+	query.Set("X-Amz-Algorithm", sigV4Algorithm)
+	query.Set("X-Amz-Credential", c.accessKeyID+"/"+scope)
+	query.Set("X-Amz-Date", now.Format(sigV4DateLayout))
+	query.Set("X-Amz-Expires", formatSeconds(expires))
+	query.Set("X-Amz-SignedHeaders", "host")
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		canonicalURI(u),
+		canonicalQuery(query),
+		"host:" + u.Host + "\n",
+		"host",
+		unsignedPayload,
+	}, "\n")
+
+	query.Set("X-Amz-Signature", c.signature(now, scope, canonicalRequest))
+
+	signed := *u
+	signed.RawQuery = canonicalQuery(query)
+	return signed.String()
+}
+
+func (c *credentials) scope(now time.Time) string {
+	return now.Format("20060102") + "/" + c.region + "/" + sigV4Service + "/aws4_request"
+}
+
+func (c *credentials) signature(now time.Time, scope, canonicalRequest string) string {
+	stringToSign := strings.Join([]string{
+		sigV4Algorithm,
+		now.Format(sigV4DateLayout),
+		scope,
+		hashHex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+c.secretAccessKey), now.Format("20060102"))
+	key = hmacSHA256(key, c.region)
+	key = hmacSHA256(key, sigV4Service)
+	key = hmacSHA256(key, "aws4_request")
+
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+// canonicalHeaders returns the canonical headers and the list of the signed ones,
+// the host and all x-amz-* and content-* headers are signed.
+func canonicalHeaders(r *http.Request) (string, string) {
+	values := map[string]string{"host": r.URL.Host}
+	if r.Host != "" {
+		values["host"] = r.Host
+	}
+
+	for name, vv := range r.Header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-amz-") || strings.HasPrefix(lower, "content-") || lower == "range" {
+			trimmed := make([]string, 0, len(vv))
+			for _, v := range vv {
+				trimmed = append(trimmed, strings.Join(strings.Fields(v), " "))
+			}
+			values[lower] = strings.Join(trimmed, ",")
+		}
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(name + ":" + values[name] + "\n")
+	}
+
+	return b.String(), strings.Join(names, ";")
+}
+
+// canonicalURI returns the path with every segment escaped once.
+func canonicalURI(u *url.URL) string {
+	p := u.Path
+	if p == "" {
+		return "/"
+	}
+
+	segments := strings.Split(p, "/")
+	for i, v := range segments {
+		segments[i] = uriEncode(v)
+	}
+
+	return strings.Join(segments, "/")
+}
+
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		vv := append([]string(nil), query[k]...)
+		sort.Strings(vv)
+		for _, v := range vv {
+			pairs = append(pairs, uriEncode(k)+"="+uriEncode(v))
+		}
+	}
+
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode escapes everything except the unreserved characters of RFC 3986.
+func uriEncode(s string) string {
+	const hexDigits = "0123456789ABCDEF"
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hexDigits[c>>4])
+		b.WriteByte(hexDigits[c&15])
+	}
+
+	return b.String()
+}
+
+func formatSeconds(d time.Duration) string {
+	return strconv.Itoa(int(d / time.Second))
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -2,14 +2,13 @@ package http
 
 import (
 	"backend/internal/domain"
+	"backend/internal/i18n"
 	"context"
 	"errors"
-	"github.com/dgrijalva/jwt-go"
-	"github.com/go-chi/jwtauth"
+	"fmt"
 	"github.com/sirupsen/logrus"
-	"io/ioutil"
+	"net"
 	"net/http"
-	"os"
 )
 
 type adapter struct {
@@ -20,8 +19,8 @@ type adapter struct {
 
 	server *http.Server
 
-	//jwt
-	jwtAuth *jwtauth.JWTAuth
+	defaultLanguage i18n.Language
+	trustedProxies  []*net.IPNet
 }
 
 // Creating a new HTTP adapter.
@@ -32,24 +31,23 @@ func NewAdapter(logger *logrus.Logger, config *Config, service domain.Service) (
 		service: service,
 	}
 
-	// Read JWT signing key
-	fileWithAccessToken, err := os.Open(a.config.JWTPrivateKey)
-	if err != nil {
-		a.logger.WithError(err).Error("Error while opening file!")
+	defaultLanguage, ok := i18n.ParseLanguage(config.DefaultLanguage)
+	if !ok {
+		err := fmt.Errorf("unsupported default language %q", config.DefaultLanguage)
+		a.logger.WithError(err).Error("Error while parsing default language!")
 		return nil, err
 	}
-
-	defer fileWithAccessToken.Close()
-
-	bytes, err := ioutil.ReadAll(fileWithAccessToken)
-	if err != nil {
-		a.logger.WithError(err).Error("Error while reading file!")
-		return nil, err
+	a.defaultLanguage = defaultLanguage
+
+	for _, v := range config.TrustedProxies {
+		_, network, err := net.ParseCIDR(v)
+		if err != nil {
+			a.logger.WithError(err).Error("Error while parsing trusted proxy network!")
+			return nil, err
+		}
+		a.trustedProxies = append(a.trustedProxies, network)
 	}
 
-	jwtAuth := jwtauth.New(jwt.SigningMethodHS256.Name, bytes, nil)
-	a.jwtAuth = jwtAuth
-
 	r, err := a.newRouter()
 	if err != nil {
 		logger.WithError(err).Error("Error while creating new router!")
@@ -0,0 +1,128 @@
+package http
+
+import (
+	"backend/internal/domain"
+	"backend/internal/infra/http/viewmodels"
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+const adminCountOnPage int = 50
+
+func (a *adapter) getUsers(w http.ResponseWriter, r *http.Request) error {
+	page, err := a.pageQueryParam(r)
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	users, count, err := a.service.GetUsers(r.Context(), page, adminCountOnPage)
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	var res viewmodels.AdminUsersWithCount
+	res.ViewModel(users, count)
+	return j(w, http.StatusOK, res)
+}
+
+func (a *adapter) blockUser(w http.ResponseWriter, r *http.Request) error {
+	return a.moderate(w, r, "user_id", a.service.BlockUser)
+}
+
+func (a *adapter) unblockUser(w http.ResponseWriter, r *http.Request) error {
+	userID, err := a.intURLParam(r, "user_id")
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	if err := a.service.UnblockUser(r.Context(), userID); err != nil {
+		return a.jError(w, r, err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (a *adapter) setUserRole(w http.ResponseWriter, r *http.Request) error {
+	userID, err := a.intURLParam(r, "user_id")
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	var req viewmodels.RoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.logger.WithError(err).Error("Error while decoding request body!")
+		return a.jError(w, r, domain.ErrInvalidInputData)
+	}
+
+	if err := req.Validate(); err != nil {
+		return a.jError(w, r, err)
+	}
+
+	if err := a.service.SetUserRole(r.Context(), userID, domain.Role(req.Role)); err != nil {
+		return a.jError(w, r, err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (a *adapter) hideProduct(w http.ResponseWriter, r *http.Request) error {
+	return a.moderate(w, r, "product_id", a.service.HideProduct)
+}
+
+func (a *adapter) unhideProduct(w http.ResponseWriter, r *http.Request) error {
+	productID, err := a.intURLParam(r, "product_id")
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	if err := a.service.UnhideProduct(r.Context(), productID); err != nil {
+		return a.jError(w, r, err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (a *adapter) getAllOrders(w http.ResponseWriter, r *http.Request) error {
+	page, err := a.pageQueryParam(r)
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	orders, count, err := a.service.GetAllOrders(r.Context(), page, adminCountOnPage)
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	var res viewmodels.OrdersWithCount
+	res.ViewModel(orders, count)
+	return j(w, http.StatusOK, res)
+}
+
+// moderate applies the moderation action with the reason from the request body to the entity of the URL param.
+func (a *adapter) moderate(w http.ResponseWriter, r *http.Request, param string, action func(ctx context.Context, id int, reason string) error) error {
+	id, err := a.intURLParam(r, param)
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	var req viewmodels.ModerationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.logger.WithError(err).Error("Error while decoding request body!")
+		return a.jError(w, r, domain.ErrInvalidInputData)
+	}
+
+	if err := req.Validate(); err != nil {
+		return a.jError(w, r, err)
+	}
+
+	if err := action(r.Context(), id, req.Reason); err != nil {
+		return a.jError(w, r, err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
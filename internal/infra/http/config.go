@@ -1,7 +1,8 @@
 package http
 
 type Config struct {
-	Address        string   `short:"a" long:"address" env:"ADDRESS" description:"Service address" required:"yes"`
-	JWTPrivateKey  string   `long:"jwt-private-key" env:"JWT_PRIVATE_KEY" description:"Path to JWT private key" required:"yes"`
-	AllowedOrigins []string `long:"allowed-origins" env:"ALLOWED_ORIGINS" description:"Allowed origins to use CORS" env-delim:"," required:"yes"`
+	Address         string   `short:"a" long:"address" env:"ADDRESS" description:"Service address" required:"yes"`
+	AllowedOrigins  []string `long:"allowed-origins" env:"ALLOWED_ORIGINS" description:"Allowed origins to use CORS" env-delim:"," required:"yes"`
+	DefaultLanguage string   `long:"default-language" env:"DEFAULT_LANGUAGE" description:"Language of messages when the client has no preference" default:"ru"`
+	TrustedProxies  []string `long:"trusted-proxies" env:"TRUSTED_PROXIES" description:"Networks of the proxies whose X-Forwarded-For and X-Real-IP headers are trusted, in CIDR notation" env-delim:","`
 }
@@ -0,0 +1,126 @@
+package http
+
+import (
+	"backend/internal/domain"
+	"backend/internal/i18n"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/middleware"
+	"math"
+	"net/http"
+	"strconv"
+)
+
+var (
+	errRouteNotFound    = domain.NewError("route_not_found", "route not found")
+	errMethodNotAllowed = domain.NewError("method_not_allowed", "method not allowed")
+)
+
+// errorStatuses maps domain error codes to HTTP statuses, unknown codes are internal errors.
+var errorStatuses = map[string]int{
+	domain.ErrInvalidInputData.Code:     http.StatusBadRequest,
+	domain.ErrInvalidToken.Code:         http.StatusBadRequest,
+	domain.ErrInvalidTwoFactorCode.Code: http.StatusBadRequest,
+	domain.ErrWrongPassword.Code:        http.StatusBadRequest,
+	domain.ErrInvalidPhotoOrder.Code:    http.StatusBadRequest,
+
+	domain.ErrNoSuchUser.Code:     http.StatusNotFound,
+	domain.ErrNoSuchProduct.Code:  http.StatusNotFound,
+	domain.ErrNoSuchOrder.Code:    http.StatusNotFound,
+	domain.ErrNoSuchBlackout.Code: http.StatusNotFound,
+	domain.ErrNoSuchSession.Code:  http.StatusNotFound,
+	domain.ErrNoSuchProvider.Code: http.StatusNotFound,
+	domain.ErrNoSuchIdentity.Code: http.StatusNotFound,
+	domain.ErrNoSuchPhoto.Code:    http.StatusNotFound,
+	errRouteNotFound.Code:         http.StatusNotFound,
+
+	errMethodNotAllowed.Code: http.StatusMethodNotAllowed,
+
+	domain.ErrProductUnavailable.Code:   http.StatusConflict,
+	domain.ErrIllegalOrderStatus.Code:   http.StatusConflict,
+	domain.ErrLoginTaken.Code:           http.StatusConflict,
+	domain.ErrEmailTaken.Code:           http.StatusConflict,
+	domain.ErrTwoFactorEnabled.Code:     http.StatusConflict,
+	domain.ErrTwoFactorNotEnabled.Code:  http.StatusConflict,
+	domain.ErrTwoFactorNotEnrolled.Code: http.StatusConflict,
+	domain.ErrIdentityTaken.Code:        http.StatusConflict,
+	domain.ErrIdentityNotLinked.Code:    http.StatusConflict,
+	domain.ErrLastLoginMethod.Code:      http.StatusConflict,
+	domain.ErrNotReviewable.Code:        http.StatusConflict,
+	domain.ErrAlreadyReviewed.Code:      http.StatusConflict,
+	domain.ErrActiveRentals.Code:        http.StatusConflict,
+	domain.ErrTooManyPhotos.Code:        http.StatusConflict,
+
+	domain.ErrUnauthorized.Code:       http.StatusUnauthorized,
+	domain.ErrInvalidCredentials.Code: http.StatusUnauthorized,
+
+	domain.ErrTooManyLoginAttempts.Code: http.StatusTooManyRequests,
+
+	domain.ErrIdentityProvider.Code: http.StatusBadGateway,
+
+	domain.ErrForbidden.Code:        http.StatusForbidden,
+	domain.ErrEmailNotVerified.Code: http.StatusForbidden,
+	domain.ErrUserBlocked.Code:      http.StatusForbidden,
+
+	domain.ErrPhotoTooLarge.Code: http.StatusRequestEntityTooLarge,
+
+	domain.ErrUnsupportedPhotoType.Code: http.StatusUnsupportedMediaType,
+}
+
+type fieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type errorResponse struct {
+	Code           string       `json:"code"`
+	Error          string       `json:"error"`
+	LocalizedError string       `json:"localized_error"`
+	RequestID      string       `json:"request_id,omitempty"`
+	Fields         []fieldError `json:"fields,omitempty"`
+}
+
+func (a *adapter) jError(w http.ResponseWriter, r *http.Request, err error) error {
+	derr := domain.AsError(err)
+
+	code, ok := errorStatuses[derr.Code]
+	if !ok {
+		// internal details are not exposed to the client
+		code = http.StatusInternalServerError
+		derr = domain.ErrInternal
+	}
+
+	res := errorResponse{
+		Code:           derr.Code,
+		Error:          derr.Message,
+		LocalizedError: i18n.Localize(a.language(r), derr.Code),
+		RequestID:      middleware.GetReqID(r.Context()),
+	}
+
+	var verr *domain.ValidationError
+	if errors.As(err, &verr) {
+		for _, v := range verr.Fields {
+			res.Fields = append(res.Fields, fieldError{
+				Field:   v.Field,
+				Code:    v.Code,
+				Message: v.Message,
+			})
+		}
+	}
+
+	var terr *domain.ThrottleError
+	if errors.As(err, &terr) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(terr.RetryAfter.Seconds()))))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Status", strconv.Itoa(code))
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		return fmt.Errorf("cannot write response: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package http
+
+import (
+	"archive/zip"
+	"backend/internal/domain"
+	"backend/internal/infra/http/viewmodels"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+)
+
+const exportFileName = "sharito-export.zip"
+
+// exportUser returns the personal data of the user as a ZIP archive of JSON files,
+// or as a single JSON document with ?format=json.
+func (a *adapter) exportUser(w http.ResponseWriter, r *http.Request) error {
+	export, err := a.service.ExportUserData(r.Context())
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	var res viewmodels.UserExport
+	res.ViewModel(export, r.Context().Value(domain.ContextSessionID).(int))
+
+	if r.URL.Query().Get("format") == "json" {
+		return j(w, http.StatusOK, res)
+	}
+
+	// the archive is built in memory, so that a failure is still reported as an error response
+	archive, err := zipJSONFiles(res.Files())
+	if err != nil {
+		a.logger.WithError(err).Error("Error while building export archive!")
+		return a.jError(w, r, domain.ErrInternal)
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+exportFileName+`"`)
+	w.Header().Set("Content-Length", strconv.Itoa(archive.Len()))
+	w.WriteHeader(http.StatusOK)
+	_, err = archive.WriteTo(w)
+	return err
+}
+
+func zipJSONFiles(files map[string]interface{}) (*bytes.Buffer, error) {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range names {
+		fw, err := zw.Create(name)
+		if err != nil {
+			return nil, err
+		}
+
+		enc := json.NewEncoder(fw)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(files[name]); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return &buf, nil
+}
+
+func (a *adapter) deleteUser(w http.ResponseWriter, r *http.Request) error {
+	var req viewmodels.DeleteUserRequest
+	// the body is optional for the users without a password
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			a.logger.WithError(err).Error("Error while decoding request body!")
+			return a.jError(w, r, domain.ErrInvalidInputData)
+		}
+	}
+
+	if err := a.service.DeleteUser(r.Context(), req.Password); err != nil {
+		return a.jError(w, r, err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
@@ -3,9 +3,11 @@ package http
 import (
 	"backend/internal/domain"
 	"backend/internal/infra/http/viewmodels"
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/go-chi/chi"
+	"math"
 	"net/http"
 	"strconv"
 	"time"
@@ -13,6 +15,11 @@ import (
 
 const productCountOnPage int = 10
 
+const queryTimeLayout = "2006-01-02 15:04"
+
+// defaultAvailabilityWindow is used when the availability period end is not requested.
+const defaultAvailabilityWindow = 30 * 24 * time.Hour
+
 func (a *adapter) wrap(handler func(w http.ResponseWriter, r *http.Request) error) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if err := handler(w, r); err != nil {
@@ -30,7 +37,7 @@ func (a *adapter) sayHello(w http.ResponseWriter, r *http.Request) error {
 	}
 
 	if _, err := w.Write([]byte("Hello!")); err != nil {
-		return jError(w, err)
+		return a.jError(w, r, err)
 	}
 
 	w.WriteHeader(http.StatusOK)
@@ -41,58 +48,387 @@ func (a *adapter) register(w http.ResponseWriter, r *http.Request) error {
 	var req viewmodels.User
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		a.logger.WithError(err).Error("Error while decoding request body!")
-		return jError(w, domain.ErrInvalidInputData)
+		return a.jError(w, r, domain.ErrInvalidInputData)
 	}
 
-	// check password existence
-	if req.Password == nil {
-		a.logger.WithError(domain.ErrInvalidInputData).Error("There is no password!")
-		return jError(w, domain.ErrInvalidInputData)
+	if err := req.ValidateRegistration(); err != nil {
+		return a.jError(w, r, err)
 	}
 
 	user := req.Domain()
 
-	token, err := a.service.Register(user)
+	// the verification email is written in the language of the request unless the user has chosen one
+	ctx := context.WithValue(r.Context(), domain.ContextLanguage, a.language(r))
+	tokens, err := a.service.Register(ctx, user)
 	if err != nil {
-		return jError(w, err)
+		return a.jError(w, r, err)
 	}
 
-	w.Header().Set("X-Auth", token)
-	return j(w, http.StatusOK, struct {
-		Token string `json:"token"`
-	}{Token: token})
+	w.Header().Set("X-Auth", tokens.AccessToken)
+	var res viewmodels.Tokens
+	res.ViewModel(tokens)
+	return j(w, http.StatusOK, res)
 }
 
 func (a *adapter) login(w http.ResponseWriter, r *http.Request) error {
 	var req viewmodels.User
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		a.logger.WithError(err).Error("Error while decoding request body!")
-		return jError(w, domain.ErrInvalidInputData)
+		return a.jError(w, r, domain.ErrInvalidInputData)
 	}
 
-	// check password existence
-	if req.Password == nil {
-		a.logger.WithError(domain.ErrInvalidInputData).Error("There is no password!")
-		return jError(w, domain.ErrInvalidInputData)
+	if err := req.ValidateLogin(); err != nil {
+		return a.jError(w, r, err)
 	}
 
 	user := req.Domain()
 
-	token, err := a.service.Login(user)
+	tokens, challenge, err := a.service.Login(r.Context(), user)
 	if err != nil {
-		return jError(w, err)
+		return a.jError(w, r, err)
 	}
 
-	w.Header().Set("X-Auth", token)
-	return j(w, http.StatusOK, struct {
-		Token string `json:"token"`
-	}{Token: token})
+	if challenge != nil {
+		var res viewmodels.Challenge
+		res.ViewModel(challenge)
+		return j(w, http.StatusOK, res)
+	}
+
+	w.Header().Set("X-Auth", tokens.AccessToken)
+	var res viewmodels.Tokens
+	res.ViewModel(tokens)
+	return j(w, http.StatusOK, res)
+}
+
+func (a *adapter) loginTwoFactor(w http.ResponseWriter, r *http.Request) error {
+	var req viewmodels.TwoFactorLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.logger.WithError(err).Error("Error while decoding request body!")
+		return a.jError(w, r, domain.ErrInvalidInputData)
+	}
+
+	if err := req.Validate(); err != nil {
+		return a.jError(w, r, err)
+	}
+
+	tokens, err := a.service.LoginTwoFactor(r.Context(), req.ChallengeToken, req.Code)
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	w.Header().Set("X-Auth", tokens.AccessToken)
+	var res viewmodels.Tokens
+	res.ViewModel(tokens)
+	return j(w, http.StatusOK, res)
+}
+
+func (a *adapter) refresh(w http.ResponseWriter, r *http.Request) error {
+	var req viewmodels.RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.logger.WithError(err).Error("Error while decoding request body!")
+		return a.jError(w, r, domain.ErrInvalidInputData)
+	}
+
+	if err := req.Validate(); err != nil {
+		return a.jError(w, r, err)
+	}
+
+	tokens, err := a.service.Refresh(r.Context(), req.RefreshToken)
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	w.Header().Set("X-Auth", tokens.AccessToken)
+	var res viewmodels.Tokens
+	res.ViewModel(tokens)
+	return j(w, http.StatusOK, res)
+}
+
+func (a *adapter) forgotPassword(w http.ResponseWriter, r *http.Request) error {
+	var req viewmodels.ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.logger.WithError(err).Error("Error while decoding request body!")
+		return a.jError(w, r, domain.ErrInvalidInputData)
+	}
+
+	if err := req.Validate(); err != nil {
+		return a.jError(w, r, err)
+	}
+
+	// the email is written in the language of the request unless the user has chosen one
+	ctx := context.WithValue(r.Context(), domain.ContextLanguage, a.language(r))
+	if err := a.service.ForgotPassword(ctx, req.Email); err != nil {
+		return a.jError(w, r, err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (a *adapter) resetPassword(w http.ResponseWriter, r *http.Request) error {
+	var req viewmodels.ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.logger.WithError(err).Error("Error while decoding request body!")
+		return a.jError(w, r, domain.ErrInvalidInputData)
+	}
+
+	if err := req.Validate(); err != nil {
+		return a.jError(w, r, err)
+	}
+
+	if err := a.service.ResetPassword(r.Context(), req.Token, req.Password); err != nil {
+		return a.jError(w, r, err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (a *adapter) verifyEmail(w http.ResponseWriter, r *http.Request) error {
+	var req viewmodels.VerifyEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.logger.WithError(err).Error("Error while decoding request body!")
+		return a.jError(w, r, domain.ErrInvalidInputData)
+	}
+
+	if err := req.Validate(); err != nil {
+		return a.jError(w, r, err)
+	}
+
+	if err := a.service.VerifyEmail(r.Context(), req.Token); err != nil {
+		return a.jError(w, r, err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (a *adapter) resendVerificationEmail(w http.ResponseWriter, r *http.Request) error {
+	ctx := context.WithValue(r.Context(), domain.ContextLanguage, a.language(r))
+	if err := a.service.ResendVerificationEmail(ctx); err != nil {
+		return a.jError(w, r, err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (a *adapter) logout(w http.ResponseWriter, r *http.Request) error {
+	if err := a.service.Logout(r.Context()); err != nil {
+		return a.jError(w, r, err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (a *adapter) getSessions(w http.ResponseWriter, r *http.Request) error {
+	sessions, err := a.service.GetSessions(r.Context())
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	res := viewmodels.Sessions{}
+	res.ViewModel(sessions, r.Context().Value(domain.ContextSessionID).(int))
+	return j(w, http.StatusOK, res)
+}
+
+func (a *adapter) revokeSession(w http.ResponseWriter, r *http.Request) error {
+	sessionID, err := a.intURLParam(r, "session_id")
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	if err := a.service.RevokeSession(r.Context(), sessionID); err != nil {
+		return a.jError(w, r, err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (a *adapter) revokeOtherSessions(w http.ResponseWriter, r *http.Request) error {
+	if err := a.service.RevokeOtherSessions(r.Context()); err != nil {
+		return a.jError(w, r, err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// enrollTwoFactor returns the pending TOTP secret, two-factor authentication is enabled by confirmTwoFactor.
+func (a *adapter) enrollTwoFactor(w http.ResponseWriter, r *http.Request) error {
+	enrollment, err := a.service.EnrollTwoFactor(r.Context())
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	var res viewmodels.TwoFactorEnrollment
+	res.ViewModel(enrollment)
+	return j(w, http.StatusOK, res)
+}
+
+func (a *adapter) confirmTwoFactor(w http.ResponseWriter, r *http.Request) error {
+	return a.twoFactorCode(w, r, func(ctx context.Context, code string) error {
+		codes, err := a.service.ConfirmTwoFactor(ctx, code)
+		if err != nil {
+			return a.jError(w, r, err)
+		}
+
+		return j(w, http.StatusOK, viewmodels.RecoveryCodes{RecoveryCodes: codes})
+	})
+}
+
+func (a *adapter) disableTwoFactor(w http.ResponseWriter, r *http.Request) error {
+	return a.twoFactorCode(w, r, func(ctx context.Context, code string) error {
+		if err := a.service.DisableTwoFactor(ctx, code); err != nil {
+			return a.jError(w, r, err)
+		}
+
+		w.WriteHeader(http.StatusOK)
+		return nil
+	})
+}
+
+func (a *adapter) regenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) error {
+	return a.twoFactorCode(w, r, func(ctx context.Context, code string) error {
+		codes, err := a.service.RegenerateRecoveryCodes(ctx, code)
+		if err != nil {
+			return a.jError(w, r, err)
+		}
+
+		return j(w, http.StatusOK, viewmodels.RecoveryCodes{RecoveryCodes: codes})
+	})
+}
+
+// twoFactorCode decodes the two-factor authentication code of the request and passes it to handle.
+func (a *adapter) twoFactorCode(w http.ResponseWriter, r *http.Request, handle func(ctx context.Context, code string) error) error {
+	var req viewmodels.TwoFactorCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.logger.WithError(err).Error("Error while decoding request body!")
+		return a.jError(w, r, domain.ErrInvalidInputData)
+	}
+
+	if err := req.Validate(); err != nil {
+		return a.jError(w, r, err)
+	}
+
+	return handle(r.Context(), req.Code)
+}
+
+func (a *adapter) getOIDCProviders(w http.ResponseWriter, r *http.Request) error {
+	return j(w, http.StatusOK, viewmodels.OIDCProviders{Providers: a.service.GetOIDCProviders()})
+}
+
+func (a *adapter) startOIDCLogin(w http.ResponseWriter, r *http.Request) error {
+	redirect, err := a.service.StartOIDCLogin(r.Context(), chi.URLParam(r, "provider"))
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	var res viewmodels.AuthorizationURL
+	res.ViewModel(redirect)
+	return j(w, http.StatusOK, res)
+}
+
+func (a *adapter) finishOIDCLogin(w http.ResponseWriter, r *http.Request) error {
+	var req viewmodels.OIDCCallbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.logger.WithError(err).Error("Error while decoding request body!")
+		return a.jError(w, r, domain.ErrInvalidInputData)
+	}
+
+	if err := req.Validate(); err != nil {
+		return a.jError(w, r, err)
+	}
+
+	// a new user gets the language of the request
+	ctx := context.WithValue(r.Context(), domain.ContextLanguage, a.language(r))
+	tokens, challenge, err := a.service.FinishOIDCLogin(ctx, req.State, req.BrowserToken, req.Code)
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	if challenge != nil {
+		var res viewmodels.Challenge
+		res.ViewModel(challenge)
+		return j(w, http.StatusOK, res)
+	}
+
+	w.Header().Set("X-Auth", tokens.AccessToken)
+	var res viewmodels.Tokens
+	res.ViewModel(tokens)
+	return j(w, http.StatusOK, res)
+}
+
+func (a *adapter) getIdentities(w http.ResponseWriter, r *http.Request) error {
+	identities, err := a.service.GetIdentities(r.Context())
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	res := viewmodels.Identities{}
+	res.ViewModel(identities)
+	return j(w, http.StatusOK, res)
+}
+
+func (a *adapter) startIdentityLink(w http.ResponseWriter, r *http.Request) error {
+	redirect, err := a.service.StartIdentityLink(r.Context(), chi.URLParam(r, "provider"))
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	var res viewmodels.AuthorizationURL
+	res.ViewModel(redirect)
+	return j(w, http.StatusOK, res)
+}
+
+func (a *adapter) linkIdentity(w http.ResponseWriter, r *http.Request) error {
+	var req viewmodels.OIDCCallbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.logger.WithError(err).Error("Error while decoding request body!")
+		return a.jError(w, r, domain.ErrInvalidInputData)
+	}
+
+	if err := req.Validate(); err != nil {
+		return a.jError(w, r, err)
+	}
+
+	if err := a.service.LinkIdentity(r.Context(), req.State, req.BrowserToken, req.Code); err != nil {
+		return a.jError(w, r, err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (a *adapter) unlinkIdentity(w http.ResponseWriter, r *http.Request) error {
+	identityID, err := a.intURLParam(r, "identity_id")
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	if err := a.service.UnlinkIdentity(r.Context(), identityID); err != nil {
+		return a.jError(w, r, err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// getJWKS publishes the keys other services verify the access tokens with.
+func (a *adapter) getJWKS(w http.ResponseWriter, r *http.Request) error {
+	var res viewmodels.JWKS
+	res.ViewModel(a.service.GetPublicKeys())
+
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	return j(w, http.StatusOK, res)
 }
 
 func (a *adapter) getUser(w http.ResponseWriter, r *http.Request) error {
 	user, err := a.service.GetUser(r.Context())
 	if err != nil {
-		return jError(w, err)
+		return a.jError(w, r, err)
 	}
 
 	var res viewmodels.User
@@ -100,16 +436,83 @@ func (a *adapter) getUser(w http.ResponseWriter, r *http.Request) error {
 	return j(w, http.StatusOK, res)
 }
 
+func (a *adapter) patchUser(w http.ResponseWriter, r *http.Request) error {
+	current, err := a.service.GetUser(r.Context())
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	// decoding over the current state keeps the absent fields untouched
+	var req viewmodels.Profile
+	req.ViewModel(current)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.logger.WithError(err).Error("Error while decoding request body!")
+		return a.jError(w, r, domain.ErrInvalidInputData)
+	}
+
+	if err := req.Validate(); err != nil {
+		return a.jError(w, r, err)
+	}
+
+	// the verification email of a new address is written in the user language
+	ctx := context.WithValue(r.Context(), domain.ContextLanguage, a.language(r))
+	if err := a.service.UpdateProfile(ctx, req.Domain()); err != nil {
+		return a.jError(w, r, err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (a *adapter) changePassword(w http.ResponseWriter, r *http.Request) error {
+	var req viewmodels.ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.logger.WithError(err).Error("Error while decoding request body!")
+		return a.jError(w, r, domain.ErrInvalidInputData)
+	}
+
+	if err := req.Validate(); err != nil {
+		return a.jError(w, r, err)
+	}
+
+	if err := a.service.ChangePassword(r.Context(), req.CurrentPassword, req.NewPassword); err != nil {
+		return a.jError(w, r, err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (a *adapter) getPublicProfile(w http.ResponseWriter, r *http.Request) error {
+	userID, err := a.intURLParam(r, "user_id")
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	profile, err := a.service.GetPublicProfile(userID)
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	var res viewmodels.PublicProfile
+	res.ViewModel(profile)
+	return j(w, http.StatusOK, res)
+}
+
 func (a *adapter) addProduct(w http.ResponseWriter, r *http.Request) error {
 	var req viewmodels.Product
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		a.logger.WithError(err).Error("Error while decoding request body!")
-		return jError(w, domain.ErrInvalidInputData)
+		return a.jError(w, r, domain.ErrInvalidInputData)
+	}
+
+	if err := req.Validate(); err != nil {
+		return a.jError(w, r, err)
 	}
 
 	productID, err := a.service.AddProduct(r.Context(), req.Domain())
 	if err != nil {
-		return jError(w, err)
+		return a.jError(w, r, err)
 	}
 
 	return j(w, http.StatusOK, struct {
@@ -117,17 +520,91 @@ func (a *adapter) addProduct(w http.ResponseWriter, r *http.Request) error {
 	}{ProductID: productID})
 }
 
+func (a *adapter) updateProduct(w http.ResponseWriter, r *http.Request) error {
+	productID, err := a.intURLParam(r, "product_id")
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	var req viewmodels.Product
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.logger.WithError(err).Error("Error while decoding request body!")
+		return a.jError(w, r, domain.ErrInvalidInputData)
+	}
+
+	if err := req.Validate(); err != nil {
+		return a.jError(w, r, err)
+	}
+
+	product := req.Domain()
+	product.ID = productID
+
+	if err := a.service.UpdateProduct(r.Context(), product); err != nil {
+		return a.jError(w, r, err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// patchProduct updates only the fields present in the request body.
+func (a *adapter) patchProduct(w http.ResponseWriter, r *http.Request) error {
+	productID, err := a.intURLParam(r, "product_id")
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	current, _, err := a.service.GetProductAndOwnerUserByProductID(r.Context(), productID)
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	// decoding over the current state keeps the absent fields untouched
+	var req viewmodels.Product
+	req.ViewModel(current)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.logger.WithError(err).Error("Error while decoding request body!")
+		return a.jError(w, r, domain.ErrInvalidInputData)
+	}
+
+	if err := req.Validate(); err != nil {
+		return a.jError(w, r, err)
+	}
+
+	product := req.Domain()
+	product.ID = productID
+
+	if err := a.service.UpdateProduct(r.Context(), product); err != nil {
+		return a.jError(w, r, err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (a *adapter) archiveProduct(w http.ResponseWriter, r *http.Request) error {
+	productID, err := a.intURLParam(r, "product_id")
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	if err := a.service.ArchiveProduct(r.Context(), productID); err != nil {
+		return a.jError(w, r, err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
 func (a *adapter) getProduct(w http.ResponseWriter, r *http.Request) error {
-	productIDStr := chi.URLParam(r, "product_id")
-	productID, err := strconv.Atoi(productIDStr)
+	productID, err := a.intURLParam(r, "product_id")
 	if err != nil {
-		a.logger.WithError(err).Error("product_id is not int")
-		return jError(w, domain.ErrInvalidInputData)
+		return a.jError(w, r, err)
 	}
 
-	product, user, err := a.service.GetProductAndOwnerUserByProductID(productID)
+	product, user, err := a.service.GetProductAndOwnerUserByProductID(r.Context(), productID)
 	if err != nil {
-		return jError(w, err)
+		return a.jError(w, r, err)
 	}
 
 	var res viewmodels.ProductWithUser
@@ -136,25 +613,19 @@ func (a *adapter) getProduct(w http.ResponseWriter, r *http.Request) error {
 }
 
 func (a *adapter) getProducts(w http.ResponseWriter, r *http.Request) error {
-	var page int
-	if pageStr := r.URL.Query().Get("page"); pageStr != "" {
-		if p, err := strconv.Atoi(pageStr); err != nil {
-			a.logger.WithError(domain.ErrInvalidInputData).Error("cannot parse 'page' query param")
-			return jError(w, domain.ErrInvalidInputData)
-		} else {
-			page = p - 1
-		}
+	page, err := a.pageQueryParam(r)
+	if err != nil {
+		return a.jError(w, r, err)
 	}
 
-	if page < 0 {
-		return jError(w, domain.ErrInvalidInputData)
+	query, err := a.productQuery(r)
+	if err != nil {
+		return a.jError(w, r, err)
 	}
 
-	search := r.URL.Query().Get("search")
-
-	products, count, err := a.service.GetProductsWithPagination(page, productCountOnPage, search)
+	products, count, err := a.service.GetProductsWithPagination(page, productCountOnPage, query)
 	if err != nil {
-		return jError(w, err)
+		return a.jError(w, r, err)
 	}
 
 	var res viewmodels.ProductsWithCount
@@ -162,47 +633,307 @@ func (a *adapter) getProducts(w http.ResponseWriter, r *http.Request) error {
 	return j(w, http.StatusOK, res)
 }
 
+// productQuery parses the filters and the sort of the product list from the query params.
+func (a *adapter) productQuery(r *http.Request) (*domain.ProductQuery, error) {
+	params := r.URL.Query()
+	query := &domain.ProductQuery{
+		Search:   params.Get("search"),
+		Category: domain.ProductCategory(params.Get("category")),
+		Sort:     domain.ProductSort(params.Get("sort")),
+	}
+
+	var err error
+	if query.MinPerHour, err = a.floatQueryParam(r, "min_per_hour"); err != nil {
+		return nil, err
+	}
+
+	if query.MaxPerHour, err = a.floatQueryParam(r, "max_per_hour"); err != nil {
+		return nil, err
+	}
+
+	if str := params.Get("owner_id"); str != "" {
+		v, err := strconv.Atoi(str)
+		if err != nil {
+			a.logger.WithError(err).Error("cannot parse 'owner_id' query param")
+			return nil, domain.ErrInvalidInputData
+		}
+		query.OwnerID = &v
+	}
+
+	from, err := a.timeQueryParam(r, "from")
+	if err != nil {
+		return nil, err
+	}
+	if !from.IsZero() {
+		query.AvailableFrom = &from
+	}
+
+	to, err := a.timeQueryParam(r, "to")
+	if err != nil {
+		return nil, err
+	}
+	if !to.IsZero() {
+		query.AvailableTo = &to
+	}
+
+	return query, nil
+}
+
 func (a *adapter) rentProduct(w http.ResponseWriter, r *http.Request) error {
-	productIDStr := chi.URLParam(r, "product_id")
-	productID, err := strconv.Atoi(productIDStr)
+	productID, err := a.intURLParam(r, "product_id")
 	if err != nil {
-		a.logger.WithError(err).Error("product_id is not int")
-		return jError(w, domain.ErrInvalidInputData)
+		return a.jError(w, r, err)
 	}
 
-	var from, to time.Time
-	if dateStr := r.URL.Query().Get("from"); dateStr != "" {
-		if date, err := time.Parse("2006-01-02 15:04", dateStr); err != nil {
-			a.logger.WithError(domain.ErrInvalidInputData).Error("cannot parse 'from' query param")
-			return jError(w, domain.ErrInvalidInputData)
-		} else {
-			from = date
-		}
+	from, err := a.timeQueryParam(r, "from")
+	if err != nil {
+		return a.jError(w, r, err)
 	}
 
-	if dateStr := r.URL.Query().Get("to"); dateStr != "" {
-		if date, err := time.Parse("2006-01-02 15:04", dateStr); err != nil {
-			a.logger.WithError(domain.ErrInvalidInputData).Error("cannot parse 'to' query param")
-			return jError(w, domain.ErrInvalidInputData)
-		} else {
-			to = date
-		}
+	to, err := a.timeQueryParam(r, "to")
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	if err := viewmodels.ValidatePeriod(from, to); err != nil {
+		return a.jError(w, r, err)
 	}
 
 	if err := a.service.RentProduct(r.Context(), productID, from, to); err != nil {
-		return jError(w, err)
+		return a.jError(w, r, err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (a *adapter) quoteProduct(w http.ResponseWriter, r *http.Request) error {
+	productID, err := a.intURLParam(r, "product_id")
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	from, err := a.timeQueryParam(r, "from")
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	to, err := a.timeQueryParam(r, "to")
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	if err := viewmodels.ValidatePeriod(from, to); err != nil {
+		return a.jError(w, r, err)
+	}
+
+	quote, err := a.service.QuoteProduct(productID, from, to)
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	var res viewmodels.Quote
+	res.ViewModel(quote)
+	return j(w, http.StatusOK, res)
+}
+
+func (a *adapter) getAvailability(w http.ResponseWriter, r *http.Request) error {
+	productID, err := a.intURLParam(r, "product_id")
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	from, err := a.timeQueryParam(r, "from")
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+	if from.IsZero() {
+		from = time.Now().UTC().Truncate(time.Hour)
+	}
+
+	to, err := a.timeQueryParam(r, "to")
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+	if to.IsZero() {
+		to = from.Add(defaultAvailabilityWindow)
+	}
+
+	availability, err := a.service.GetAvailability(r.Context(), productID, from, to)
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	var res viewmodels.Availability
+	res.ViewModel(availability)
+	return j(w, http.StatusOK, res)
+}
+
+func (a *adapter) getBlackouts(w http.ResponseWriter, r *http.Request) error {
+	productID, err := a.intURLParam(r, "product_id")
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	blackouts, err := a.service.GetBlackouts(r.Context(), productID)
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	res := viewmodels.Blackouts{}
+	res.ViewModel(blackouts)
+	return j(w, http.StatusOK, res)
+}
+
+func (a *adapter) addBlackout(w http.ResponseWriter, r *http.Request) error {
+	productID, err := a.intURLParam(r, "product_id")
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	var req viewmodels.Blackout
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.logger.WithError(err).Error("Error while decoding request body!")
+		return a.jError(w, r, domain.ErrInvalidInputData)
+	}
+
+	if err := req.Validate(); err != nil {
+		return a.jError(w, r, err)
+	}
+
+	blackout := req.Domain()
+	blackout.ProductID = productID
+
+	blackoutID, err := a.service.AddBlackout(r.Context(), blackout)
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	return j(w, http.StatusOK, struct {
+		BlackoutID int `json:"blackout_id"`
+	}{BlackoutID: blackoutID})
+}
+
+func (a *adapter) updateBlackout(w http.ResponseWriter, r *http.Request) error {
+	productID, err := a.intURLParam(r, "product_id")
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	blackoutID, err := a.intURLParam(r, "blackout_id")
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	var req viewmodels.Blackout
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.logger.WithError(err).Error("Error while decoding request body!")
+		return a.jError(w, r, domain.ErrInvalidInputData)
+	}
+
+	if err := req.Validate(); err != nil {
+		return a.jError(w, r, err)
+	}
+
+	blackout := req.Domain()
+	blackout.ID = blackoutID
+	blackout.ProductID = productID
+
+	if err := a.service.UpdateBlackout(r.Context(), blackout); err != nil {
+		return a.jError(w, r, err)
 	}
 
 	w.WriteHeader(http.StatusOK)
 	return nil
 }
 
+func (a *adapter) deleteBlackout(w http.ResponseWriter, r *http.Request) error {
+	productID, err := a.intURLParam(r, "product_id")
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	blackoutID, err := a.intURLParam(r, "blackout_id")
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	if err := a.service.DeleteBlackout(r.Context(), productID, blackoutID); err != nil {
+		return a.jError(w, r, err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// intURLParam parses an int URL param.
+func (a *adapter) intURLParam(r *http.Request, name string) (int, error) {
+	value, err := strconv.Atoi(chi.URLParam(r, name))
+	if err != nil {
+		a.logger.WithError(err).Errorf("%s is not int", name)
+		return 0, domain.ErrInvalidInputData
+	}
+
+	return value, nil
+}
+
+// pageQueryParam parses the optional one-based 'page' query param into a zero-based page.
+func (a *adapter) pageQueryParam(r *http.Request) (int, error) {
+	var page int
+	if pageStr := r.URL.Query().Get("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err != nil {
+			a.logger.WithError(domain.ErrInvalidInputData).Error("cannot parse 'page' query param")
+			return 0, domain.ErrInvalidInputData
+		} else {
+			page = p - 1
+		}
+	}
+
+	if page < 0 {
+		return 0, domain.ErrInvalidInputData
+	}
+
+	return page, nil
+}
+
+// timeQueryParam parses an optional time query param, the zero time is returned if it is absent.
+func (a *adapter) timeQueryParam(r *http.Request, name string) (time.Time, error) {
+	dateStr := r.URL.Query().Get(name)
+	if dateStr == "" {
+		return time.Time{}, nil
+	}
+
+	date, err := time.Parse(queryTimeLayout, dateStr)
+	if err != nil {
+		a.logger.WithError(domain.ErrInvalidInputData).Errorf("cannot parse '%s' query param", name)
+		return time.Time{}, domain.ErrInvalidInputData
+	}
+
+	return date, nil
+}
+
+// floatQueryParam parses an optional number query param, nil is returned if it is absent.
+func (a *adapter) floatQueryParam(r *http.Request, name string) (*float64, error) {
+	str := r.URL.Query().Get(name)
+	if str == "" {
+		return nil, nil
+	}
+
+	value, err := strconv.ParseFloat(str, 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		a.logger.WithError(domain.ErrInvalidInputData).Errorf("cannot parse '%s' query param", name)
+		return nil, domain.ErrInvalidInputData
+	}
+
+	return &value, nil
+}
+
 func (a *adapter) getOrders(w http.ResponseWriter, r *http.Request) error {
 	var isMine bool
 	if isMeStr := r.URL.Query().Get("mine"); isMeStr != "" {
 		if mm, err := strconv.ParseBool(isMeStr); err != nil {
 			a.logger.WithError(domain.ErrInvalidInputData).Error("cannot parse 'mine' query param")
-			return jError(w, domain.ErrInvalidInputData)
+			return a.jError(w, r, domain.ErrInvalidInputData)
 		} else {
 			isMine = mm
 		}
@@ -210,10 +941,52 @@ func (a *adapter) getOrders(w http.ResponseWriter, r *http.Request) error {
 
 	orders, err := a.service.GetOrders(r.Context(), isMine)
 	if err != nil {
-		return jError(w, err)
+		return a.jError(w, r, err)
 	}
 
 	res := viewmodels.Orders{}
 	res.ViewModel(orders)
 	return j(w, http.StatusOK, res)
 }
+
+func (a *adapter) reviewOrder(w http.ResponseWriter, r *http.Request) error {
+	orderID, err := a.intURLParam(r, "order_id")
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	var req viewmodels.Review
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.logger.WithError(err).Error("Error while decoding request body!")
+		return a.jError(w, r, domain.ErrInvalidInputData)
+	}
+
+	if err := req.Validate(); err != nil {
+		return a.jError(w, r, err)
+	}
+
+	reviewID, err := a.service.ReviewOrder(r.Context(), orderID, req.Domain())
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	return j(w, http.StatusOK, struct {
+		ReviewID int `json:"review_id"`
+	}{ReviewID: reviewID})
+}
+
+func (a *adapter) changeOrderStatus(change func(ctx context.Context, orderID int) error) func(w http.ResponseWriter, r *http.Request) error {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		orderID, err := a.intURLParam(r, "order_id")
+		if err != nil {
+			return a.jError(w, r, err)
+		}
+
+		if err := change(r.Context(), orderID); err != nil {
+			return a.jError(w, r, err)
+		}
+
+		w.WriteHeader(http.StatusOK)
+		return nil
+	}
+}
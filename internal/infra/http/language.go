@@ -0,0 +1,26 @@
+package http
+
+import (
+	"backend/internal/domain"
+	"backend/internal/i18n"
+	"net/http"
+)
+
+// language returns the language of the user facing messages:
+// the user preference, the Accept-Language header or the default one.
+func (a *adapter) language(r *http.Request) i18n.Language {
+	if _, ok := r.Context().Value(domain.ContextUserID).(int); ok {
+		user, err := a.service.GetUser(r.Context())
+		if err == nil && user.Language != nil {
+			if lang, ok := i18n.ParseLanguage(*user.Language); ok {
+				return lang
+			}
+		}
+	}
+
+	if lang, ok := i18n.FromAcceptLanguage(r.Header.Get("Accept-Language")); ok {
+		return lang
+	}
+
+	return a.defaultLanguage
+}
@@ -3,71 +3,125 @@ package http
 import (
 	"backend/internal/domain"
 	"context"
-	"github.com/go-chi/chi"
-	"github.com/go-chi/jwtauth"
+	"net"
 	"net/http"
 	"strconv"
+	"strings"
 )
 
 func (a *adapter) JWTAuthMiddleware() func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			authMiddleware := func(next http.Handler) http.Handler {
-				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-					r.Header.Set("Authorization", jwtauth.TokenFromHeader(r))
-
-					token, err := jwtauth.VerifyRequest(a.jwtAuth, r, func(r *http.Request) string {
-						return r.Header.Get("Authorization")
-					})
-
-					if err != nil {
-						a.logger.WithError(err).Error(domain.ErrUnauthorized)
-						_ = jError(w, domain.ErrUnauthorized)
-						return
-					}
-
-					ctx := r.Context()
-					ctx = jwtauth.NewContext(ctx, token, err)
-					next.ServeHTTP(w, r.WithContext(ctx))
-				})
+			token := tokenFromHeader(r)
+			if token == "" {
+				a.logger.Error("There is no bearer token!")
+				_ = a.jError(w, r, domain.ErrUnauthorized)
+				return
 			}
 
-			userIDMiddleware := func(next http.Handler) http.Handler {
-				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-					_, claims, err := jwtauth.FromContext(r.Context())
-					if err != nil {
-						a.logger.WithError(err).Error("Error while extracting JWT token from context!")
-						_ = jError(w, domain.ErrUnauthorized)
-						return
-					}
-
-					sub, ok := claims["sub"]
-					if !ok {
-						a.logger.Error("Token is without 'sub' field!")
-						_ = jError(w, domain.ErrUnauthorized)
-						return
-					}
-
-					subStr, ok := sub.(string)
-					if !ok {
-						a.logger.Error("'sub' field is not a string!")
-						_ = jError(w, domain.ErrUnauthorized)
-						return
-					}
-
-					userID, err := strconv.Atoi(subStr)
-					if err != nil {
-						a.logger.WithError(err).Error("Error while converting string into int!")
-						_ = jError(w, domain.ErrUnauthorized)
-						return
-					}
-
-					r = r.WithContext(context.WithValue(r.Context(), domain.ContextUserID, userID))
-					w.Header().Set("User-ID", strconv.Itoa(userID))
-					next.ServeHTTP(w, r)
-				})
+			// tokens of revoked sessions are rejected before they expire
+			claims, err := a.service.Authenticate(token)
+			if err != nil {
+				a.logger.WithError(err).Error(domain.ErrUnauthorized)
+				_ = a.jError(w, r, domain.ErrUnauthorized)
+				return
 			}
-			chi.Chain(authMiddleware, userIDMiddleware).Handler(next).ServeHTTP(w, r)
+
+			ctx := context.WithValue(r.Context(), domain.ContextUserID, claims.UserID)
+			ctx = context.WithValue(ctx, domain.ContextSessionID, claims.SessionID)
+			ctx = context.WithValue(ctx, domain.ContextRole, claims.Role)
+			w.Header().Set("User-ID", strconv.Itoa(claims.UserID))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequirePermission lets through the users whose role has the permission,
+// it is used after JWTAuthMiddleware.
+func (a *adapter) RequirePermission(p domain.Permission) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role, _ := r.Context().Value(domain.ContextRole).(domain.Role)
+			if !role.Can(p) {
+				a.logger.WithField("role", role).WithField("permission", p).Error(domain.ErrForbidden)
+				_ = a.jError(w, r, domain.ErrForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
 		})
 	}
 }
+
+// ClientMiddleware puts the client IP and user agent into the request context.
+func (a *adapter) ClientMiddleware() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), domain.ContextClient, &domain.Client{
+				IP:        a.clientIP(r),
+				UserAgent: r.UserAgent(),
+			})
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// clientIP returns the address of the client. The forwarded headers are only trusted when the request
+// comes from a trusted proxy, otherwise the clients could pick any address to dodge the login throttle.
+func (a *adapter) clientIP(r *http.Request) string {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		ip = host
+	}
+
+	if !a.trustedProxy(ip) {
+		return ip
+	}
+
+	// the proxies append the addresses, so the rightmost one not added by a trusted proxy is the client
+	if header := r.Header.Get("X-Forwarded-For"); header != "" {
+		hops := strings.Split(header, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if net.ParseIP(hop) == nil {
+				break
+			}
+			ip = hop
+			if !a.trustedProxy(hop) {
+				return hop
+			}
+		}
+		return ip
+	}
+
+	if header := strings.TrimSpace(r.Header.Get("X-Real-IP")); net.ParseIP(header) != nil {
+		return header
+	}
+
+	return ip
+}
+
+func (a *adapter) trustedProxy(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+
+	for _, network := range a.trustedProxies {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// tokenFromHeader returns the token of the "Authorization: Bearer <token>" header.
+func tokenFromHeader(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	if len(header) > 7 && strings.EqualFold(header[:7], "bearer ") {
+		return header[7:]
+	}
+
+	return ""
+}
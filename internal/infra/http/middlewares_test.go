@@ -0,0 +1,68 @@
+package http
+
+import (
+	"net"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	_, proxies, _ := net.ParseCIDR("10.0.0.0/8")
+	a := &adapter{trustedProxies: []*net.IPNet{proxies}}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		want       string
+	}{
+		{
+			name:       "direct client",
+			remoteAddr: "203.0.113.7:4000",
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "forged headers of a direct client",
+			remoteAddr: "203.0.113.7:4000",
+			headers:    map[string]string{"X-Forwarded-For": "198.51.100.1", "X-Real-IP": "198.51.100.2"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "client behind a trusted proxy",
+			remoteAddr: "10.0.0.2:4000",
+			headers:    map[string]string{"X-Forwarded-For": "203.0.113.7"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "forged hop before the real client",
+			remoteAddr: "10.0.0.2:4000",
+			headers:    map[string]string{"X-Forwarded-For": "198.51.100.1, 203.0.113.7, 10.0.0.3"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "real IP header of a trusted proxy",
+			remoteAddr: "10.0.0.2:4000",
+			headers:    map[string]string{"X-Real-IP": "203.0.113.7"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "trusted proxy without headers",
+			remoteAddr: "10.0.0.2:4000",
+			want:       "10.0.0.2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+
+			if got := a.clientIP(r); got != tt.want {
+				t.Errorf("clientIP() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
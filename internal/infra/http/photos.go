@@ -0,0 +1,231 @@
+package http
+
+import (
+	"backend/internal/domain"
+	"backend/internal/infra/http/viewmodels"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxUploadSize caps the uploaded files before the service checks their size limits.
+const maxUploadSize int64 = 32 << 20
+
+// blobsPath is the prefix of the signed URLs of the blob store served by the service.
+const blobsPath = "/api/v1/blobs/"
+
+// uploadProductPhoto accepts the photo as the "photo" field of a multipart form.
+func (a *adapter) uploadProductPhoto(w http.ResponseWriter, r *http.Request) error {
+	productID, err := a.intURLParam(r, "product_id")
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	data, err := a.multipartFile(w, r, "photo")
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	photo, err := a.service.UploadProductPhoto(r.Context(), productID, data)
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	var res viewmodels.Photo
+	res.ViewModel(photo)
+	return j(w, http.StatusOK, res)
+}
+
+// uploadAvatar accepts the avatar as the "avatar" field of a multipart form.
+func (a *adapter) uploadAvatar(w http.ResponseWriter, r *http.Request) error {
+	data, err := a.multipartFile(w, r, "avatar")
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	user, err := a.service.UploadAvatar(r.Context(), data)
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	var res viewmodels.User
+	res.ViewModel(user)
+	return j(w, http.StatusOK, res)
+}
+
+func (a *adapter) deleteAvatar(w http.ResponseWriter, r *http.Request) error {
+	if err := a.service.DeleteAvatar(r.Context()); err != nil {
+		return a.jError(w, r, err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (a *adapter) reorderProductPhotos(w http.ResponseWriter, r *http.Request) error {
+	productID, err := a.intURLParam(r, "product_id")
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	var req viewmodels.PhotoOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.logger.WithError(err).Error("Error while decoding request body!")
+		return a.jError(w, r, domain.ErrInvalidInputData)
+	}
+
+	if err := req.Validate(); err != nil {
+		return a.jError(w, r, err)
+	}
+
+	if err := a.service.ReorderProductPhotos(r.Context(), productID, req.PhotoIDs); err != nil {
+		return a.jError(w, r, err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (a *adapter) setCoverPhoto(w http.ResponseWriter, r *http.Request) error {
+	productID, err := a.intURLParam(r, "product_id")
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	photoID, err := a.intURLParam(r, "photo_id")
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	if err := a.service.SetCoverPhoto(r.Context(), productID, photoID); err != nil {
+		return a.jError(w, r, err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (a *adapter) updatePhotoCaption(w http.ResponseWriter, r *http.Request) error {
+	productID, err := a.intURLParam(r, "product_id")
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	photoID, err := a.intURLParam(r, "photo_id")
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	var req viewmodels.PhotoCaptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.logger.WithError(err).Error("Error while decoding request body!")
+		return a.jError(w, r, domain.ErrInvalidInputData)
+	}
+
+	if err := req.Validate(); err != nil {
+		return a.jError(w, r, err)
+	}
+
+	if err := a.service.UpdatePhotoCaption(r.Context(), productID, photoID, req.Caption); err != nil {
+		return a.jError(w, r, err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (a *adapter) deleteProductPhoto(w http.ResponseWriter, r *http.Request) error {
+	productID, err := a.intURLParam(r, "product_id")
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	photoID, err := a.intURLParam(r, "photo_id")
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+
+	if err := a.service.DeleteProductPhoto(r.Context(), productID, photoID); err != nil {
+		return a.jError(w, r, err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// multipartFile reads the file of the multipart form field, the other fields are skipped.
+func (a *adapter) multipartFile(w http.ResponseWriter, r *http.Request, field string) ([]byte, error) {
+	// the slack leaves room for the multipart headers and the other fields
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize+1<<20)
+
+	mr, err := r.MultipartReader()
+	if err != nil {
+		a.logger.WithError(err).Error("Error while reading multipart form!")
+		return nil, domain.ErrInvalidInputData
+	}
+
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			a.logger.WithError(err).Error("Error while reading multipart form!")
+			return nil, domain.ErrInvalidInputData
+		}
+
+		if part.FormName() != field {
+			continue
+		}
+
+		data, err := ioutil.ReadAll(io.LimitReader(part, maxUploadSize+1))
+		if err != nil {
+			a.logger.WithError(err).Error("Error while reading multipart file!")
+			return nil, domain.ErrInvalidInputData
+		}
+
+		if int64(len(data)) > maxUploadSize {
+			return nil, domain.ErrPhotoTooLarge
+		}
+
+		return data, nil
+	}
+
+	a.logger.WithField("field", field).Error("There is no file in multipart form!")
+	return nil, domain.ErrInvalidInputData
+}
+
+// getBlob serves the signed URLs of the blob store.
+func (a *adapter) getBlob(w http.ResponseWriter, r *http.Request) error {
+	// the key is taken from the path itself, the URL format middleware cuts the extension off the route
+	key := strings.TrimPrefix(r.URL.Path, blobsPath)
+
+	expires, err := strconv.ParseInt(r.URL.Query().Get("expires"), 10, 64)
+	if err != nil {
+		return a.jError(w, r, domain.ErrInvalidToken)
+	}
+
+	blob, err := a.service.OpenBlob(r.Context(), key, expires, r.URL.Query().Get("signature"))
+	if err != nil {
+		return a.jError(w, r, err)
+	}
+	defer blob.Body.Close()
+
+	maxAge := expires - time.Now().Unix()
+	if maxAge < 0 {
+		maxAge = 0
+	}
+
+	w.Header().Set("Content-Type", blob.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(blob.Size, 10))
+	w.Header().Set("Cache-Control", "private, max-age="+strconv.FormatInt(maxAge, 10))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+
+	_, err = io.Copy(w, blob.Body)
+	return err
+}
@@ -1,9 +1,9 @@
 package http
 
 import (
+	"backend/internal/domain"
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
-	"github.com/go-chi/jwtauth"
 	"github.com/rs/cors"
 	"net/http"
 )
@@ -13,7 +13,7 @@ func (a *adapter) newRouter() (http.Handler, error) {
 
 	// Set default middleware
 	r.Use(middleware.RequestID)
-	r.Use(middleware.RealIP)
+	r.Use(a.ClientMiddleware())
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.URLFormat)
 	r.Use(middleware.Logger)
@@ -25,6 +25,15 @@ func (a *adapter) newRouter() (http.Handler, error) {
 	})
 	r.Use(c.Handler)
 
+	r.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		_ = a.jError(w, r, errRouteNotFound)
+	})
+	r.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		_ = a.jError(w, r, errMethodNotAllowed)
+	})
+
+	r.Get("/.well-known/jwks.json", a.wrap(a.getJWKS))
+
 	r.Route("/api", func(r chi.Router) {
 		r.Route("/test", func(r chi.Router) {
 			r.Get("/hello", a.wrap(a.sayHello))
@@ -34,30 +43,91 @@ func (a *adapter) newRouter() (http.Handler, error) {
 			r.Route("/auth", func(r chi.Router) {
 				r.Post("/register", a.wrap(a.register))
 				r.Post("/login", a.wrap(a.login))
+				r.Post("/login/2fa", a.wrap(a.loginTwoFactor))
+				r.Post("/refresh", a.wrap(a.refresh))
+				r.Post("/password/forgot", a.wrap(a.forgotPassword))
+				r.Post("/password/reset", a.wrap(a.resetPassword))
+				r.Post("/verify-email", a.wrap(a.verifyEmail))
+				r.Get("/oidc", a.wrap(a.getOIDCProviders))
+				r.Post("/oidc/callback", a.wrap(a.finishOIDCLogin))
+				r.Post("/oidc/{provider}", a.wrap(a.startOIDCLogin))
+				r.Group(func(r chi.Router) {
+					r.Use(a.JWTAuthMiddleware())
+					r.Post("/logout", a.wrap(a.logout))
+					r.Post("/verify-email/resend", a.wrap(a.resendVerificationEmail))
+				})
 			})
 
 			r.Group(func(r chi.Router) {
 				r.Route("/user", func(r chi.Router) {
-					r.Use(jwtauth.Verifier(a.jwtAuth))
 					r.Use(a.JWTAuthMiddleware())
 					r.Get("/", a.wrap(a.getUser))
+					r.Patch("/", a.wrap(a.patchUser))
+					r.Delete("/", a.wrap(a.deleteUser))
+					r.Get("/export", a.wrap(a.exportUser))
+					r.Post("/password", a.wrap(a.changePassword))
+					r.Put("/avatar", a.wrap(a.uploadAvatar))
+					r.Delete("/avatar", a.wrap(a.deleteAvatar))
+					r.Get("/sessions", a.wrap(a.getSessions))
+					r.Delete("/sessions", a.wrap(a.revokeOtherSessions))
+					r.Delete("/sessions/{session_id}", a.wrap(a.revokeSession))
+					r.Post("/2fa/enroll", a.wrap(a.enrollTwoFactor))
+					r.Post("/2fa/confirm", a.wrap(a.confirmTwoFactor))
+					r.Post("/2fa/recovery-codes", a.wrap(a.regenerateRecoveryCodes))
+					r.Delete("/2fa", a.wrap(a.disableTwoFactor))
+					r.Get("/identities", a.wrap(a.getIdentities))
+					r.Post("/identities/callback", a.wrap(a.linkIdentity))
+					r.Post("/identities/{provider}", a.wrap(a.startIdentityLink))
+					r.Delete("/identities/{identity_id}", a.wrap(a.unlinkIdentity))
 				})
 
+				r.Get("/users/{user_id}", a.wrap(a.getPublicProfile))
+				r.Get("/blobs/*", a.wrap(a.getBlob))
+
 				r.Route("/product", func(r chi.Router) {
 					r.Get("/", a.wrap(a.getProducts))
 					r.Group(func(r chi.Router) {
-						r.Use(jwtauth.Verifier(a.jwtAuth))
 						r.Use(a.JWTAuthMiddleware())
 						r.Post("/", a.wrap(a.addProduct))
 						r.Get("/{product_id}", a.wrap(a.getProduct))
+						r.Put("/{product_id}", a.wrap(a.updateProduct))
+						r.Patch("/{product_id}", a.wrap(a.patchProduct))
+						r.Delete("/{product_id}", a.wrap(a.archiveProduct))
+						r.Post("/{product_id}/photos", a.wrap(a.uploadProductPhoto))
+						r.Put("/{product_id}/photos/order", a.wrap(a.reorderProductPhotos))
+						r.Post("/{product_id}/photos/{photo_id}/cover", a.wrap(a.setCoverPhoto))
+						r.Patch("/{product_id}/photos/{photo_id}", a.wrap(a.updatePhotoCaption))
+						r.Delete("/{product_id}/photos/{photo_id}", a.wrap(a.deleteProductPhoto))
+						r.Get("/{product_id}/quote", a.wrap(a.quoteProduct))
+						r.Get("/{product_id}/availability", a.wrap(a.getAvailability))
+						r.Get("/{product_id}/blackouts", a.wrap(a.getBlackouts))
+						r.Post("/{product_id}/blackouts", a.wrap(a.addBlackout))
+						r.Put("/{product_id}/blackouts/{blackout_id}", a.wrap(a.updateBlackout))
+						r.Delete("/{product_id}/blackouts/{blackout_id}", a.wrap(a.deleteBlackout))
 					})
 				})
 
 				r.Route("/order", func(r chi.Router) {
-					r.Use(jwtauth.Verifier(a.jwtAuth))
 					r.Use(a.JWTAuthMiddleware())
 					r.Post("/{product_id}", a.wrap(a.rentProduct))
 					r.Get("/", a.wrap(a.getOrders))
+					r.Post("/{order_id}/approve", a.wrap(a.changeOrderStatus(a.service.ApproveOrder)))
+					r.Post("/{order_id}/reject", a.wrap(a.changeOrderStatus(a.service.RejectOrder)))
+					r.Post("/{order_id}/start", a.wrap(a.changeOrderStatus(a.service.StartOrder)))
+					r.Post("/{order_id}/complete", a.wrap(a.changeOrderStatus(a.service.CompleteOrder)))
+					r.Post("/{order_id}/cancel", a.wrap(a.changeOrderStatus(a.service.CancelOrder)))
+					r.Post("/{order_id}/review", a.wrap(a.reviewOrder))
+				})
+
+				r.Route("/admin", func(r chi.Router) {
+					r.Use(a.JWTAuthMiddleware())
+					r.With(a.RequirePermission(domain.PermissionViewUsers)).Get("/users", a.wrap(a.getUsers))
+					r.With(a.RequirePermission(domain.PermissionBlockUsers)).Post("/users/{user_id}/block", a.wrap(a.blockUser))
+					r.With(a.RequirePermission(domain.PermissionBlockUsers)).Post("/users/{user_id}/unblock", a.wrap(a.unblockUser))
+					r.With(a.RequirePermission(domain.PermissionManageRoles)).Put("/users/{user_id}/role", a.wrap(a.setUserRole))
+					r.With(a.RequirePermission(domain.PermissionModerateProducts)).Post("/products/{product_id}/hide", a.wrap(a.hideProduct))
+					r.With(a.RequirePermission(domain.PermissionModerateProducts)).Post("/products/{product_id}/unhide", a.wrap(a.unhideProduct))
+					r.With(a.RequirePermission(domain.PermissionViewAllOrders)).Get("/orders", a.wrap(a.getAllOrders))
 				})
 			})
 		})
@@ -37,24 +37,3 @@ func j(w http.ResponseWriter, code int, payload interface{}) error {
 
 	return nil
 }
-
-func jError(w http.ResponseWriter, err error) error {
-	code := http.StatusInternalServerError
-	localizedError := "Внутренняя ошибка!"
-
-	switch err {
-
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Status", strconv.Itoa(code))
-	w.WriteHeader(code)
-	if err := json.NewEncoder(w).Encode(map[string]interface{}{
-		"error":           err.Error(),
-		"localized_error": localizedError,
-	}); err != nil {
-		return fmt.Errorf("cannot write response: %w", err)
-	}
-
-	return nil
-}
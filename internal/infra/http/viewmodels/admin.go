@@ -0,0 +1,83 @@
+package viewmodels
+
+import (
+	"backend/internal/domain"
+	"time"
+)
+
+// AdminUser is the user as seen in the admin API.
+type AdminUser struct {
+	ID              int        `json:"id"`
+	FirstName       string     `json:"first_name"`
+	LastName        string     `json:"last_name"`
+	Login           string     `json:"login"`
+	Email           string     `json:"email"`
+	Role            string     `json:"role"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	BlockedAt       *time.Time `json:"blocked_at,omitempty"`
+	BlockReason     *string    `json:"block_reason,omitempty"`
+}
+
+func (u *AdminUser) ViewModel(d *domain.User) {
+	u.ID = d.ID
+	u.FirstName = d.FirstName
+	u.LastName = d.LastName
+	u.Login = d.Login
+	u.Email = d.Email
+	u.Role = string(d.Role)
+	u.EmailVerifiedAt = d.EmailVerifiedAt
+	u.BlockedAt = d.BlockedAt
+	u.BlockReason = d.BlockReason
+}
+
+type AdminUsersWithCount struct {
+	Users []*AdminUser `json:"users"`
+	Count int          `json:"count"`
+}
+
+func (u *AdminUsersWithCount) ViewModel(dd []*domain.User, count int) {
+	u.Users = make([]*AdminUser, 0)
+	for _, d := range dd {
+		var v AdminUser
+		v.ViewModel(d)
+		u.Users = append(u.Users, &v)
+	}
+	u.Count = count
+}
+
+type OrdersWithCount struct {
+	Orders Orders `json:"orders"`
+	Count  int    `json:"count"`
+}
+
+func (o *OrdersWithCount) ViewModel(dd []*domain.Order, count int) {
+	o.Orders.ViewModel(dd)
+	o.Count = count
+}
+
+// ModerationRequest carries the reason of blocking a user or hiding a product.
+type ModerationRequest struct {
+	Reason string `json:"reason"`
+}
+
+func (m *ModerationRequest) Validate() error {
+	var v validator
+	if v.required("reason", m.Reason) {
+		v.length("reason", m.Reason, 1, 500)
+	}
+
+	return v.err()
+}
+
+type RoleRequest struct {
+	Role string `json:"role"`
+}
+
+func (r *RoleRequest) Validate() error {
+	var v validator
+	if v.required("role", r.Role) {
+		v.check(domain.Role(r.Role).Valid(), "role", codeUnsupported, "is not supported")
+	}
+
+	return v.err()
+}
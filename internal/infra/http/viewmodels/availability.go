@@ -0,0 +1,89 @@
+package viewmodels
+
+import (
+	"backend/internal/domain"
+	"time"
+)
+
+type Interval struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+type Intervals []*Interval
+
+func (ii *Intervals) ViewModel(dd []domain.Interval) {
+	*ii = make([]*Interval, 0)
+	for _, d := range dd {
+		*ii = append(*ii, &Interval{From: d.From, To: d.To})
+	}
+}
+
+type Blackout struct {
+	ID     int       `json:"id"`
+	From   time.Time `json:"from"`
+	To     time.Time `json:"to"`
+	Reason *string   `json:"reason,omitempty"`
+}
+
+func (b *Blackout) Domain() *domain.Blackout {
+	return &domain.Blackout{
+		ID:     b.ID,
+		From:   b.From,
+		To:     b.To,
+		Reason: b.Reason,
+	}
+}
+
+func (b *Blackout) Validate() error {
+	var v validator
+	v.check(!b.From.IsZero(), "from", codeRequired, "must not be empty")
+	if v.check(!b.To.IsZero(), "to", codeRequired, "must not be empty") {
+		v.after("to", b.To, b.From)
+	}
+	if b.Reason != nil {
+		v.length("reason", *b.Reason, 0, 500)
+	}
+
+	return v.err()
+}
+
+func (b *Blackout) ViewModel(d *domain.Blackout) {
+	b.ID = d.ID
+	b.From = d.From
+	b.To = d.To
+	b.Reason = d.Reason
+}
+
+type Blackouts []*Blackout
+
+func (bb *Blackouts) ViewModel(dd []*domain.Blackout) {
+	*bb = make([]*Blackout, 0)
+	for _, d := range dd {
+		var b Blackout
+		b.ViewModel(d)
+		*bb = append(*bb, &b)
+	}
+}
+
+// Availability is shown to every user, so the blackouts go without the reasons the owner noted for themselves.
+type Availability struct {
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+	Booked    Intervals `json:"booked"`
+	Blackouts Intervals `json:"blackouts"`
+	Free      Intervals `json:"free"`
+}
+
+func (a *Availability) ViewModel(d *domain.Availability) {
+	a.From = d.From
+	a.To = d.To
+	a.Booked.ViewModel(d.Booked)
+	a.Free.ViewModel(d.Free)
+
+	blackouts := make([]domain.Interval, 0, len(d.Blackouts))
+	for _, b := range d.Blackouts {
+		blackouts = append(blackouts, domain.Interval{From: b.From, To: b.To})
+	}
+	a.Blackouts.ViewModel(blackouts)
+}
@@ -0,0 +1,115 @@
+package viewmodels
+
+import (
+	"backend/internal/domain"
+	"time"
+)
+
+// ExportUser is the full profile of the user in the personal data export.
+type ExportUser struct {
+	ID              int        `json:"id"`
+	Login           string     `json:"login"`
+	FirstName       string     `json:"first_name"`
+	LastName        string     `json:"last_name"`
+	Email           string     `json:"email"`
+	Language        *string    `json:"language,omitempty"`
+	Avatar          *string    `json:"avatar,omitempty"`
+	Role            string     `json:"role"`
+	CreatedAt       time.Time  `json:"created_at"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	TOTPEnabledAt   *time.Time `json:"two_factor_enabled_at,omitempty"`
+	BlockedAt       *time.Time `json:"blocked_at,omitempty"`
+	BlockReason     *string    `json:"block_reason,omitempty"`
+}
+
+func (u *ExportUser) ViewModel(d *domain.User) {
+	u.ID = d.ID
+	u.Login = d.Login
+	u.FirstName = d.FirstName
+	u.LastName = d.LastName
+	u.Email = d.Email
+	u.Language = d.Language
+	u.Avatar = d.Avatar
+	u.Role = string(d.Role)
+	u.CreatedAt = d.CreatedAt
+	u.EmailVerifiedAt = d.EmailVerifiedAt
+	u.TOTPEnabledAt = d.TOTPEnabledAt
+	u.BlockedAt = d.BlockedAt
+	u.BlockReason = d.BlockReason
+}
+
+// ExportOrder is the order in the personal data export, the other party is referenced by id only.
+type ExportOrder struct {
+	ID           int        `json:"id"`
+	ProductID    int        `json:"product_id"`
+	UserID       int        `json:"user_id"`
+	OrderStart   time.Time  `json:"order_start"`
+	OrderEnd     time.Time  `json:"order_end"`
+	Price        float64    `json:"price"`
+	Status       string     `json:"status"`
+	CreatedAt    time.Time  `json:"created_at"`
+	CancelledAt  *time.Time `json:"cancelled_at,omitempty"`
+	RefundAmount *float64   `json:"refund_amount,omitempty"`
+}
+
+func (o *ExportOrder) ViewModel(d *domain.Order) {
+	o.ID = d.ID
+	o.ProductID = d.ProductID
+	o.UserID = d.UserID
+	o.OrderStart = d.OrderStart
+	o.OrderEnd = d.OrderEnd
+	o.Price = d.Price.Float64()
+	o.Status = string(d.Status)
+	o.CreatedAt = d.CreatedAt
+	o.CancelledAt = d.CancelledAt
+	if d.RefundAmount != nil {
+		refund := d.RefundAmount.Float64()
+		o.RefundAmount = &refund
+	}
+}
+
+type ExportOrders []*ExportOrder
+
+func (oo *ExportOrders) ViewModel(dd []*domain.Order) {
+	*oo = make([]*ExportOrder, 0)
+	for _, d := range dd {
+		var o ExportOrder
+		o.ViewModel(d)
+		*oo = append(*oo, &o)
+	}
+}
+
+type UserExport struct {
+	User          ExportUser   `json:"user"`
+	Products      Products     `json:"products"`
+	Orders        ExportOrders `json:"orders"`
+	ProductOrders ExportOrders `json:"product_orders"`
+	Sessions      Sessions     `json:"sessions"`
+	Identities    Identities   `json:"identities"`
+}
+
+func (e *UserExport) ViewModel(d *domain.UserExport, currentSessionID int) {
+	e.User.ViewModel(d.User)
+	e.Products.ViewModel(d.Products)
+	e.Orders.ViewModel(d.Orders)
+	e.ProductOrders.ViewModel(d.ProductOrders)
+	e.Sessions.ViewModel(d.Sessions, currentSessionID)
+	e.Identities.ViewModel(d.Identities)
+}
+
+// Files splits the export into the files of the archive.
+func (e *UserExport) Files() map[string]interface{} {
+	return map[string]interface{}{
+		"user.json":           e.User,
+		"products.json":       e.Products,
+		"orders.json":         e.Orders,
+		"product_orders.json": e.ProductOrders,
+		"sessions.json":       e.Sessions,
+		"identities.json":     e.Identities,
+	}
+}
+
+type DeleteUserRequest struct {
+	// Password may be omitted by the users who have never set a password
+	Password string `json:"password"`
+}
@@ -0,0 +1,60 @@
+package viewmodels
+
+import (
+	"backend/internal/domain"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+)
+
+// JWK is a public key in the JSON Web Key format, see RFC 7517.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// Ed25519
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+}
+
+func (k *JWK) ViewModel(d *domain.PublicKey) bool {
+	k.KeyID = d.KID
+	k.Algorithm = d.Algorithm
+	k.Use = "sig"
+
+	switch key := d.Key.(type) {
+	case *rsa.PublicKey:
+		k.KeyType = "RSA"
+		k.N = base64.RawURLEncoding.EncodeToString(key.N.Bytes())
+		k.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes())
+	case ed25519.PublicKey:
+		k.KeyType = "OKP"
+		k.Curve = "Ed25519"
+		k.X = base64.RawURLEncoding.EncodeToString(key)
+	default:
+		return false
+	}
+
+	return true
+}
+
+type JWKS struct {
+	Keys []*JWK `json:"keys"`
+}
+
+func (s *JWKS) ViewModel(dd []*domain.PublicKey) {
+	s.Keys = make([]*JWK, 0)
+	for _, d := range dd {
+		var k JWK
+		if k.ViewModel(d) {
+			s.Keys = append(s.Keys, &k)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package viewmodels
+
+import (
+	"backend/internal/domain"
+	"time"
+)
+
+type OIDCProviders struct {
+	Providers []string `json:"providers"`
+}
+
+// AuthorizationURL is where the browser is sent to sign in at the provider,
+// the browser keeps the browser token and sends it back with the callback.
+type AuthorizationURL struct {
+	AuthorizationURL string `json:"authorization_url"`
+	BrowserToken     string `json:"browser_token"`
+}
+
+func (u *AuthorizationURL) ViewModel(d *domain.OIDCRedirect) {
+	u.AuthorizationURL = d.URL
+	u.BrowserToken = d.BrowserToken
+}
+
+type OIDCCallbackRequest struct {
+	State        string `json:"state"`
+	BrowserToken string `json:"browser_token"`
+	Code         string `json:"code"`
+}
+
+func (r *OIDCCallbackRequest) Validate() error {
+	var v validator
+	v.required("state", r.State)
+	v.required("browser_token", r.BrowserToken)
+	v.required("code", r.Code)
+
+	return v.err()
+}
+
+type Identity struct {
+	ID        int       `json:"id"`
+	Provider  string    `json:"provider"`
+	Email     *string   `json:"email,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (i *Identity) ViewModel(d *domain.Identity) {
+	i.ID = d.ID
+	i.Provider = d.Provider
+	i.Email = d.Email
+	i.CreatedAt = d.CreatedAt
+}
+
+type Identities []*Identity
+
+func (ii *Identities) ViewModel(dd []*domain.Identity) {
+	*ii = make([]*Identity, 0)
+	for _, d := range dd {
+		var i Identity
+		i.ViewModel(d)
+		*ii = append(*ii, &i)
+	}
+}
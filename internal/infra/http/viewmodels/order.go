@@ -6,21 +6,45 @@ import (
 )
 
 type Order struct {
-	OrderStart time.Time `json:"order_start"`
-	OrderEnd   time.Time `json:"order_end"`
-	User       *User     `json:"user"`
-	Product    *Product  `json:"product"`
-	Price      float64   `json:"price"`
+	ID          int        `json:"id"`
+	OrderStart  time.Time  `json:"order_start"`
+	OrderEnd    time.Time  `json:"order_end"`
+	User        *User      `json:"user"`
+	Product     *Product   `json:"product"`
+	Price       float64    `json:"price"`
+	Status      string     `json:"status"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ApprovedAt  *time.Time `json:"approved_at,omitempty"`
+	RejectedAt  *time.Time `json:"rejected_at,omitempty"`
+	ActivatedAt *time.Time `json:"activated_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	CancelledAt *time.Time `json:"cancelled_at,omitempty"`
+
+	CancelledBy  *int     `json:"cancelled_by,omitempty"`
+	RefundAmount *float64 `json:"refund_amount,omitempty"`
 }
 
 func (o *Order) ViewModel(d *domain.Order) {
+	o.ID = d.ID
 	o.OrderStart = d.OrderStart
 	o.OrderEnd = d.OrderEnd
 	o.User = &User{}
 	o.User.ViewModel(d.User)
 	o.Product = &Product{}
 	o.Product.ViewModel(d.Product)
-	o.Price = d.Price * d.Product.PerHour
+	o.Price = d.Price.Float64()
+	o.Status = string(d.Status)
+	o.CreatedAt = d.CreatedAt
+	o.ApprovedAt = d.ApprovedAt
+	o.RejectedAt = d.RejectedAt
+	o.ActivatedAt = d.ActivatedAt
+	o.CompletedAt = d.CompletedAt
+	o.CancelledAt = d.CancelledAt
+	o.CancelledBy = d.CancelledBy
+	if d.RefundAmount != nil {
+		refund := d.RefundAmount.Float64()
+		o.RefundAmount = &refund
+	}
 }
 
 type Orders []*Order
@@ -0,0 +1,55 @@
+package viewmodels
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+func (r *ForgotPasswordRequest) Validate() error {
+	var v validator
+	if v.required("email", r.Email) {
+		v.email("email", r.Email)
+	}
+
+	return v.err()
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+func (r *ResetPasswordRequest) Validate() error {
+	var v validator
+	v.required("token", r.Token)
+	if v.required("password", r.Password) {
+		v.length("password", r.Password, 8, 128)
+	}
+
+	return v.err()
+}
+
+type ChangePasswordRequest struct {
+	// CurrentPassword may be omitted by the users who have never set a password
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+func (r *ChangePasswordRequest) Validate() error {
+	var v validator
+	if v.required("new_password", r.NewPassword) {
+		v.length("new_password", r.NewPassword, 8, 128)
+	}
+
+	return v.err()
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+func (r *VerifyEmailRequest) Validate() error {
+	var v validator
+	v.required("token", r.Token)
+
+	return v.err()
+}
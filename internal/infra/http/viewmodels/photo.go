@@ -0,0 +1,84 @@
+package viewmodels
+
+import (
+	"backend/internal/domain"
+	"time"
+)
+
+type Photo struct {
+	ID          int    `json:"id"`
+	URL         string `json:"url"`
+	ContentType string `json:"content_type,omitempty"`
+	Size        int64  `json:"size,omitempty"`
+	Width       int    `json:"width,omitempty"`
+	Height      int    `json:"height,omitempty"`
+	// Blurhash and DominantColor are the placeholders shown while the photo loads
+	Blurhash      string `json:"blurhash,omitempty"`
+	DominantColor string `json:"dominant_color,omitempty"`
+	// Variants are the resized copies by name, the external photos have none
+	Variants  map[string]*PhotoVariant `json:"variants,omitempty"`
+	Position  int                      `json:"position"`
+	Cover     bool                     `json:"cover"`
+	Caption   *string                  `json:"caption,omitempty"`
+	CreatedAt time.Time                `json:"created_at"`
+}
+
+func (p *Photo) ViewModel(d *domain.Photo) {
+	p.ID = d.ID
+	p.URL = d.URL
+	p.ContentType = d.ContentType
+	p.Size = d.Size
+	p.Width = d.Width
+	p.Height = d.Height
+	p.Blurhash = d.Blurhash
+	p.DominantColor = d.DominantColor
+	if len(d.Variants) > 0 {
+		p.Variants = make(map[string]*PhotoVariant, len(d.Variants))
+		for _, v := range d.Variants {
+			var variant PhotoVariant
+			variant.ViewModel(v)
+			p.Variants[string(v.Name)] = &variant
+		}
+	}
+	p.Position = d.Position
+	p.Cover = d.Cover
+	p.Caption = d.Caption
+	p.CreatedAt = d.CreatedAt
+}
+
+type PhotoVariant struct {
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+func (v *PhotoVariant) ViewModel(d *domain.PhotoVariant) {
+	v.URL = d.URL
+	v.Width = d.Width
+	v.Height = d.Height
+}
+
+type PhotoOrderRequest struct {
+	PhotoIDs []int `json:"photo_ids"`
+}
+
+func (p *PhotoOrderRequest) Validate() error {
+	var v validator
+	v.check(len(p.PhotoIDs) > 0, "photo_ids", codeRequired, "must not be empty")
+
+	return v.err()
+}
+
+// PhotoCaptionRequest sets the caption of the photo, an empty or missing one removes it.
+type PhotoCaptionRequest struct {
+	Caption *string `json:"caption"`
+}
+
+func (p *PhotoCaptionRequest) Validate() error {
+	var v validator
+	if p.Caption != nil {
+		v.length("caption", *p.Caption, 0, 500)
+	}
+
+	return v.err()
+}
@@ -1,14 +1,26 @@
 package viewmodels
 
-import "backend/internal/domain"
+import (
+	"backend/internal/domain"
+	"time"
+)
 
 type Product struct {
 	ID          int      `json:"id"`
 	OwnerID     int      `json:"owner_id"`
 	Name        string   `json:"name"`
 	PerHour     float64  `json:"per_hour"`
+	PerDay      *float64 `json:"per_day,omitempty"`
+	PerWeek     *float64 `json:"per_week,omitempty"`
 	Description *string  `json:"description,omitempty"`
-	Photos      []string `json:"photos"`
+	// Photos is only returned, the photos are uploaded separately
+	Photos []*Photo `json:"photos"`
+
+	CancellationPolicy string     `json:"cancellation_policy"`
+	Category           string     `json:"category"`
+	ArchivedAt         *time.Time `json:"archived_at,omitempty"`
+	// Snippet is only returned by the search, it is HTML with the matched words in <mark>
+	Snippet *string `json:"snippet,omitempty"`
 }
 
 func (p *Product) Domain() *domain.Product {
@@ -17,9 +29,38 @@ func (p *Product) Domain() *domain.Product {
 		OwnerID:     p.OwnerID,
 		Name:        p.Name,
 		PerHour:     p.PerHour,
+		PerDay:      p.PerDay,
+		PerWeek:     p.PerWeek,
 		Description: p.Description,
-		Photos:      p.Photos,
+
+		CancellationPolicy: domain.CancellationPolicy(p.CancellationPolicy),
+		Category:           domain.ProductCategory(p.Category),
+	}
+}
+
+func (p *Product) Validate() error {
+	var v validator
+	if v.required("name", p.Name) {
+		v.length("name", p.Name, 1, 200)
+	}
+	v.positive("per_hour", p.PerHour)
+	if p.PerDay != nil {
+		v.positive("per_day", *p.PerDay)
 	}
+	if p.PerWeek != nil {
+		v.positive("per_week", *p.PerWeek)
+	}
+	if p.Description != nil {
+		v.length("description", *p.Description, 0, 5000)
+	}
+	if p.CancellationPolicy != "" {
+		v.check(domain.CancellationPolicy(p.CancellationPolicy).Valid(), "cancellation_policy", codeUnsupported, "is not supported")
+	}
+	if p.Category != "" {
+		v.check(domain.ProductCategory(p.Category).Valid(), "category", codeUnsupported, "is not supported")
+	}
+
+	return v.err()
 }
 
 func (p *Product) ViewModel(d *domain.Product) {
@@ -27,8 +68,19 @@ func (p *Product) ViewModel(d *domain.Product) {
 	p.OwnerID = d.OwnerID
 	p.Name = d.Name
 	p.PerHour = d.PerHour
+	p.PerDay = d.PerDay
+	p.PerWeek = d.PerWeek
 	p.Description = d.Description
-	p.Photos = d.Photos
+	p.Photos = make([]*Photo, 0, len(d.Photos))
+	for _, v := range d.Photos {
+		var photo Photo
+		photo.ViewModel(v)
+		p.Photos = append(p.Photos, &photo)
+	}
+	p.CancellationPolicy = string(d.CancellationPolicy)
+	p.Category = string(d.Category)
+	p.ArchivedAt = d.ArchivedAt
+	p.Snippet = d.Snippet
 }
 
 type Products []*Product
@@ -3,8 +3,8 @@ package viewmodels
 import "backend/internal/domain"
 
 type ProductWithUser struct {
-	Product Product `json:"product"`
-	User    User    `json:"user"`
+	Product Product    `json:"product"`
+	User    PublicUser `json:"user"`
 }
 
 func (p *ProductWithUser) ViewModel(dp *domain.Product, du *domain.User) {
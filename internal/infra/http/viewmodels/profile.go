@@ -0,0 +1,65 @@
+package viewmodels
+
+import (
+	"backend/internal/domain"
+	"time"
+)
+
+// PublicUser is the user as seen by the other users, without the contact data.
+type PublicUser struct {
+	ID          int       `json:"id"`
+	DisplayName string    `json:"display_name"`
+	Avatar      *string   `json:"avatar,omitempty"`
+	MemberSince time.Time `json:"member_since"`
+}
+
+func (u *PublicUser) ViewModel(d *domain.User) {
+	u.ID = d.ID
+	u.DisplayName = d.DisplayName()
+	u.Avatar = d.Avatar
+	u.MemberSince = d.CreatedAt
+}
+
+type RatingSummary struct {
+	Average float64 `json:"average"`
+	Count   int     `json:"count"`
+}
+
+func (r *RatingSummary) ViewModel(d *domain.RatingSummary) {
+	r.Average = d.Average
+	r.Count = d.Count
+}
+
+type PublicProfile struct {
+	PublicUser
+	Products Products      `json:"products"`
+	Rating   RatingSummary `json:"rating"`
+}
+
+func (p *PublicProfile) ViewModel(d *domain.PublicProfile) {
+	p.PublicUser.ViewModel(d.User)
+	p.Products.ViewModel(d.Products)
+	p.Rating.ViewModel(d.Rating)
+}
+
+type Review struct {
+	Rating  int     `json:"rating"`
+	Comment *string `json:"comment,omitempty"`
+}
+
+func (r *Review) Domain() *domain.Review {
+	return &domain.Review{
+		Rating:  r.Rating,
+		Comment: r.Comment,
+	}
+}
+
+func (r *Review) Validate() error {
+	var v validator
+	v.check(r.Rating >= 1 && r.Rating <= 5, "rating", codeInvalidFormat, "must be from 1 to 5")
+	if r.Comment != nil {
+		v.length("comment", *r.Comment, 0, 2000)
+	}
+
+	return v.err()
+}
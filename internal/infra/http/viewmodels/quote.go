@@ -0,0 +1,47 @@
+package viewmodels
+
+import (
+	"backend/internal/domain"
+	"time"
+)
+
+type QuoteLine struct {
+	Unit     string  `json:"unit"`
+	Quantity int     `json:"quantity"`
+	Rate     float64 `json:"rate"`
+	Amount   float64 `json:"amount"`
+}
+
+func (l *QuoteLine) ViewModel(d *domain.QuoteLine) {
+	l.Unit = string(d.Unit)
+	l.Quantity = d.Quantity
+	l.Rate = d.Rate.Float64()
+	l.Amount = d.Amount.Float64()
+}
+
+type Quote struct {
+	From            time.Time    `json:"from"`
+	To              time.Time    `json:"to"`
+	Hours           int          `json:"hours"`
+	Lines           []*QuoteLine `json:"lines"`
+	Subtotal        float64      `json:"subtotal"`
+	DiscountPercent float64      `json:"discount_percent"`
+	Discount        float64      `json:"discount"`
+	Total           float64      `json:"total"`
+}
+
+func (q *Quote) ViewModel(d *domain.Quote) {
+	q.From = d.From
+	q.To = d.To
+	q.Hours = d.Hours
+	q.Lines = make([]*QuoteLine, 0)
+	for _, v := range d.Lines {
+		var l QuoteLine
+		l.ViewModel(v)
+		q.Lines = append(q.Lines, &l)
+	}
+	q.Subtotal = d.Subtotal.Float64()
+	q.DiscountPercent = d.DiscountPercent
+	q.Discount = d.Discount.Float64()
+	q.Total = d.Total.Float64()
+}
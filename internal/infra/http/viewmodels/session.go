@@ -0,0 +1,65 @@
+package viewmodels
+
+import (
+	"backend/internal/domain"
+	"time"
+)
+
+type Tokens struct {
+	// Token duplicates AccessToken for the old clients
+	Token                 string    `json:"token"`
+	AccessToken           string    `json:"access_token"`
+	AccessTokenExpiresAt  time.Time `json:"access_token_expires_at"`
+	RefreshToken          string    `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
+}
+
+func (t *Tokens) ViewModel(d *domain.Tokens) {
+	t.Token = d.AccessToken
+	t.AccessToken = d.AccessToken
+	t.AccessTokenExpiresAt = d.AccessTokenExpiresAt
+	t.RefreshToken = d.RefreshToken
+	t.RefreshTokenExpiresAt = d.RefreshTokenExpiresAt
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+func (r *RefreshRequest) Validate() error {
+	var v validator
+	v.required("refresh_token", r.RefreshToken)
+
+	return v.err()
+}
+
+type Session struct {
+	ID         int       `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
+
+func (s *Session) ViewModel(d *domain.Session, currentID int) {
+	s.ID = d.ID
+	s.UserAgent = d.UserAgent
+	s.IP = d.IP
+	s.CreatedAt = d.CreatedAt
+	s.LastUsedAt = d.LastUsedAt
+	s.ExpiresAt = d.ExpiresAt
+	s.Current = d.ID == currentID
+}
+
+type Sessions []*Session
+
+func (ss *Sessions) ViewModel(dd []*domain.Session, currentID int) {
+	*ss = make([]*Session, 0)
+	for _, d := range dd {
+		var s Session
+		s.ViewModel(d, currentID)
+		*ss = append(*ss, &s)
+	}
+}
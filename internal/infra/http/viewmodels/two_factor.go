@@ -0,0 +1,58 @@
+package viewmodels
+
+import (
+	"backend/internal/domain"
+	"encoding/base32"
+	"time"
+)
+
+type Challenge struct {
+	TwoFactorRequired bool      `json:"two_factor_required"`
+	ChallengeToken    string    `json:"challenge_token"`
+	ExpiresAt         time.Time `json:"expires_at"`
+}
+
+func (c *Challenge) ViewModel(d *domain.Challenge) {
+	c.TwoFactorRequired = true
+	c.ChallengeToken = d.Token
+	c.ExpiresAt = d.ExpiresAt
+}
+
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+}
+
+func (r *TwoFactorLoginRequest) Validate() error {
+	var v validator
+	v.required("challenge_token", r.ChallengeToken)
+	v.required("code", r.Code)
+
+	return v.err()
+}
+
+type TwoFactorEnrollment struct {
+	// Secret is the base32 encoded secret for entering into the authenticator app manually
+	Secret string `json:"secret"`
+	KeyURI string `json:"otpauth_uri"`
+}
+
+func (e *TwoFactorEnrollment) ViewModel(d *domain.TwoFactorEnrollment) {
+	e.Secret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(d.Secret)
+	e.KeyURI = d.KeyURI
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code"`
+}
+
+func (r *TwoFactorCodeRequest) Validate() error {
+	var v validator
+	v.required("code", r.Code)
+
+	return v.err()
+}
+
+type RecoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
@@ -1,6 +1,9 @@
 package viewmodels
 
-import "backend/internal/domain"
+import (
+	"backend/internal/domain"
+	"time"
+)
 
 type User struct {
 	FirstName string  `json:"first_name"`
@@ -8,15 +11,56 @@ type User struct {
 	Login     string  `json:"login"`
 	Email     string  `json:"email"`
 	Password  *string `json:"password,omitempty"`
+	Language  *string `json:"language,omitempty"`
+	// EmailVerifiedAt is only returned, it is ignored in the requests
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	Avatar          *string    `json:"avatar,omitempty"`
 }
 
 func (u *User) Domain() *domain.User {
-	return &domain.User{
+	d := &domain.User{
 		FirstName: u.FirstName,
 		LastName:  u.LastName,
 		Login:     u.Login,
 		Email:     u.Email,
+		Language:  u.Language,
+	}
+
+	if u.Password != nil {
+		d.Password = *u.Password
+	}
+
+	return d
+}
+
+func (u *User) ValidateRegistration() error {
+	var v validator
+	if v.required("login", u.Login) && v.length("login", u.Login, 3, 64) {
+		v.check(loginRegexp.MatchString(u.Login), "login", codeInvalidFormat, "may contain only latin letters, digits, '.', '_' and '-'")
+	}
+	if v.required("first_name", u.FirstName) {
+		v.length("first_name", u.FirstName, 1, 100)
 	}
+	if v.required("last_name", u.LastName) {
+		v.length("last_name", u.LastName, 1, 100)
+	}
+	if v.required("email", u.Email) {
+		v.email("email", u.Email)
+	}
+	if v.check(u.Password != nil, "password", codeRequired, "must not be empty") {
+		v.length("password", *u.Password, 8, 128)
+	}
+	v.language("language", u.Language)
+
+	return v.err()
+}
+
+func (u *User) ValidateLogin() error {
+	var v validator
+	v.required("login", u.Login)
+	v.check(u.Password != nil && *u.Password != "", "password", codeRequired, "must not be empty")
+
+	return v.err()
 }
 
 func (u *User) ViewModel(d *domain.User) {
@@ -24,4 +68,47 @@ func (u *User) ViewModel(d *domain.User) {
 	u.LastName = d.LastName
 	u.Login = d.Login
 	u.Email = d.Email
+	u.Language = d.Language
+	u.EmailVerifiedAt = d.EmailVerifiedAt
+	u.Avatar = d.Avatar
+}
+
+// Profile holds the user fields the user can change, the avatar is uploaded separately.
+type Profile struct {
+	FirstName string  `json:"first_name"`
+	LastName  string  `json:"last_name"`
+	Email     string  `json:"email"`
+	Language  *string `json:"language"`
+}
+
+func (p *Profile) ViewModel(d *domain.User) {
+	p.FirstName = d.FirstName
+	p.LastName = d.LastName
+	p.Email = d.Email
+	p.Language = d.Language
+}
+
+func (p *Profile) Domain() *domain.User {
+	return &domain.User{
+		FirstName: p.FirstName,
+		LastName:  p.LastName,
+		Email:     p.Email,
+		Language:  p.Language,
+	}
+}
+
+func (p *Profile) Validate() error {
+	var v validator
+	if v.required("first_name", p.FirstName) {
+		v.length("first_name", p.FirstName, 1, 100)
+	}
+	if v.required("last_name", p.LastName) {
+		v.length("last_name", p.LastName, 1, 100)
+	}
+	if v.required("email", p.Email) {
+		v.email("email", p.Email)
+	}
+	v.language("language", p.Language)
+
+	return v.err()
 }
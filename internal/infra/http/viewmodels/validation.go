@@ -0,0 +1,91 @@
+package viewmodels
+
+import (
+	"backend/internal/domain"
+	"backend/internal/i18n"
+	"net/mail"
+	"regexp"
+	"time"
+	"unicode/utf8"
+)
+
+// Field error codes.
+const (
+	codeRequired      = "required"
+	codeTooShort      = "too_short"
+	codeTooLong       = "too_long"
+	codeInvalidFormat = "invalid_format"
+	codeNotPositive   = "not_positive"
+	codeNotAfter      = "not_after"
+	codeUnsupported   = "unsupported"
+)
+
+var loginRegexp = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
+
+// validator collects the field errors so that all of them are reported at once.
+type validator struct {
+	fields []domain.FieldError
+}
+
+func (v *validator) check(ok bool, field, code, message string) bool {
+	if !ok {
+		v.fields = append(v.fields, domain.FieldError{
+			Field:   field,
+			Code:    code,
+			Message: message,
+		})
+	}
+
+	return ok
+}
+
+func (v *validator) required(field, value string) bool {
+	return v.check(value != "", field, codeRequired, "must not be empty")
+}
+
+func (v *validator) length(field, value string, min, max int) bool {
+	n := utf8.RuneCountInString(value)
+	return v.check(n >= min, field, codeTooShort, "is too short") &&
+		v.check(n <= max, field, codeTooLong, "is too long")
+}
+
+func (v *validator) positive(field string, value float64) bool {
+	return v.check(value > 0, field, codeNotPositive, "must be positive")
+}
+
+func (v *validator) after(field string, value, than time.Time) bool {
+	return v.check(value.After(than), field, codeNotAfter, "must be later")
+}
+
+func (v *validator) email(field, value string) bool {
+	address, err := mail.ParseAddress(value)
+	return v.check(err == nil && address.Address == value, field, codeInvalidFormat, "is not a valid email")
+}
+
+func (v *validator) language(field string, value *string) bool {
+	if value == nil {
+		return true
+	}
+
+	return v.check(i18n.Supported(i18n.Language(*value)), field, codeUnsupported, "is not supported")
+}
+
+// err returns the validation error, or nil if all fields are valid.
+func (v *validator) err() error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+
+	return domain.NewValidationError(v.fields...)
+}
+
+// ValidatePeriod checks the rental period passed in the query params.
+func ValidatePeriod(from, to time.Time) error {
+	var v validator
+	v.check(!from.IsZero(), "from", codeRequired, "must not be empty")
+	if v.check(!to.IsZero(), "to", codeRequired, "must not be empty") && !from.IsZero() {
+		v.after("to", to, from)
+	}
+
+	return v.err()
+}
@@ -0,0 +1,144 @@
+package imaging
+
+import (
+	"backend/internal/domain"
+	"bytes"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+)
+
+// placeholderSize is the size of the downscaled copy the placeholders are computed of.
+const placeholderSize = 32
+
+type adapter struct {
+	logger   logrus.FieldLogger
+	config   *Config
+	variants map[domain.PhotoVariantName]int
+}
+
+func NewAdapter(logger logrus.FieldLogger, config *Config) (domain.ImageProcessor, error) {
+	if config.JPEGQuality < 1 || config.JPEGQuality > 100 {
+		return nil, errors.New("jpeg quality must be from 1 to 100")
+	}
+
+	variants := map[domain.PhotoVariantName]int{
+		domain.PhotoVariantThumbnail: config.ThumbnailSize,
+		domain.PhotoVariantCard:      config.CardSize,
+		domain.PhotoVariantFull:      config.FullSize,
+	}
+	for name, size := range variants {
+		if size <= 0 {
+			return nil, errors.New("size of the " + string(name) + " variant must be positive")
+		}
+	}
+
+	return &adapter{
+		logger:   logger,
+		config:   config,
+		variants: variants,
+	}, nil
+}
+
+func (a *adapter) ProcessPhoto(data []byte) (*domain.ProcessedPhoto, error) {
+	// the dimensions are checked before decoding, a small file may claim a huge image
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		a.logger.WithError(err).Error("Error while decoding photo config!")
+		return nil, domain.ErrUnsupportedPhotoType
+	}
+
+	if format != "jpeg" && format != "png" {
+		return nil, domain.ErrUnsupportedPhotoType
+	}
+
+	if cfg.Width*cfg.Height > a.config.MaxPixels {
+		return nil, domain.ErrPhotoTooLarge
+	}
+
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		a.logger.WithError(err).Error("Error while decoding photo!")
+		return nil, domain.ErrUnsupportedPhotoType
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, cfg.Width, cfg.Height))
+	draw.Draw(img, img.Bounds(), decoded, decoded.Bounds().Min, draw.Src)
+
+	var original []byte
+	orientation := 1
+	if format == "jpeg" {
+		orientation = jpegOrientation(data)
+		original, err = stripJPEG(data)
+	} else {
+		original, err = stripPNG(data)
+	}
+	if err != nil {
+		a.logger.WithError(err).Error("Error while stripping photo metadata!")
+		return nil, domain.ErrUnsupportedPhotoType
+	}
+
+	// the orientation is lost along with the metadata, so the pixels are turned instead
+	if orientation != 1 {
+		img = orient(img, orientation)
+		if original, err = a.encode(img, format); err != nil {
+			return nil, err
+		}
+	}
+
+	bounds := img.Bounds()
+	res := &domain.ProcessedPhoto{
+		Original: &domain.Image{
+			ContentType: contentType(format),
+			Data:        original,
+			Width:       bounds.Dx(),
+			Height:      bounds.Dy(),
+		},
+		Variants: make(map[domain.PhotoVariantName]*domain.Image, len(a.variants)),
+	}
+
+	for name, size := range a.variants {
+		resized := fit(img, size)
+		data, err := a.encode(resized, format)
+		if err != nil {
+			return nil, err
+		}
+
+		res.Variants[name] = &domain.Image{
+			ContentType: contentType(format),
+			Data:        data,
+			Width:       resized.Bounds().Dx(),
+			Height:      resized.Bounds().Dy(),
+		}
+	}
+
+	small := fit(img, placeholderSize)
+	res.Blurhash = blurhash(small, 4, 3)
+	res.DominantColor = dominantColor(small)
+
+	return res, nil
+}
+
+// encode encodes the image in the format of the uploaded photo, so that the transparency of PNG is kept.
+func (a *adapter) encode(img image.Image, format string) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	if format == "png" {
+		err = png.Encode(&buf, img)
+	} else {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: a.config.JPEGQuality})
+	}
+	if err != nil {
+		a.logger.WithError(err).Error("Error while encoding photo!")
+		return nil, domain.ErrInternal
+	}
+
+	return buf.Bytes(), nil
+}
+
+func contentType(format string) string {
+	return "image/" + format
+}
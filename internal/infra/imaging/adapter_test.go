@@ -0,0 +1,165 @@
+package imaging
+
+import (
+	"backend/internal/domain"
+	"bytes"
+	"encoding/binary"
+	"github.com/sirupsen/logrus"
+	"image"
+	"image/gif"
+	"io/ioutil"
+	"testing"
+)
+
+func newTestAdapter(t *testing.T) domain.ImageProcessor {
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+
+	a, err := NewAdapter(logger, &Config{
+		ThumbnailSize: 20,
+		CardSize:      50,
+		FullSize:      100,
+		JPEGQuality:   85,
+		MaxPixels:     100000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func TestProcessPhoto(t *testing.T) {
+	a := newTestAdapter(t)
+	rotated := withJPEGSegments(testJPEG(t, 200, 100), jpegSegment(0xe1, exifPayload(binary.BigEndian, 6)))
+
+	type size struct{ w, h int }
+	tests := []struct {
+		name         string
+		data         []byte
+		contentType  string
+		wantOriginal size
+		wantVariants map[domain.PhotoVariantName]size
+	}{
+		{
+			name:         "PNG",
+			data:         testPNG(t, 200, 100),
+			contentType:  "image/png",
+			wantOriginal: size{200, 100},
+			wantVariants: map[domain.PhotoVariantName]size{
+				domain.PhotoVariantThumbnail: {20, 10},
+				domain.PhotoVariantCard:      {50, 25},
+				domain.PhotoVariantFull:      {100, 50},
+			},
+		},
+		{
+			name:         "JPEG",
+			data:         testJPEG(t, 100, 200),
+			contentType:  "image/jpeg",
+			wantOriginal: size{100, 200},
+			wantVariants: map[domain.PhotoVariantName]size{
+				domain.PhotoVariantThumbnail: {10, 20},
+				domain.PhotoVariantCard:      {25, 50},
+				domain.PhotoVariantFull:      {50, 100},
+			},
+		},
+		{
+			name:         "rotated JPEG",
+			data:         rotated,
+			contentType:  "image/jpeg",
+			wantOriginal: size{100, 200},
+			wantVariants: map[domain.PhotoVariantName]size{
+				domain.PhotoVariantThumbnail: {10, 20},
+				domain.PhotoVariantCard:      {25, 50},
+				domain.PhotoVariantFull:      {50, 100},
+			},
+		},
+		{
+			name:         "small PNG",
+			data:         testPNG(t, 40, 10),
+			contentType:  "image/png",
+			wantOriginal: size{40, 10},
+			wantVariants: map[domain.PhotoVariantName]size{
+				domain.PhotoVariantThumbnail: {20, 5},
+				domain.PhotoVariantCard:      {40, 10},
+				domain.PhotoVariantFull:      {40, 10},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := a.ProcessPhoto(tt.data)
+			if err != nil {
+				t.Fatalf("ProcessPhoto() error = %v", err)
+			}
+
+			images := map[string]*domain.Image{"original": res.Original}
+			wants := map[string]size{"original": tt.wantOriginal}
+			for name, want := range tt.wantVariants {
+				images[string(name)] = res.Variants[name]
+				wants[string(name)] = want
+			}
+			if len(res.Variants) != len(tt.wantVariants) {
+				t.Errorf("got %d variants, want %d", len(res.Variants), len(tt.wantVariants))
+			}
+
+			for name, img := range images {
+				want := wants[name]
+				if img == nil {
+					t.Errorf("%s is missing", name)
+					continue
+				}
+				if img.ContentType != tt.contentType {
+					t.Errorf("%s content type = %s, want %s", name, img.ContentType, tt.contentType)
+				}
+				if img.Width != want.w || img.Height != want.h {
+					t.Errorf("%s = %dx%d, want %dx%d", name, img.Width, img.Height, want.w, want.h)
+				}
+
+				// the encoded data matches the reported size
+				cfg, _, err := image.DecodeConfig(bytes.NewReader(img.Data))
+				if err != nil {
+					t.Errorf("%s does not decode: %v", name, err)
+				} else if cfg.Width != want.w || cfg.Height != want.h {
+					t.Errorf("%s is encoded as %dx%d, want %dx%d", name, cfg.Width, cfg.Height, want.w, want.h)
+				}
+				if bytes.Contains(img.Data, []byte("Exif")) {
+					t.Errorf("%s kept the EXIF metadata", name)
+				}
+			}
+
+			if len(res.Blurhash) != 28 {
+				t.Errorf("blurhash = %q, want 28 characters", res.Blurhash)
+			}
+			if len(res.DominantColor) != 7 {
+				t.Errorf("dominant color = %q, want #rrggbb", res.DominantColor)
+			}
+		})
+	}
+}
+
+func TestProcessPhotoRejected(t *testing.T) {
+	a := newTestAdapter(t)
+
+	var gifData bytes.Buffer
+	if err := gif.Encode(&gifData, testImage(10, 10), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{name: "too many pixels", data: testPNG(t, 400, 300), want: domain.ErrPhotoTooLarge},
+		{name: "GIF", data: gifData.Bytes(), want: domain.ErrUnsupportedPhotoType},
+		{name: "not an image", data: []byte("not an image"), want: domain.ErrUnsupportedPhotoType},
+		{name: "truncated", data: testPNG(t, 100, 100)[:60], want: domain.ErrUnsupportedPhotoType},
+	}
+
+	for _, tt := range tests {
+		if _, err := a.ProcessPhoto(tt.data); err != tt.want {
+			t.Errorf("ProcessPhoto(%s) error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
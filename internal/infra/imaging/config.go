@@ -0,0 +1,9 @@
+package imaging
+
+type Config struct {
+	ThumbnailSize int `long:"thumbnail-size" env:"THUMBNAIL_SIZE" description:"Max width and height of the thumbnail variant of the photos" default:"200"`
+	CardSize      int `long:"card-size" env:"CARD_SIZE" description:"Max width and height of the card variant of the photos" default:"600"`
+	FullSize      int `long:"full-size" env:"FULL_SIZE" description:"Max width and height of the full variant of the photos" default:"1600"`
+	JPEGQuality   int `long:"jpeg-quality" env:"JPEG_QUALITY" description:"Quality of the re-encoded JPEG photos, from 1 to 100" default:"85"`
+	MaxPixels     int `long:"max-pixels" env:"MAX_PIXELS" description:"Max number of pixels of an uploaded photo, the larger ones are not decoded" default:"25000000"`
+}
@@ -0,0 +1,173 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+var errMalformed = errors.New("malformed image")
+
+// stripJPEG drops the metadata segments of the JPEG file without re-encoding it.
+// JFIF and the ICC colour profile are kept, EXIF, XMP, IPTC and the comments are dropped.
+func stripJPEG(data []byte) ([]byte, error) {
+	if len(data) < 2 || data[0] != 0xff || data[1] != 0xd8 {
+		return nil, errMalformed
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:2]...)
+
+	for i := 2; ; {
+		// the markers may be padded with fill bytes
+		for i+1 < len(data) && data[i] == 0xff && data[i+1] == 0xff {
+			i++
+		}
+		if i+1 >= len(data) || data[i] != 0xff {
+			return nil, errMalformed
+		}
+		marker := data[i+1]
+
+		// the entropy coded data follows the start of scan, it is copied as is
+		if marker == 0xda {
+			return append(out, data[i:]...), nil
+		}
+
+		// the standalone markers have no length
+		if marker == 0x01 || (marker >= 0xd0 && marker <= 0xd7) {
+			out = append(out, data[i:i+2]...)
+			i += 2
+			continue
+		}
+
+		if i+4 > len(data) {
+			return nil, errMalformed
+		}
+		end := i + 2 + int(binary.BigEndian.Uint16(data[i+2:]))
+		if end > len(data) || end < i+4 {
+			return nil, errMalformed
+		}
+
+		if keepJPEGSegment(marker, data[i+4:end]) {
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+}
+
+func keepJPEGSegment(marker byte, payload []byte) bool {
+	switch {
+	case marker == 0xe0:
+		return bytes.HasPrefix(payload, []byte("JFIF\x00"))
+	case marker == 0xe2:
+		return bytes.HasPrefix(payload, []byte("ICC_PROFILE\x00"))
+	case marker == 0xee:
+		// the Adobe segment tells how the colours are transformed
+		return bytes.HasPrefix(payload, []byte("Adobe"))
+	case marker > 0xe0 && marker <= 0xef, marker == 0xfe:
+		return false
+	}
+
+	return true
+}
+
+// pngMetadataChunks are the chunks dropped from the PNG files.
+var pngMetadataChunks = map[string]bool{
+	"eXIf": true,
+	"tEXt": true,
+	"zTXt": true,
+	"iTXt": true,
+	"tIME": true,
+}
+
+// stripPNG drops the metadata chunks of the PNG file without re-encoding it.
+func stripPNG(data []byte) ([]byte, error) {
+	const signature = "\x89PNG\r\n\x1a\n"
+	if !bytes.HasPrefix(data, []byte(signature)) {
+		return nil, errMalformed
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, signature...)
+
+	for i := len(signature); i < len(data); {
+		// length, type, data and crc
+		if i+8 > len(data) {
+			return nil, errMalformed
+		}
+		end := i + 12 + int(binary.BigEndian.Uint32(data[i:]))
+		if end > len(data) || end < i+12 {
+			return nil, errMalformed
+		}
+
+		if !pngMetadataChunks[string(data[i+4:i+8])] {
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+
+	return out, nil
+}
+
+// jpegOrientation returns the EXIF orientation of the JPEG file, 1 if there is none.
+func jpegOrientation(data []byte) int {
+	for i := 2; i+4 <= len(data) && data[i] == 0xff; {
+		marker := data[i+1]
+		if marker == 0xda {
+			break
+		}
+
+		end := i + 2 + int(binary.BigEndian.Uint16(data[i+2:]))
+		if end > len(data) || end < i+4 {
+			break
+		}
+
+		payload := data[i+4 : end]
+		if marker == 0xe1 && bytes.HasPrefix(payload, []byte("Exif\x00\x00")) {
+			return exifOrientation(payload[6:])
+		}
+		i = end
+	}
+
+	return 1
+}
+
+// exifOrientation reads the orientation tag of the first IFD of the TIFF structure.
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	offset := int(order.Uint32(tiff[4:]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return 1
+	}
+
+	count := int(order.Uint16(tiff[offset:]))
+	for n := 0; n < count; n++ {
+		entry := offset + 2 + n*12
+		if entry+12 > len(tiff) {
+			break
+		}
+
+		// the orientation is a single SHORT stored in the value field
+		if order.Uint16(tiff[entry:]) == 0x0112 && order.Uint16(tiff[entry+2:]) == 3 {
+			if v := int(order.Uint16(tiff[entry+8:])); v >= 1 && v <= 8 {
+				return v
+			}
+			break
+		}
+	}
+
+	return 1
+}
@@ -0,0 +1,235 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func testImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x * 255 / w), G: uint8(y * 255 / h), B: 128, A: 255})
+		}
+	}
+	return img
+}
+
+func testJPEG(t *testing.T, w, h int) []byte {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, testImage(w, h), nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func testPNG(t *testing.T, w, h int) []byte {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, testImage(w, h)); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func jpegSegment(marker byte, payload string) []byte {
+	segment := []byte{0xff, marker, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+func pngChunk(typ, data string) []byte {
+	chunk := make([]byte, 4, 12+len(data))
+	binary.BigEndian.PutUint32(chunk, uint32(len(data)))
+	chunk = append(chunk, typ...)
+	chunk = append(chunk, data...)
+	crc := make([]byte, 4)
+	binary.BigEndian.PutUint32(crc, crc32.ChecksumIEEE([]byte(typ+data)))
+	return append(chunk, crc...)
+}
+
+// exifPayload returns an APP1 EXIF payload with the orientation tag as the only entry of the first IFD.
+func exifPayload(order binary.ByteOrder, orientation int) string {
+	tiff := make([]byte, 8+2+12+4)
+	if order == binary.LittleEndian {
+		copy(tiff, "II")
+	} else {
+		copy(tiff, "MM")
+	}
+	order.PutUint16(tiff[2:], 42)
+	order.PutUint32(tiff[4:], 8)
+	order.PutUint16(tiff[8:], 1)
+	order.PutUint16(tiff[10:], 0x0112)
+	order.PutUint16(tiff[12:], 3)
+	order.PutUint32(tiff[14:], 1)
+	order.PutUint16(tiff[18:], uint16(orientation))
+	return "Exif\x00\x00" + string(tiff)
+}
+
+// withJPEGSegments inserts the segments right after the start of image marker.
+func withJPEGSegments(data []byte, segments ...[]byte) []byte {
+	out := append([]byte{}, data[:2]...)
+	for _, segment := range segments {
+		out = append(out, segment...)
+	}
+	return append(out, data[2:]...)
+}
+
+// withPNGChunks inserts the chunks right after the IHDR chunk.
+func withPNGChunks(data []byte, chunks ...[]byte) []byte {
+	const ihdrEnd = 8 + 12 + 13
+	out := append([]byte{}, data[:ihdrEnd]...)
+	for _, chunk := range chunks {
+		out = append(out, chunk...)
+	}
+	return append(out, data[ihdrEnd:]...)
+}
+
+func TestStripJPEG(t *testing.T) {
+	kept := [][]byte{
+		jpegSegment(0xe0, "JFIF\x00\x01\x01\x00\x00\x01\x00\x01\x00\x00"),
+		jpegSegment(0xe2, "ICC_PROFILE\x00\x01\x01profile"),
+		jpegSegment(0xee, "Adobe\x00\x64\x00\x00\x00\x00\x01"),
+	}
+	dropped := [][]byte{
+		jpegSegment(0xe1, exifPayload(binary.BigEndian, 6)),
+		jpegSegment(0xe1, "http://ns.adobe.com/xap/1.0/\x00<x:xmpmeta>secret</x:xmpmeta>"),
+		jpegSegment(0xed, "Photoshop 3.0\x00secret"),
+		jpegSegment(0xe0, "JFXX\x00secret thumbnail"),
+		jpegSegment(0xfe, "secret comment"),
+	}
+
+	plain := testJPEG(t, 16, 8)
+	data := withJPEGSegments(plain, kept[0], dropped[0], dropped[1], kept[1], dropped[2], dropped[3], kept[2], dropped[4])
+
+	got, err := stripJPEG(data)
+	if err != nil {
+		t.Fatalf("stripJPEG() error = %v", err)
+	}
+
+	if want := withJPEGSegments(plain, kept...); !bytes.Equal(got, want) {
+		t.Errorf("stripJPEG() kept %d bytes, want %d", len(got), len(want))
+	}
+	if bytes.Contains(got, []byte("secret")) || bytes.Contains(got, []byte("Exif")) {
+		t.Error("stripJPEG() kept the metadata")
+	}
+	if _, err := jpeg.Decode(bytes.NewReader(got)); err != nil {
+		t.Errorf("stripped JPEG does not decode: %v", err)
+	}
+}
+
+func TestStripPNG(t *testing.T) {
+	plain := testPNG(t, 16, 8)
+	kept := pngChunk("gAMA", "\x00\x00\xb1\x8f")
+	data := withPNGChunks(plain,
+		pngChunk("eXIf", "MM\x00\x2asecret"),
+		pngChunk("tEXt", "Comment\x00secret"),
+		kept,
+		pngChunk("zTXt", "Comment\x00\x00secret"),
+		pngChunk("iTXt", "Comment\x00\x00\x00\x00\x00secret"),
+		pngChunk("tIME", "\x07\xe5\x06\x01\x0c\x00\x00"),
+	)
+
+	got, err := stripPNG(data)
+	if err != nil {
+		t.Fatalf("stripPNG() error = %v", err)
+	}
+
+	if want := withPNGChunks(plain, kept); !bytes.Equal(got, want) {
+		t.Errorf("stripPNG() kept %d bytes, want %d", len(got), len(want))
+	}
+	if bytes.Contains(got, []byte("secret")) {
+		t.Error("stripPNG() kept the metadata")
+	}
+	if !bytes.Contains(got, []byte("IDAT")) {
+		t.Error("stripPNG() dropped the image data")
+	}
+	if _, err := png.Decode(bytes.NewReader(got)); err != nil {
+		t.Errorf("stripped PNG does not decode: %v", err)
+	}
+}
+
+func TestStripMalformed(t *testing.T) {
+	plainJPEG := testJPEG(t, 8, 8)
+	plainPNG := testPNG(t, 8, 8)
+
+	jpegs := []struct {
+		name string
+		data []byte
+	}{
+		{name: "empty", data: nil},
+		{name: "no start of image", data: plainPNG},
+		{name: "only start of image", data: plainJPEG[:2]},
+		{name: "truncated marker", data: plainJPEG[:3]},
+		{name: "truncated length", data: append([]byte{0xff, 0xd8, 0xff, 0xe1}, 0x00)},
+		{name: "length shorter than itself", data: withJPEGSegments(plainJPEG, []byte{0xff, 0xe1, 0x00, 0x01})},
+		{name: "length past the end", data: append([]byte{0xff, 0xd8}, jpegSegment(0xe1, "Exif\x00\x00")[:6]...)},
+		{name: "no start of scan", data: append([]byte{0xff, 0xd8}, jpegSegment(0xe0, "JFIF\x00")...)},
+		{name: "garbage between segments", data: withJPEGSegments(plainJPEG, []byte{0x00, 0x01})},
+	}
+
+	for _, tt := range jpegs {
+		if _, err := stripJPEG(tt.data); err != errMalformed {
+			t.Errorf("stripJPEG(%s) error = %v, want %v", tt.name, err, errMalformed)
+		}
+		// the orientation is read before the file is stripped, so it must not panic on the same input
+		if got := jpegOrientation(tt.data); got != 1 {
+			t.Errorf("jpegOrientation(%s) = %d, want 1", tt.name, got)
+		}
+	}
+
+	huge := pngChunk("tEXt", "")
+	binary.BigEndian.PutUint32(huge, 0xffffffff)
+
+	pngs := []struct {
+		name string
+		data []byte
+	}{
+		{name: "empty", data: nil},
+		{name: "no signature", data: plainJPEG},
+		{name: "truncated chunk header", data: plainPNG[:8+6]},
+		{name: "truncated chunk", data: plainPNG[:len(plainPNG)-2]},
+		{name: "length past the end", data: withPNGChunks(plainPNG, huge)},
+	}
+
+	for _, tt := range pngs {
+		if _, err := stripPNG(tt.data); err != errMalformed {
+			t.Errorf("stripPNG(%s) error = %v, want %v", tt.name, err, errMalformed)
+		}
+	}
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	plain := testJPEG(t, 8, 8)
+	truncated := exifPayload(binary.BigEndian, 6)
+	truncated = truncated[:len(truncated)-8]
+
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{name: "no EXIF", data: plain, want: 1},
+		{name: "big endian", data: withJPEGSegments(plain, jpegSegment(0xe1, exifPayload(binary.BigEndian, 6))), want: 6},
+		{name: "little endian", data: withJPEGSegments(plain, jpegSegment(0xe1, exifPayload(binary.LittleEndian, 8))), want: 8},
+		{
+			name: "after JFIF",
+			data: withJPEGSegments(plain, jpegSegment(0xe0, "JFIF\x00"), jpegSegment(0xe1, exifPayload(binary.BigEndian, 3))),
+			want: 3,
+		},
+		{name: "out of range", data: withJPEGSegments(plain, jpegSegment(0xe1, exifPayload(binary.BigEndian, 9))), want: 1},
+		{name: "truncated IFD", data: withJPEGSegments(plain, jpegSegment(0xe1, truncated)), want: 1},
+		{name: "unknown byte order", data: withJPEGSegments(plain, jpegSegment(0xe1, "Exif\x00\x00XX\x00\x2a\x00\x00\x00\x08")), want: 1},
+	}
+
+	for _, tt := range tests {
+		if got := jpegOrientation(tt.data); got != tt.want {
+			t.Errorf("jpegOrientation(%s) = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
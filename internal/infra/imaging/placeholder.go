@@ -0,0 +1,137 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"strings"
+)
+
+const base83Chars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz#$%*+,-.:;=?@[\\]^_{|}~"
+
+// blurhash encodes the image as a BlurHash (https://blurha.sh) of the number of components along the axes.
+// The image should be small, every component sums up all of its pixels.
+func blurhash(img *image.RGBA, xComponents, yComponents int) string {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+
+	factors := make([][3]float64, 0, xComponents*yComponents)
+	for j := 0; j < yComponents; j++ {
+		for i := 0; i < xComponents; i++ {
+			normalisation := 2.0
+			if i == 0 && j == 0 {
+				normalisation = 1
+			}
+
+			var f [3]float64
+			for y := 0; y < h; y++ {
+				for x := 0; x < w; x++ {
+					basis := normalisation *
+						math.Cos(math.Pi*float64(i)*float64(x)/float64(w)) *
+						math.Cos(math.Pi*float64(j)*float64(y)/float64(h))
+
+					o := img.PixOffset(x, y)
+					f[0] += basis * srgbToLinear(img.Pix[o])
+					f[1] += basis * srgbToLinear(img.Pix[o+1])
+					f[2] += basis * srgbToLinear(img.Pix[o+2])
+				}
+			}
+
+			scale := 1 / float64(w*h)
+			factors = append(factors, [3]float64{f[0] * scale, f[1] * scale, f[2] * scale})
+		}
+	}
+
+	var sb strings.Builder
+	writeBase83(&sb, (xComponents-1)+(yComponents-1)*9, 1)
+
+	dc, ac := factors[0], factors[1:]
+
+	maxValue := 1.0
+	if len(ac) > 0 {
+		var actualMax float64
+		for _, f := range ac {
+			actualMax = math.Max(actualMax, math.Max(math.Abs(f[0]), math.Max(math.Abs(f[1]), math.Abs(f[2]))))
+		}
+
+		quantisedMax := int(math.Max(0, math.Min(82, math.Floor(actualMax*166-0.5))))
+		maxValue = float64(quantisedMax+1) / 166
+		writeBase83(&sb, quantisedMax, 1)
+	} else {
+		writeBase83(&sb, 0, 1)
+	}
+
+	writeBase83(&sb, linearToSRGB(dc[0])<<16+linearToSRGB(dc[1])<<8+linearToSRGB(dc[2]), 4)
+
+	for _, f := range ac {
+		quant := func(v float64) int {
+			return int(math.Max(0, math.Min(18, math.Floor(signPow(v/maxValue, 0.5)*9+9.5))))
+		}
+		writeBase83(&sb, quant(f[0])*19*19+quant(f[1])*19+quant(f[2]), 2)
+	}
+
+	return sb.String()
+}
+
+func writeBase83(sb *strings.Builder, value, length int) {
+	for i := 1; i <= length; i++ {
+		digit := value / int(math.Pow(83, float64(length-i))) % 83
+		sb.WriteByte(base83Chars[digit])
+	}
+}
+
+func srgbToLinear(v uint8) float64 {
+	f := float64(v) / 255
+	if f <= 0.04045 {
+		return f / 12.92
+	}
+	return math.Pow((f+0.055)/1.055, 2.4)
+}
+
+func linearToSRGB(v float64) int {
+	v = math.Max(0, math.Min(1, v))
+	if v <= 0.0031308 {
+		return int(v*12.92*255 + 0.5)
+	}
+	return int((1.055*math.Pow(v, 1/2.4)-0.055)*255 + 0.5)
+}
+
+func signPow(v, exp float64) float64 {
+	return math.Copysign(math.Pow(math.Abs(v), exp), v)
+}
+
+// dominantColor returns the average colour of the most common colour bucket of the image as a hex string.
+// The mostly transparent pixels are skipped, the image is expected to be small.
+func dominantColor(img *image.RGBA) string {
+	type bucket struct {
+		count   int
+		r, g, b int
+	}
+
+	var buckets [4096]bucket
+	best := -1
+	for i := 0; i+3 < len(img.Pix); i += 4 {
+		a := int(img.Pix[i+3])
+		if a < 128 {
+			continue
+		}
+
+		// the pixels are premultiplied
+		r, g, b := int(img.Pix[i])*255/a, int(img.Pix[i+1])*255/a, int(img.Pix[i+2])*255/a
+		k := r>>4<<8 | g>>4<<4 | b>>4
+		buckets[k].count++
+		buckets[k].r += r
+		buckets[k].g += g
+		buckets[k].b += b
+
+		if best < 0 || buckets[k].count > buckets[best].count {
+			best = k
+		}
+	}
+
+	if best < 0 {
+		return ""
+	}
+
+	v := buckets[best]
+	return fmt.Sprintf("#%02x%02x%02x", v.r/v.count, v.g/v.count, v.b/v.count)
+}
@@ -0,0 +1,62 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func filled(w, h int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestBlurhash(t *testing.T) {
+	hash := blurhash(filled(8, 8, color.RGBA{R: 200, G: 100, B: 50, A: 255}), 4, 3)
+
+	// the size flag, the max AC value, the DC colour and 11 AC components of 2 characters
+	if len(hash) != 1+1+4+11*2 {
+		t.Fatalf("len(blurhash()) = %d, want 28", len(hash))
+	}
+	if hash[0] != base83Chars[3+2*9] {
+		t.Errorf("size flag = %c, want %c", hash[0], base83Chars[3+2*9])
+	}
+
+	var dc strings.Builder
+	writeBase83(&dc, 200<<16+100<<8+50, 4)
+	if hash[2:6] != dc.String() {
+		t.Errorf("DC = %s, want %s", hash[2:6], dc.String())
+	}
+}
+
+func TestDominantColor(t *testing.T) {
+	mixed := filled(4, 4, color.RGBA{R: 200, G: 0, B: 0, A: 255})
+	mixed.SetRGBA(0, 0, color.RGBA{B: 200, A: 255})
+	mixed.SetRGBA(1, 0, color.RGBA{B: 200, A: 255})
+
+	translucent := filled(4, 4, color.RGBA{R: 100, A: 100})
+	translucent.SetRGBA(0, 0, color.RGBA{G: 128, A: 128})
+
+	tests := []struct {
+		name string
+		img  *image.RGBA
+		want string
+	}{
+		{name: "solid", img: filled(4, 4, color.RGBA{R: 200, G: 100, B: 50, A: 255}), want: "#c86432"},
+		{name: "most common", img: mixed, want: "#c80000"},
+		{name: "premultiplied", img: translucent, want: "#00ff00"},
+		{name: "transparent", img: filled(4, 4, color.RGBA{}), want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := dominantColor(tt.img); got != tt.want {
+			t.Errorf("dominantColor(%s) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package imaging
+
+import "image"
+
+// orient turns the image the way its EXIF orientation tells, so that it is displayed upright.
+func orient(src *image.RGBA, orientation int) *image.RGBA {
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+
+	// the orientations from 5 to 8 swap the width and the height
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2:
+				sx, sy = w-1-x, y
+			case 3:
+				sx, sy = w-1-x, h-1-y
+			case 4:
+				sx, sy = x, h-1-y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, h-1-x
+			case 7:
+				sx, sy = w-1-y, h-1-x
+			case 8:
+				sx, sy = w-1-y, x
+			default:
+				sx, sy = x, y
+			}
+
+			copy(dst.Pix[dst.PixOffset(x, y):dst.PixOffset(x, y)+4], src.Pix[src.PixOffset(sx, sy):src.PixOffset(sx, sy)+4])
+		}
+	}
+
+	return dst
+}
+
+// fit downscales the image to fit into a square of the size keeping its aspect ratio.
+// The smaller images are returned as they are, they are never upscaled.
+func fit(src *image.RGBA, size int) *image.RGBA {
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	if w <= size && h <= size {
+		return src
+	}
+
+	dw, dh := size, size
+	if w > h {
+		dh = max(1, h*size/w)
+	} else {
+		dw = max(1, w*size/h)
+	}
+
+	return resize(src, dw, dh)
+}
+
+// resize scales the image down by averaging the source pixels covered by every pixel of the result.
+// The pixels are premultiplied, so the transparent ones do not darken the edges.
+func resize(src *image.RGBA, dw, dh int) *image.RGBA {
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < dh; y++ {
+		y0, y1 := y*h/dh, max((y+1)*h/dh, y*h/dh+1)
+		for x := 0; x < dw; x++ {
+			x0, x1 := x*w/dw, max((x+1)*w/dw, x*w/dw+1)
+
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				row := src.PixOffset(x0, sy)
+				for i := row; i < row+(x1-x0)*4; i += 4 {
+					sum[0] += int(src.Pix[i])
+					sum[1] += int(src.Pix[i+1])
+					sum[2] += int(src.Pix[i+2])
+					sum[3] += int(src.Pix[i+3])
+				}
+			}
+
+			n := (x1 - x0) * (y1 - y0)
+			o := dst.PixOffset(x, y)
+			for c := 0; c < 4; c++ {
+				dst.Pix[o+c] = uint8((sum[c] + n/2) / n)
+			}
+		}
+	}
+
+	return dst
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,78 @@
+package imaging
+
+import (
+	"image"
+	"reflect"
+	"testing"
+)
+
+// letters returns the image of the rows, every pixel is told apart by its red value.
+func letters(rows ...string) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, len(rows[0]), len(rows)))
+	for y, row := range rows {
+		for x := range row {
+			img.Pix[img.PixOffset(x, y)] = row[x]
+			img.Pix[img.PixOffset(x, y)+3] = 255
+		}
+	}
+	return img
+}
+
+func rows(img *image.RGBA) []string {
+	var res []string
+	for y := 0; y < img.Bounds().Dy(); y++ {
+		row := make([]byte, img.Bounds().Dx())
+		for x := range row {
+			row[x] = img.Pix[img.PixOffset(x, y)]
+		}
+		res = append(res, string(row))
+	}
+	return res
+}
+
+func TestOrient(t *testing.T) {
+	// the stored image is
+	//   ABC
+	//   DEF
+	// and the result is how it is displayed with the EXIF orientation
+	tests := []struct {
+		orientation int
+		want        []string
+	}{
+		{orientation: 1, want: []string{"ABC", "DEF"}},
+		{orientation: 2, want: []string{"CBA", "FED"}},
+		{orientation: 3, want: []string{"FED", "CBA"}},
+		{orientation: 4, want: []string{"DEF", "ABC"}},
+		{orientation: 5, want: []string{"AD", "BE", "CF"}},
+		{orientation: 6, want: []string{"DA", "EB", "FC"}},
+		{orientation: 7, want: []string{"FC", "EB", "DA"}},
+		{orientation: 8, want: []string{"CF", "BE", "AD"}},
+	}
+
+	for _, tt := range tests {
+		if got := rows(orient(letters("ABC", "DEF"), tt.orientation)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("orient(%d) = %v, want %v", tt.orientation, got, tt.want)
+		}
+	}
+}
+
+func TestFit(t *testing.T) {
+	tests := []struct {
+		w, h         int
+		size         int
+		wantW, wantH int
+	}{
+		{w: 200, h: 100, size: 50, wantW: 50, wantH: 25},
+		{w: 100, h: 200, size: 50, wantW: 25, wantH: 50},
+		{w: 100, h: 100, size: 50, wantW: 50, wantH: 50},
+		{w: 1000, h: 1, size: 50, wantW: 50, wantH: 1},
+		{w: 40, h: 10, size: 50, wantW: 40, wantH: 10},
+	}
+
+	for _, tt := range tests {
+		got := fit(image.NewRGBA(image.Rect(0, 0, tt.w, tt.h)), tt.size).Bounds()
+		if got.Dx() != tt.wantW || got.Dy() != tt.wantH {
+			t.Errorf("fit(%dx%d, %d) = %dx%d, want %dx%d", tt.w, tt.h, tt.size, got.Dx(), got.Dy(), tt.wantW, tt.wantH)
+		}
+	}
+}
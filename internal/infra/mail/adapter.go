@@ -0,0 +1,31 @@
+package mail
+
+import (
+	"backend/internal/domain"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"net/mail"
+)
+
+// NewAdapter returns the mailer of the configured driver.
+func NewAdapter(logger logrus.FieldLogger, config *Config) (domain.Mailer, error) {
+	from, err := mail.ParseAddress(config.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender address: %w", err)
+	}
+
+	switch config.Driver {
+	case "smtp":
+		if config.SMTPHost == "" {
+			return nil, errors.New("smtp host is required")
+		}
+		return newSMTPMailer(logger, config, from), nil
+	case "file":
+		return newFileMailer(logger, config, from)
+	case "memory":
+		return NewMemoryMailer(), nil
+	}
+
+	return nil, fmt.Errorf("unknown mail driver %q", config.Driver)
+}
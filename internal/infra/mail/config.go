@@ -0,0 +1,11 @@
+package mail
+
+type Config struct {
+	Driver       string `long:"driver" env:"DRIVER" description:"How emails are delivered: by SMTP, written to files or kept in memory" choice:"smtp" choice:"file" choice:"memory" default:"file"`
+	From         string `long:"from" env:"FROM" description:"Sender address of the emails" default:"Sharito <noreply@sharito.ru>"`
+	SMTPHost     string `long:"smtp-host" env:"SMTP_HOST" description:"SMTP server host"`
+	SMTPPort     int    `long:"smtp-port" env:"SMTP_PORT" description:"SMTP server port" default:"587"`
+	SMTPUsername string `long:"smtp-username" env:"SMTP_USERNAME" description:"SMTP username, authentication is skipped if empty"`
+	SMTPPassword string `long:"smtp-password" env:"SMTP_PASSWORD" description:"SMTP password"`
+	Dir          string `long:"dir" env:"DIR" description:"Directory the emails are written to by the file driver" default:"mail"`
+}
@@ -0,0 +1,41 @@
+package mail
+
+import (
+	"backend/internal/domain"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// fileMailer writes the emails to .eml files instead of sending them, it is meant for the local development.
+type fileMailer struct {
+	logger logrus.FieldLogger
+	dir    string
+	from   *mail.Address
+}
+
+func newFileMailer(logger logrus.FieldLogger, config *Config, from *mail.Address) (*fileMailer, error) {
+	if err := os.MkdirAll(config.Dir, 0755); err != nil {
+		return nil, err
+	}
+
+	return &fileMailer{
+		logger: logger,
+		dir:    config.Dir,
+		from:   from,
+	}, nil
+}
+
+func (m *fileMailer) Send(email *domain.Email) error {
+	name := filepath.Join(m.dir, fmt.Sprintf("%s-%d.eml", time.Now().Format("20060102T150405"), email.ID))
+	if err := ioutil.WriteFile(name, message(m.from, email), 0644); err != nil {
+		return err
+	}
+
+	m.logger.WithField("file", name).WithField("to", email.To).Info("Email written to file.")
+	return nil
+}
@@ -0,0 +1,33 @@
+package mail
+
+import (
+	"backend/internal/domain"
+	"sync"
+)
+
+// MemoryMailer keeps the emails in memory, so that tests can inspect them.
+type MemoryMailer struct {
+	mu     sync.Mutex
+	emails []*domain.Email
+}
+
+func NewMemoryMailer() *MemoryMailer {
+	return &MemoryMailer{}
+}
+
+func (m *MemoryMailer) Send(email *domain.Email) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e := *email
+	m.emails = append(m.emails, &e)
+	return nil
+}
+
+// Sent returns the emails sent so far.
+func (m *MemoryMailer) Sent() []*domain.Email {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]*domain.Email(nil), m.emails...)
+}
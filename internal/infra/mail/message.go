@@ -0,0 +1,37 @@
+package mail
+
+import (
+	"backend/internal/domain"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net/mail"
+	"time"
+)
+
+// lineLength is the max length of the base64 encoded body lines, as required by RFC 2045.
+const lineLength = 76
+
+// message renders the email in the RFC 5322 format.
+func message(from *mail.Address, email *domain.Email) []byte {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "From: %s\r\n", from.String())
+	fmt.Fprintf(&b, "To: %s\r\n", email.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", email.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: base64\r\n")
+	b.WriteString("\r\n")
+
+	body := base64.StdEncoding.EncodeToString([]byte(email.Body))
+	for len(body) > lineLength {
+		b.WriteString(body[:lineLength] + "\r\n")
+		body = body[lineLength:]
+	}
+	b.WriteString(body + "\r\n")
+
+	return b.Bytes()
+}
@@ -0,0 +1,37 @@
+package mail
+
+import (
+	"backend/internal/domain"
+	"github.com/sirupsen/logrus"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+)
+
+type smtpMailer struct {
+	logger logrus.FieldLogger
+	addr   string
+	auth   smtp.Auth
+	from   *mail.Address
+}
+
+func newSMTPMailer(logger logrus.FieldLogger, config *Config, from *mail.Address) *smtpMailer {
+	m := &smtpMailer{
+		logger: logger,
+		addr:   net.JoinHostPort(config.SMTPHost, strconv.Itoa(config.SMTPPort)),
+		from:   from,
+	}
+
+	// PlainAuth refuses to send the credentials over an unencrypted connection,
+	// STARTTLS is used whenever the server supports it
+	if config.SMTPUsername != "" {
+		m.auth = smtp.PlainAuth("", config.SMTPUsername, config.SMTPPassword, config.SMTPHost)
+	}
+
+	return m
+}
+
+func (m *smtpMailer) Send(email *domain.Email) error {
+	return smtp.SendMail(m.addr, m.auth, m.from.Address, []string{email.To}, message(m.from, email))
+}
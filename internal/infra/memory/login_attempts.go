@@ -0,0 +1,115 @@
+package memory
+
+import (
+	"backend/internal/domain"
+	"sync"
+	"time"
+)
+
+// maxLoginAttempts is the number of the latest failed attempts kept for the audit.
+const maxLoginAttempts = 1000
+
+// LoginAttemptStore keeps the failed login counters in memory,
+// it suits a single instance deployment and tests.
+type LoginAttemptStore struct {
+	mu        sync.Mutex
+	throttles map[string]*domain.LoginThrottle
+	attempts  []*domain.LoginAttempt
+	lastID    int
+	swept     time.Time
+}
+
+func NewLoginAttemptStore() *LoginAttemptStore {
+	return &LoginAttemptStore{
+		throttles: make(map[string]*domain.LoginThrottle),
+		swept:     time.Now(),
+	}
+}
+
+func (s *LoginAttemptStore) GetLoginThrottle(key string) (*domain.LoginThrottle, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if t, ok := s.throttles[key]; ok {
+		throttle := *t
+		return &throttle, nil
+	}
+
+	return &domain.LoginThrottle{Key: key}, nil
+}
+
+func (s *LoginAttemptStore) RegisterLoginFailure(key string, window time.Duration) (*domain.LoginThrottle, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now, window)
+
+	t, ok := s.throttles[key]
+	if !ok || now.Sub(t.LastFailureAt) > window {
+		t = &domain.LoginThrottle{Key: key}
+		s.throttles[key] = t
+	}
+	t.Failures++
+	t.LastFailureAt = now
+
+	throttle := *t
+	return &throttle, nil
+}
+
+func (s *LoginAttemptStore) UndoLoginFailure(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if t, ok := s.throttles[key]; ok && t.Failures > 0 {
+		t.Failures--
+	}
+	return nil
+}
+
+func (s *LoginAttemptStore) ResetLoginFailures(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.throttles, key)
+	return nil
+}
+
+func (s *LoginAttemptStore) SaveLoginAttempt(attempt *domain.LoginAttempt) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastID++
+	a := *attempt
+	a.ID = s.lastID
+	a.CreatedAt = time.Now()
+
+	s.attempts = append(s.attempts, &a)
+	if len(s.attempts) > maxLoginAttempts {
+		s.attempts = s.attempts[len(s.attempts)-maxLoginAttempts:]
+	}
+
+	return nil
+}
+
+// Attempts returns the latest failed login attempts.
+func (s *LoginAttemptStore) Attempts() []*domain.LoginAttempt {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]*domain.LoginAttempt(nil), s.attempts...)
+}
+
+// sweep drops the forgotten counters once per window, so that the IPs of the past attacks do not pile up.
+func (s *LoginAttemptStore) sweep(now time.Time, window time.Duration) {
+	if now.Sub(s.swept) < window {
+		return
+	}
+	s.swept = now
+
+	for key, t := range s.throttles {
+		if now.Sub(t.LastFailureAt) > window {
+			delete(s.throttles, key)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package oidc
+
+import (
+	"backend/internal/domain"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+)
+
+var defaultScopes = []string{"openid", "email", "profile"}
+
+type adapter struct {
+	logger    logrus.FieldLogger
+	config    *Config
+	providers map[string]*provider
+}
+
+func NewAdapter(logger logrus.FieldLogger, config *Config) (domain.OIDC, error) {
+	a := &adapter{
+		logger:    logger,
+		config:    config,
+		providers: make(map[string]*provider),
+	}
+
+	if config.ProvidersFile == "" {
+		return a, nil
+	}
+
+	bts, err := ioutil.ReadFile(config.ProvidersFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var providers []ProviderConfig
+	if err := json.Unmarshal(bts, &providers); err != nil {
+		return nil, fmt.Errorf("invalid providers file: %w", err)
+	}
+
+	client := &http.Client{Timeout: config.Timeout}
+	for _, v := range providers {
+		if v.Name == "" || v.Issuer == "" || v.ClientID == "" {
+			return nil, fmt.Errorf("provider %q: name, issuer and client_id are required", v.Name)
+		}
+		if _, ok := a.providers[v.Name]; ok {
+			return nil, fmt.Errorf("provider %q is duplicated", v.Name)
+		}
+
+		v.ClientSecret = os.ExpandEnv(v.ClientSecret)
+		if len(v.Scopes) == 0 {
+			v.Scopes = defaultScopes
+		}
+
+		a.providers[v.Name] = newProvider(logger.WithField("provider", v.Name), v, client)
+	}
+
+	return a, nil
+}
+
+func (a *adapter) Providers() []string {
+	names := make([]string, 0, len(a.providers))
+	for name := range a.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func (a *adapter) AuthCodeURL(name, state, nonce, codeVerifier string) (string, error) {
+	p, ok := a.providers[name]
+	if !ok {
+		return "", domain.ErrNoSuchProvider
+	}
+
+	md, err := p.metadata(context.Background())
+	if err != nil {
+		return "", err
+	}
+
+	// PKCE, RFC 7636
+	challenge := sha256.Sum256([]byte(codeVerifier))
+
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", p.config.ClientID)
+	params.Set("redirect_uri", a.config.RedirectURL)
+	params.Set("scope", strings.Join(p.config.Scopes, " "))
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	params.Set("code_challenge_method", "S256")
+
+	sep := "?"
+	if strings.Contains(md.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+
+	return md.AuthorizationEndpoint + sep + params.Encode(), nil
+}
+
+type tokenResponse struct {
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+func (a *adapter) Exchange(ctx context.Context, name, code, codeVerifier, nonce string) (*domain.ExternalIdentity, error) {
+	p, ok := a.providers[name]
+	if !ok {
+		return nil, domain.ErrNoSuchProvider
+	}
+
+	md, err := p.metadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", a.config.RedirectURL)
+	form.Set("code_verifier", codeVerifier)
+	form.Set("client_id", p.config.ClientID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, md.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		p.logger.WithError(err).Error("Error while creating token request!")
+		return nil, domain.ErrIdentityProvider
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.config.ClientSecret != "" {
+		// client_secret_basic, the credentials are form encoded first as required by RFC 6749
+		req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		p.logger.WithError(err).Error("Error while exchanging authorization code!")
+		return nil, domain.ErrIdentityProvider
+	}
+	defer resp.Body.Close()
+
+	var token tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		p.logger.WithError(err).WithField("status", resp.StatusCode).Error("Error while decoding token response!")
+		return nil, domain.ErrIdentityProvider
+	}
+
+	if resp.StatusCode != http.StatusOK || token.IDToken == "" {
+		p.logger.WithField("status", resp.StatusCode).WithField("error", token.Error).
+			WithField("description", token.ErrorDescription).Warn("Authorization code is rejected!")
+		// the code is invalid, expired or issued for another client
+		if token.Error == "invalid_grant" {
+			return nil, domain.ErrInvalidToken
+		}
+		return nil, domain.ErrIdentityProvider
+	}
+
+	identity, err := p.verifyIDToken(ctx, md, token.IDToken, nonce)
+	if err != nil {
+		if errors.Is(err, domain.ErrIdentityProvider) {
+			return nil, err
+		}
+		p.logger.WithError(err).Warn("Invalid ID token!")
+		return nil, domain.ErrUnauthorized
+	}
+	identity.Provider = name
+
+	return identity, nil
+}
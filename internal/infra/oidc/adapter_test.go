@@ -0,0 +1,295 @@
+package oidc
+
+import (
+	"backend/internal/domain"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testProvider     = "stub"
+	testClientID     = "sharito"
+	testClientSecret = "secret"
+	testKID          = "key-1"
+	testCode         = "code"
+	testVerifier     = "verifier"
+	testNonce        = "nonce"
+)
+
+// stubIssuer is an OpenID provider serving the discovery document, the token endpoint and the keys.
+type stubIssuer struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu      sync.Mutex
+	idToken string
+	status  int
+	error   string
+}
+
+func newStubIssuer(t *testing.T) *stubIssuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &stubIssuer{t: t, key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(metadata{
+			Issuer:                s.server.URL,
+			AuthorizationEndpoint: s.server.URL + "/authorize",
+			TokenEndpoint:         s.server.URL + "/token",
+			JWKSURI:               s.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(jwkSet{Keys: []jwk{{
+			KID: testKID,
+			Kty: "RSA",
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", s.token)
+
+	s.server = httptest.NewServer(mux)
+	return s
+}
+
+func (s *stubIssuer) token(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, secret, ok := r.BasicAuth()
+	if r.Method != http.MethodPost || !ok || id != testClientID || secret != testClientSecret {
+		w.WriteHeader(http.StatusUnauthorized)
+		_ = json.NewEncoder(w).Encode(tokenResponse{Error: "invalid_client"})
+		return
+	}
+
+	if r.PostFormValue("grant_type") != "authorization_code" || r.PostFormValue("code") != testCode ||
+		r.PostFormValue("code_verifier") != testVerifier || r.PostFormValue("redirect_uri") != "http://localhost/callback" {
+		s.t.Errorf("unexpected token request %v", r.PostForm)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if s.status != 0 {
+		w.WriteHeader(s.status)
+	}
+	_ = json.NewEncoder(w).Encode(tokenResponse{IDToken: s.idToken, Error: s.error})
+}
+
+// respond sets the response of the token endpoint.
+func (s *stubIssuer) respond(status int, idToken, errorCode string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.status, s.idToken, s.error = status, idToken, errorCode
+}
+
+// sign returns an ID token signed by the issuer, the changes are applied to the valid claims.
+func (s *stubIssuer) sign(kid string, change func(claims jwt.MapClaims)) string {
+	claims := jwt.MapClaims{
+		"iss":            s.server.URL,
+		"aud":            testClientID,
+		"sub":            "subject",
+		"exp":            time.Now().Add(time.Hour).Unix(),
+		"iat":            time.Now().Unix(),
+		"nonce":          testNonce,
+		"email":          "ivan@example.com",
+		"email_verified": true,
+		"name":           "Ivan Petrov",
+	}
+	if change != nil {
+		change(claims)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+
+	signed, err := token.SignedString(s.key)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+
+	return signed
+}
+
+func (s *stubIssuer) adapter(t *testing.T) domain.OIDC {
+	dir, err := ioutil.TempDir("", "oidc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "providers.json")
+	bts, _ := json.Marshal([]ProviderConfig{{
+		Name:         testProvider,
+		Issuer:       s.server.URL,
+		ClientID:     testClientID,
+		ClientSecret: testClientSecret,
+	}})
+	if err := ioutil.WriteFile(file, bts, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+
+	a, err := NewAdapter(logger, &Config{
+		ProvidersFile: file,
+		RedirectURL:   "http://localhost/callback",
+		Timeout:       5 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return a
+}
+
+func TestExchange(t *testing.T) {
+	issuer := newStubIssuer(t)
+	defer issuer.server.Close()
+
+	a := issuer.adapter(t)
+	issuer.respond(http.StatusOK, issuer.sign(testKID, nil), "")
+
+	identity, err := a.Exchange(context.Background(), testProvider, testCode, testVerifier, testNonce)
+	if err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+
+	want := domain.ExternalIdentity{
+		Provider:      testProvider,
+		Subject:       "subject",
+		Email:         "ivan@example.com",
+		EmailVerified: true,
+		FirstName:     "Ivan",
+		LastName:      "Petrov",
+	}
+	if *identity != want {
+		t.Errorf("Exchange() = %+v, want %+v", *identity, want)
+	}
+}
+
+func TestExchangeRejected(t *testing.T) {
+	issuer := newStubIssuer(t)
+	defer issuer.server.Close()
+
+	tests := []struct {
+		name    string
+		status  int
+		idToken func() string
+		error   string
+		want    error
+	}{
+		{
+			name:    "bad nonce",
+			idToken: func() string { return issuer.sign(testKID, func(c jwt.MapClaims) { c["nonce"] = "other" }) },
+			want:    domain.ErrUnauthorized,
+		},
+		{
+			name:    "no nonce",
+			idToken: func() string { return issuer.sign(testKID, func(c jwt.MapClaims) { delete(c, "nonce") }) },
+			want:    domain.ErrUnauthorized,
+		},
+		{
+			name:    "wrong aud",
+			idToken: func() string { return issuer.sign(testKID, func(c jwt.MapClaims) { c["aud"] = "other" }) },
+			want:    domain.ErrUnauthorized,
+		},
+		{
+			name: "wrong aud in array",
+			idToken: func() string {
+				return issuer.sign(testKID, func(c jwt.MapClaims) { c["aud"] = []string{"other", "another"} })
+			},
+			want: domain.ErrUnauthorized,
+		},
+		{
+			name: "wrong issuer",
+			idToken: func() string {
+				return issuer.sign(testKID, func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" })
+			},
+			want: domain.ErrUnauthorized,
+		},
+		{
+			name: "expired",
+			idToken: func() string {
+				return issuer.sign(testKID, func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() })
+			},
+			want: domain.ErrUnauthorized,
+		},
+		{
+			name:    "unknown kid",
+			idToken: func() string { return issuer.sign("key-2", nil) },
+			want:    domain.ErrUnauthorized,
+		},
+		{
+			name:    "invalid_grant",
+			status:  http.StatusBadRequest,
+			idToken: func() string { return "" },
+			error:   "invalid_grant",
+			want:    domain.ErrInvalidToken,
+		},
+		{
+			name:    "provider error",
+			status:  http.StatusInternalServerError,
+			idToken: func() string { return "" },
+			error:   "server_error",
+			want:    domain.ErrIdentityProvider,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := issuer.adapter(t)
+			issuer.respond(tt.status, tt.idToken(), tt.error)
+
+			identity, err := a.Exchange(context.Background(), testProvider, testCode, testVerifier, testNonce)
+			if err != tt.want {
+				t.Errorf("Exchange() = %+v, %v, want error %v", identity, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestExchangeAudienceArray(t *testing.T) {
+	issuer := newStubIssuer(t)
+	defer issuer.server.Close()
+
+	a := issuer.adapter(t)
+	issuer.respond(http.StatusOK, issuer.sign(testKID, func(c jwt.MapClaims) { c["aud"] = []string{"other", testClientID} }), "")
+
+	if _, err := a.Exchange(context.Background(), testProvider, testCode, testVerifier, testNonce); err != nil {
+		t.Errorf("Exchange() error = %v", err)
+	}
+}
+
+func TestExchangeUnknownProvider(t *testing.T) {
+	issuer := newStubIssuer(t)
+	defer issuer.server.Close()
+
+	a := issuer.adapter(t)
+	if _, err := a.Exchange(context.Background(), "other", testCode, testVerifier, testNonce); err != domain.ErrNoSuchProvider {
+		t.Errorf("Exchange() error = %v, want %v", err, domain.ErrNoSuchProvider)
+	}
+}
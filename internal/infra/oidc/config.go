@@ -0,0 +1,19 @@
+package oidc
+
+import "time"
+
+type Config struct {
+	ProvidersFile string        `long:"providers-file" env:"PROVIDERS_FILE" description:"JSON file with the OpenID Connect providers, social login is disabled if empty"`
+	RedirectURL   string        `long:"redirect-url" env:"REDIRECT_URL" description:"Page of the web application the providers redirect back to" default:"http://localhost:3000/oauth/callback"`
+	Timeout       time.Duration `long:"timeout" env:"TIMEOUT" description:"Timeout of the requests to the providers" default:"10s"`
+}
+
+// ProviderConfig describes a provider in the providers file,
+// the client secret may refer to an environment variable like "${GOOGLE_CLIENT_SECRET}".
+type ProviderConfig struct {
+	Name         string   `json:"name"`
+	Issuer       string   `json:"issuer"`
+	ClientID     string   `json:"client_id"`
+	ClientSecret string   `json:"client_secret"`
+	Scopes       []string `json:"scopes"`
+}
@@ -0,0 +1,237 @@
+package oidc
+
+import (
+	"backend/internal/domain"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// keysRefreshInterval limits refetching the provider keys when a token is signed by an unknown key.
+const keysRefreshInterval = time.Minute
+
+// metadata is the part of the provider configuration document used by the authorization code flow.
+type metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type provider struct {
+	logger logrus.FieldLogger
+	config ProviderConfig
+	client *http.Client
+
+	mu            sync.Mutex
+	md            *metadata
+	keys          map[string]crypto.PublicKey
+	keysFetchedAt time.Time
+}
+
+func newProvider(logger logrus.FieldLogger, config ProviderConfig, client *http.Client) *provider {
+	return &provider{
+		logger: logger,
+		config: config,
+		client: client,
+	}
+}
+
+// metadata returns the provider configuration, it is discovered on the first use
+// so that an unavailable provider does not prevent the service from starting.
+func (p *provider) metadata(ctx context.Context) (*metadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.md != nil {
+		return p.md, nil
+	}
+
+	var md metadata
+	if err := p.get(ctx, strings.TrimSuffix(p.config.Issuer, "/")+"/.well-known/openid-configuration", &md); err != nil {
+		p.logger.WithError(err).Error("Error while discovering provider configuration!")
+		return nil, domain.ErrIdentityProvider
+	}
+
+	if md.Issuer != p.config.Issuer || md.AuthorizationEndpoint == "" || md.TokenEndpoint == "" || md.JWKSURI == "" {
+		p.logger.WithField("issuer", md.Issuer).Error("Invalid provider configuration!")
+		return nil, domain.ErrIdentityProvider
+	}
+
+	p.md = &md
+	return p.md, nil
+}
+
+// key returns the provider key the token is signed with, the keys are refetched when the provider rotates them.
+func (p *provider) key(ctx context.Context, md *metadata, kid string) (crypto.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+
+	if time.Since(p.keysFetchedAt) < keysRefreshInterval {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+
+	var set jwkSet
+	if err := p.get(ctx, md.JWKSURI, &set); err != nil {
+		p.logger.WithError(err).Error("Error while fetching provider keys!")
+		return nil, domain.ErrIdentityProvider
+	}
+	p.keysFetchedAt = time.Now()
+
+	p.keys = make(map[string]crypto.PublicKey)
+	for _, v := range set.Keys {
+		key, err := v.publicKey()
+		if err != nil {
+			p.logger.WithError(err).WithField("kid", v.KID).Debug("Skipping provider key!")
+			continue
+		}
+		p.keys[v.KID] = key
+	}
+
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("unknown key id %q", kid)
+}
+
+func (p *provider) get(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// verifyIDToken checks the signature and the claims of the ID token as required by OpenID Connect Core 3.1.3.7.
+func (p *provider) verifyIDToken(ctx context.Context, md *metadata, idToken, nonce string) (*domain.ExternalIdentity, error) {
+	claims := jwt.MapClaims{}
+
+	// the provider errors are kept apart from the invalid tokens
+	var providerErr error
+	if _, err := jwt.ParseWithClaims(idToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+
+		key, err := p.key(ctx, md, kid)
+		if err != nil {
+			if errors.Is(err, domain.ErrIdentityProvider) {
+				providerErr = err
+			}
+			return nil, err
+		}
+
+		switch key.(type) {
+		case *rsa.PublicKey:
+			if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+				return nil, fmt.Errorf("unexpected signing method %q", token.Method.Alg())
+			}
+		case *ecdsa.PublicKey:
+			if _, ok := token.Method.(*jwt.SigningMethodECDSA); !ok {
+				return nil, fmt.Errorf("unexpected signing method %q", token.Method.Alg())
+			}
+		default:
+			return nil, fmt.Errorf("unsupported key of %q", kid)
+		}
+
+		return key, nil
+	}); err != nil {
+		if providerErr != nil {
+			return nil, providerErr
+		}
+		return nil, err
+	}
+
+	if iss, _ := claims["iss"].(string); iss != md.Issuer {
+		return nil, fmt.Errorf("unexpected issuer %q", iss)
+	}
+	if !hasAudience(claims["aud"], p.config.ClientID) {
+		return nil, errors.New("unexpected audience")
+	}
+	if _, ok := claims["exp"]; !ok {
+		return nil, errors.New("no expiration")
+	}
+	if n, _ := claims["nonce"].(string); n != nonce {
+		return nil, errors.New("nonce mismatch")
+	}
+
+	identity := &domain.ExternalIdentity{
+		Subject:       stringClaim(claims, "sub"),
+		Email:         stringClaim(claims, "email"),
+		EmailVerified: boolClaim(claims, "email_verified"),
+		FirstName:     stringClaim(claims, "given_name"),
+		LastName:      stringClaim(claims, "family_name"),
+	}
+
+	if identity.Subject == "" {
+		return nil, errors.New("no subject")
+	}
+
+	if identity.FirstName == "" {
+		parts := strings.SplitN(stringClaim(claims, "name"), " ", 2)
+		identity.FirstName = parts[0]
+		if len(parts) > 1 && identity.LastName == "" {
+			identity.LastName = parts[1]
+		}
+	}
+
+	return identity, nil
+}
+
+// hasAudience reports whether the aud claim, a string or an array of strings, contains the client.
+func hasAudience(aud interface{}, clientID string) bool {
+	switch v := aud.(type) {
+	case string:
+		return v == clientID
+	case []interface{}:
+		for _, a := range v {
+			if a == clientID {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func stringClaim(claims jwt.MapClaims, name string) string {
+	v, _ := claims[name].(string)
+	return v
+}
+
+// boolClaim reads the boolean claim, some providers encode them as strings.
+func boolClaim(claims jwt.MapClaims, name string) bool {
+	switch v := claims[name].(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	}
+
+	return false
+}
@@ -0,0 +1,122 @@
+package postgres
+
+import (
+	"backend/internal/domain"
+	"backend/internal/infra/postgres/models"
+	"database/sql"
+	"errors"
+	"github.com/jmoiron/sqlx"
+)
+
+func (a *adapter) GetOwnedProducts(ownerID int) ([]*domain.Product, error) {
+	var p models.Products
+	if err := a.db.Select(&p,
+		`SELECT id, owner_id, name, per_hour, per_day, per_week, description, cancellation_policy, category, archived_at, hidden_at
+				FROM products
+				WHERE owner_id = $1
+				ORDER BY id`,
+		ownerID); err != nil {
+		a.logger.WithError(err).Error("Error while getting owned products!")
+		return nil, domain.ErrInternalDatabase
+	}
+
+	for _, v := range p {
+		if err := a.loadProductPhotos(v, false); err != nil {
+			return nil, err
+		}
+	}
+
+	return p.Domain(), nil
+}
+
+// hasOrders reports whether the user has orders in the statuses as a renter or as an owner.
+func (a *adapter) hasOrders(q sqlx.Queryer, userID int, statuses []domain.OrderStatus) (bool, error) {
+	ss := make([]string, 0, len(statuses))
+	for _, v := range statuses {
+		ss = append(ss, string(v))
+	}
+
+	var exists bool
+	if err := sqlx.Get(
+		q,
+		&exists,
+		`SELECT EXISTS(
+					SELECT 1
+					FROM orders o
+					JOIN products p ON p.id = o.product_id
+					WHERE (o.user_id = $1 OR p.owner_id = $1) AND o.status = ANY($2)
+				)`,
+		userID,
+		ss,
+	); err != nil {
+		a.logger.WithError(err).Error("Error while checking user orders!")
+		return false, domain.ErrInternalDatabase
+	}
+
+	return exists, nil
+}
+
+// anonymizeStatements erase the personal data of the user $1, the order matters.
+var anonymizeStatements = []string{
+	`DELETE FROM email_outbox WHERE lower(recipient) = (SELECT lower(email) FROM users WHERE id = $1)`,
+	`DELETE FROM refresh_tokens WHERE session_id IN (SELECT id FROM sessions WHERE user_id = $1)`,
+	`DELETE FROM sessions WHERE user_id = $1`,
+	`DELETE FROM user_tokens WHERE user_id = $1`,
+	`DELETE FROM recovery_codes WHERE user_id = $1`,
+	`DELETE FROM user_identities WHERE user_id = $1`,
+	`DELETE FROM oidc_logins WHERE user_id = $1`,
+	// the attempts with unknown logins were made before the user registered or by mistyping the login
+	`DELETE FROM login_attempts
+				WHERE user_id = $1
+				   OR (user_id IS NULL AND lower(login) = (SELECT lower(login) FROM users WHERE id = $1))`,
+	`DELETE FROM login_throttles
+				WHERE key IN ((SELECT 'account:' || lower(login) FROM users WHERE id = $1), '2fa:' || $1)`,
+	// the variants go along with the photos, the service deletes their blobs
+	`DELETE FROM product_photos WHERE product_id IN (SELECT id FROM products WHERE owner_id = $1)`,
+	`UPDATE products
+				SET archived_at = coalesce(archived_at, now()), updated_at = now()
+				WHERE owner_id = $1`,
+	// the login and the email are not valid ones, so that nobody can take them
+	`UPDATE users
+				SET login = 'deleted:' || id, first_name = 'Deleted', last_name = 'User', email = 'deleted:' || id,
+				    password_hash = NULL, salt = NULL, language = NULL, avatar_key = NULL, email_verified_at = NULL,
+				    totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = NULL, deleted_at = now()
+				WHERE id = $1`,
+}
+
+func (a *adapter) AnonymizeUser(id int, openStatuses []domain.OrderStatus) error {
+	return a.inTx(func(tx *sqlx.Tx) error {
+		var userID int
+		if err := tx.Get(&userID, `SELECT id FROM users WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, id); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return domain.ErrNoSuchUser
+			}
+			a.logger.WithError(err).Error("Error while locking user!")
+			return domain.ErrInternalDatabase
+		}
+
+		// the products are locked like by the booking, so that no order is placed while the user is checked
+		if _, err := tx.Exec(`SELECT id FROM products WHERE owner_id = $1 FOR UPDATE`, id); err != nil {
+			a.logger.WithError(err).Error("Error while locking user products!")
+			return domain.ErrInternalDatabase
+		}
+
+		open, err := a.hasOrders(tx, id, openStatuses)
+		if err != nil {
+			return err
+		}
+
+		if open {
+			return domain.ErrActiveRentals
+		}
+
+		for _, v := range anonymizeStatements {
+			if _, err := tx.Exec(v, id); err != nil {
+				a.logger.WithError(err).Error("Error while anonymizing user!")
+				return domain.ErrInternalDatabase
+			}
+		}
+
+		return nil
+	})
+}
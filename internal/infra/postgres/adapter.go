@@ -5,6 +5,7 @@ import (
 	"backend/internal/infra/postgres/models"
 	"database/sql"
 	"errors"
+	"fmt"
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
@@ -53,15 +54,25 @@ func NewAdapter(logger logrus.FieldLogger, config *Config) (domain.Database, err
 		return nil, err
 	}
 
+	if err := a.syncSearchDictionaries(); err != nil {
+		return nil, err
+	}
+
 	return a, nil
 }
 
 func (a *adapter) SaveUser(user *domain.User) (int, error) {
+	return a.insertUser(a.db, user, false)
+}
+
+// insertUser saves the user by q, which is either the database or a transaction.
+func (a *adapter) insertUser(q sqlx.Queryer, user *domain.User, emailVerified bool) (int, error) {
 	var id int
-	if err := a.db.Get(
+	if err := sqlx.Get(
+		q,
 		&id,
-		`INSERT INTO users (login, first_name, last_name, email, password_hash, salt)
-				VALUES ($1, $2, $3, $4, $5, $6)
+		`INSERT INTO users (login, first_name, last_name, email, password_hash, salt, language, email_verified_at)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, CASE WHEN $8 THEN now() END)
 				RETURNING id`,
 		user.Login,
 		user.FirstName,
@@ -69,7 +80,17 @@ func (a *adapter) SaveUser(user *domain.User) (int, error) {
 		user.Email,
 		user.PasswordHash,
 		user.Salt,
+		user.Language,
+		emailVerified,
 	); err != nil {
+		if pgErrorCode(err) == codeUniqueViolation {
+			switch pgConstraintName(err) {
+			case "users_login_key":
+				return 0, domain.ErrLoginTaken
+			case "users_email_key":
+				return 0, domain.ErrEmailTaken
+			}
+		}
 		a.logger.WithError(err).Error("Error while saving user info!")
 		return 0, domain.ErrInternalDatabase
 	}
@@ -82,9 +103,10 @@ func (a *adapter) GetUserByLogin(login string) (*domain.User, error) {
 
 	if err := a.db.Get(
 		&user,
-		`SELECT id, login, first_name, last_name, email, password_hash, salt
+		`SELECT id, login, first_name, last_name, email, password_hash, salt, language, email_verified_at,
+					totp_secret, totp_enabled_at, role, blocked_at, block_reason, avatar_key, created_at, deleted_at
 				FROM users
-				WHERE login = $1`,
+				WHERE lower(login) = lower($1)`,
 		login); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			a.logger.WithError(err).Error("There is no such user!")
@@ -102,7 +124,8 @@ func (a *adapter) GetUserByID(id int) (*domain.User, error) {
 
 	if err := a.db.Get(
 		&user,
-		`SELECT id, login, first_name, last_name, email, password_hash, salt
+		`SELECT id, login, first_name, last_name, email, password_hash, salt, language, email_verified_at,
+					totp_secret, totp_enabled_at, role, blocked_at, block_reason, avatar_key, created_at, deleted_at
 				FROM users
 				WHERE id = $1`,
 		id); err != nil {
@@ -117,54 +140,166 @@ func (a *adapter) GetUserByID(id int) (*domain.User, error) {
 	return user.Domain(), nil
 }
 
-func (a *adapter) SaveProduct(product *domain.Product) (int, error) {
-	tx, err := a.db.Beginx()
+func (a *adapter) GetUserByEmail(email string) (*domain.User, error) {
+	var user models.User
+
+	if err := a.db.Get(
+		&user,
+		`SELECT id, login, first_name, last_name, email, password_hash, salt, language, email_verified_at,
+					totp_secret, totp_enabled_at, role, blocked_at, block_reason, avatar_key, created_at, deleted_at
+				FROM users
+				WHERE lower(email) = lower($1)`,
+		email); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNoSuchUser
+		}
+		a.logger.WithError(err).Error("Error while getting user info by email!")
+		return nil, domain.ErrInternalDatabase
+	}
+
+	return user.Domain(), nil
+}
+
+func (a *adapter) ChangeUserPassword(id int, passwordHash, salt []byte, exceptSessionID int, reason string) error {
+	return a.inTx(func(tx *sqlx.Tx) error {
+		if _, err := tx.Exec(
+			`UPDATE users
+					SET password_hash = $1, salt = $2
+					WHERE id = $3`,
+			passwordHash,
+			salt,
+			id,
+		); err != nil {
+			a.logger.WithError(err).Error("Error while updating user password!")
+			return domain.ErrInternalDatabase
+		}
+
+		if _, err := tx.Exec(
+			`UPDATE user_tokens
+					SET used_at = now()
+					WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL`,
+			id,
+			domain.TokenPurposePasswordReset,
+		); err != nil {
+			a.logger.WithError(err).Error("Error while revoking user tokens!")
+			return domain.ErrInternalDatabase
+		}
+
+		if _, err := tx.Exec(
+			`UPDATE sessions
+					SET revoked_at = now(), revoke_reason = $1
+					WHERE user_id = $2 AND id <> $3 AND revoked_at IS NULL`,
+			reason,
+			id,
+			exceptSessionID,
+		); err != nil {
+			a.logger.WithError(err).Error("Error while revoking user sessions!")
+			return domain.ErrInternalDatabase
+		}
+
+		return nil
+	})
+}
+
+// UpdateUser saves the profile fields of the user.
+func (a *adapter) UpdateUser(user *domain.User) error {
+	res, err := a.db.Exec(
+		`UPDATE users
+				SET first_name = $1, last_name = $2, email = $3, language = $4, email_verified_at = $5
+				WHERE id = $6`,
+		user.FirstName,
+		user.LastName,
+		user.Email,
+		user.Language,
+		user.EmailVerifiedAt,
+		user.ID,
+	)
 	if err != nil {
-		a.logger.WithError(err).Error("Error while trying to begin a database transaction!")
-		return 0, err
+		if pgErrorCode(err) == codeUniqueViolation && pgConstraintName(err) == "users_email_key" {
+			return domain.ErrEmailTaken
+		}
+		a.logger.WithError(err).Error("Error while updating user info!")
+		return domain.ErrInternalDatabase
 	}
 
-	defer func(err *error) {
-		if *err != nil {
-			if err := tx.Rollback(); err != nil {
-				a.logger.WithError(err).Error("Error while trying to rollback a database transaction!")
+	affected, err := res.RowsAffected()
+	if err != nil {
+		a.logger.WithError(err).Error("Error while getting affected rows!")
+		return domain.ErrInternalDatabase
+	}
+
+	if affected == 0 {
+		return domain.ErrNoSuchUser
+	}
+
+	return nil
+}
+
+// UpdateUserAvatar swaps the avatar under the lock of the user row, so that the previous one is never lost.
+func (a *adapter) UpdateUserAvatar(id int, key *string) (*string, error) {
+	var previous *string
+	err := a.inTx(func(tx *sqlx.Tx) error {
+		if err := tx.Get(
+			&previous,
+			`SELECT avatar_key FROM users WHERE id = $1 FOR UPDATE`,
+			id,
+		); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return domain.ErrNoSuchUser
 			}
+			a.logger.WithError(err).Error("Error while getting user avatar!")
+			return domain.ErrInternalDatabase
 		}
-	}(&err)
 
-	var id int
+		if _, err := tx.Exec(
+			`UPDATE users SET avatar_key = $2 WHERE id = $1`,
+			id,
+			key,
+		); err != nil {
+			a.logger.WithError(err).Error("Error while updating user avatar!")
+			return domain.ErrInternalDatabase
+		}
+
+		return nil
+	})
+
+	return previous, err
+}
+
+func (a *adapter) VerifyUserEmail(id int) error {
+	if _, err := a.db.Exec(
+		`UPDATE users
+				SET email_verified_at = now()
+				WHERE id = $1 AND email_verified_at IS NULL`,
+		id,
+	); err != nil {
+		a.logger.WithError(err).Error("Error while verifying user email!")
+		return domain.ErrInternalDatabase
+	}
 
-	if err := tx.Get(
+	return nil
+}
+
+func (a *adapter) SaveProduct(product *domain.Product) (int, error) {
+	var id int
+	if err := a.db.Get(
 		&id,
-		`INSERT INTO products (owner_id, name, per_hour, description)
-				VALUES ($1, $2, $3, $4)
+		`INSERT INTO products (owner_id, name, per_hour, per_day, per_week, description, cancellation_policy, category)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 				RETURNING id`,
 		product.OwnerID,
 		product.Name,
 		product.PerHour,
+		product.PerDay,
+		product.PerWeek,
 		product.Description,
+		product.CancellationPolicy,
+		product.Category,
 	); err != nil {
 		a.logger.WithError(err).Error("Error while saving product info!")
 		return 0, domain.ErrInternalDatabase
 	}
 
-	for _, v := range product.Photos {
-		if _, err := tx.Exec(
-			`INSERT INTO product_photos (product_id, photo)
-				VALUES ($1, $2)`,
-			id,
-			v,
-		); err != nil {
-			a.logger.WithError(err).Error("Error while saving product photos!")
-			return 0, domain.ErrInternalDatabase
-		}
-	}
-
-	if err := tx.Commit(); err != nil {
-		a.logger.WithError(err).Error("Error while trying to commit a database transaction!")
-		return 0, domain.ErrInternalDatabase
-	}
-
 	return id, nil
 }
 
@@ -173,7 +308,7 @@ func (a *adapter) GetProductByID(id int) (*domain.Product, error) {
 
 	if err := a.db.Get(
 		&product,
-		`SELECT id, owner_id, name, per_hour, description
+		`SELECT id, owner_id, name, per_hour, per_day, per_week, description, cancellation_policy, category, archived_at, hidden_at
 				FROM products
 				WHERE id = $1`,
 		id); err != nil {
@@ -184,68 +319,158 @@ func (a *adapter) GetProductByID(id int) (*domain.Product, error) {
 		return nil, domain.ErrInternalDatabase
 	}
 
-	if err := a.db.Select(
-		&product.Photos,
-		`SELECT photo FROM product_photos WHERE product_id = $1`,
-		id); err != nil {
-		a.logger.WithError(err).Error("Error while getting photos by product_id!")
-		return nil, domain.ErrInternalDatabase
+	if err := a.loadProductPhotos(&product, false); err != nil {
+		return nil, err
 	}
 
 	return product.Domain(), nil
 }
 
-func (a *adapter) GetProductsWithPagination(limit, offset int, search string) ([]*domain.Product, int, error) {
-	template := "%" + search + "%"
+func (a *adapter) UpdateProduct(product *domain.Product) error {
+	res, err := a.db.Exec(
+		`UPDATE products
+				SET name = $1, per_hour = $2, per_day = $3, per_week = $4, description = $5,
+				    cancellation_policy = $6, category = $7, updated_at = now()
+				WHERE id = $8 AND archived_at IS NULL`,
+		product.Name,
+		product.PerHour,
+		product.PerDay,
+		product.PerWeek,
+		product.Description,
+		product.CancellationPolicy,
+		product.Category,
+		product.ID,
+	)
+	if err != nil {
+		a.logger.WithError(err).Error("Error while updating product info!")
+		return domain.ErrInternalDatabase
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		a.logger.WithError(err).Error("Error while getting affected rows!")
+		return domain.ErrInternalDatabase
+	}
+
+	if affected == 0 {
+		return domain.ErrNoSuchProduct
+	}
+
+	return nil
+}
+
+func (a *adapter) ArchiveProduct(id int) error {
+	if _, err := a.db.Exec(
+		`UPDATE products
+				SET archived_at = now(), updated_at = now()
+				WHERE id = $1 AND archived_at IS NULL`,
+		id,
+	); err != nil {
+		a.logger.WithError(err).Error("Error while archiving product!")
+		return domain.ErrInternalDatabase
+	}
+
+	return nil
+}
+
+func (a *adapter) GetUserProducts(ownerID int) ([]*domain.Product, error) {
 	var p models.Products
 	if err := a.db.Select(&p,
-		`SELECT id, owner_id, name, per_hour, description
+		`SELECT id, owner_id, name, per_hour, per_day, per_week, description, cancellation_policy, category, archived_at, hidden_at
 				FROM products
-				WHERE name ILIKE $1
-				LIMIT $2 OFFSET $3`,
-		template,
-		limit,
-		offset); err != nil {
-		a.logger.WithError(err).Error("Error while getting products with pagination!")
-		return nil, 0, domain.ErrInternalDatabase
+				WHERE owner_id = $1 AND archived_at IS NULL AND hidden_at IS NULL
+				ORDER BY id`,
+		ownerID); err != nil {
+		a.logger.WithError(err).Error("Error while getting user products!")
+		return nil, domain.ErrInternalDatabase
 	}
 
 	for _, v := range p {
-		if err := a.db.Select(&v.Photos,
-			`SELECT photo FROM product_photos WHERE product_id = $1 ORDER BY created_at LIMIT 1`,
-			v.ID,
-		); err != nil {
-			a.logger.WithError(err).Error("Error while getting main product photo!")
-			return nil, 0, domain.ErrInternalDatabase
+		if err := a.loadProductPhotos(v, true); err != nil {
+			return nil, err
 		}
 	}
 
-	var count int
-	if err := a.db.Get(
-		&count,
-		`SELECT count(id) FROM products`,
-	); err != nil {
-		a.logger.WithError(err).Error("Error while getting product count!")
-		return nil, 0, domain.ErrInternalDatabase
-	}
+	return p.Domain(), nil
+}
+
+func (a *adapter) RentProduct(productID, userID int, from, to time.Time, price domain.Money) error {
+	return a.inTx(func(tx *sqlx.Tx) error {
+		// the renter is locked before the product like by the anonymization, which checks the open orders
+		var renterID int
+		if err := tx.Get(&renterID, `SELECT id FROM users WHERE id = $1 AND deleted_at IS NULL FOR SHARE`, userID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return domain.ErrNoSuchUser
+			}
+			a.logger.WithError(err).Error("Error while locking renter!")
+			return domain.ErrInternalDatabase
+		}
 
-	return p.Domain(), count, nil
+		if err := a.lockProduct(tx, productID); err != nil {
+			return err
+		}
+
+		var blackedOut bool
+		if err := tx.Get(
+			&blackedOut,
+			`SELECT EXISTS(SELECT 1
+				FROM product_blackouts
+				WHERE product_id = $1
+				  AND tstzrange(starts_at, ends_at) && tstzrange($2::timestamptz, $3::timestamptz))`,
+			productID,
+			from,
+			to,
+		); err != nil {
+			a.logger.WithError(err).Error("Error while checking product blackouts!")
+			return domain.ErrInternalDatabase
+		}
+
+		if blackedOut {
+			return domain.ErrProductUnavailable
+		}
+
+		if _, err := tx.Exec(
+			`INSERT INTO orders (user_id, product_id, order_start, order_end, price)
+				VALUES ($1, $2, $3, $4, $5::numeric / 100)`,
+			userID,
+			productID,
+			from,
+			to,
+			int64(price),
+		); err != nil {
+			switch pgErrorCode(err) {
+			case codeExclusionViolation:
+				return domain.ErrProductUnavailable
+			case codeForeignKeyViolation:
+				return domain.ErrNoSuchProduct
+			}
+			a.logger.WithError(err).Error("Error while saving order!")
+			return domain.ErrInternalDatabase
+		}
+
+		return nil
+	})
 }
 
-func (a *adapter) RentProduct(productID, userID int, from, to time.Time) error {
-	if _, err := a.db.Exec(
-		`INSERT INTO orders (user_id, product_id, order_start, order_end)
-				VALUES ($1, $2, $3, $4)`,
-		userID,
+func (a *adapter) GetBookedIntervals(productID int, from, to time.Time) ([]domain.Interval, error) {
+	var intervals models.Intervals
+
+	if err := a.db.Select(&intervals,
+		`SELECT order_start AS starts_at, order_end AS ends_at
+				FROM orders
+				WHERE product_id = $1
+				  AND status NOT IN ('rejected', 'cancelled')
+				  AND tstzrange(order_start, order_end) && tstzrange($2::timestamptz, $3::timestamptz)
+				ORDER BY order_start`,
 		productID,
 		from,
 		to,
 	); err != nil {
-		a.logger.WithError(err).Error("Error while saving order!")
-		return domain.ErrInternalDatabase
+		a.logger.WithError(err).Error("Error while getting booked intervals!")
+		return nil, domain.ErrInternalDatabase
 	}
 
-	return nil
+	return intervals.Domain(), nil
 }
 
 func (a *adapter) GetOrders(userID int, isMine bool) ([]*domain.Order, error) {
@@ -253,10 +478,13 @@ func (a *adapter) GetOrders(userID int, isMine bool) ([]*domain.Order, error) {
 
 	if isMine {
 		if err := a.db.Select(&orders,
-			`SELECT id, user_id, product_id, order_start, order_end, 
-       				extract(EPOCH FROM order_end - order_start) / 3600 AS price 
+			`SELECT id, user_id, product_id, order_start, order_end,
+       				(price * 100)::bigint AS price,
+       				status, created_at, approved_at, rejected_at, activated_at, completed_at, cancelled_at,
+       				cancelled_by, (refund_amount * 100)::bigint AS refund_amount
 					FROM orders 
-					WHERE user_id = $1`,
+					WHERE user_id = $1
+					ORDER BY order_start DESC`,
 			userID,
 		); err != nil {
 			a.logger.WithError(err).Error("Error while getting orders!")
@@ -264,11 +492,14 @@ func (a *adapter) GetOrders(userID int, isMine bool) ([]*domain.Order, error) {
 		}
 	} else {
 		if err := a.db.Select(&orders,
-			`SELECT p.id, user_id, product_id, order_start, order_end,
-       				extract(EPOCH FROM order_end - order_start) / 3600 AS price
-					FROM orders
-					LEFT JOIN products p ON p.id = orders.product_id 
-					WHERE p.owner_id = $1`,
+			`SELECT o.id, o.user_id, o.product_id, o.order_start, o.order_end,
+       				(o.price * 100)::bigint AS price,
+       				o.status, o.created_at, o.approved_at, o.rejected_at, o.activated_at, o.completed_at, o.cancelled_at,
+       				o.cancelled_by, (o.refund_amount * 100)::bigint AS refund_amount
+					FROM orders o
+					LEFT JOIN products p ON p.id = o.product_id 
+					WHERE p.owner_id = $1
+					ORDER BY o.order_start DESC`,
 			userID,
 		); err != nil {
 			a.logger.WithError(err).Error("Error while getting orders!")
@@ -278,3 +509,96 @@ func (a *adapter) GetOrders(userID int, isMine bool) ([]*domain.Order, error) {
 
 	return orders.Domain(), nil
 }
+
+func (a *adapter) GetOrderByID(id int) (*domain.Order, error) {
+	var order models.Order
+
+	if err := a.db.Get(
+		&order,
+		`SELECT id, user_id, product_id, order_start, order_end,
+       			(price * 100)::bigint AS price,
+       			status, created_at, approved_at, rejected_at, activated_at, completed_at, cancelled_at,
+       			cancelled_by, (refund_amount * 100)::bigint AS refund_amount
+				FROM orders
+				WHERE id = $1`,
+		id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNoSuchOrder
+		}
+		a.logger.WithError(err).Error("Error while getting order by id!")
+		return nil, domain.ErrInternalDatabase
+	}
+
+	return order.Domain(), nil
+}
+
+// orderStatusTimestamps maps an order status to the column storing the moment it was reached.
+var orderStatusTimestamps = map[domain.OrderStatus]string{
+	domain.OrderStatusApproved:  "approved_at",
+	domain.OrderStatusRejected:  "rejected_at",
+	domain.OrderStatusActive:    "activated_at",
+	domain.OrderStatusCompleted: "completed_at",
+	domain.OrderStatusCancelled: "cancelled_at",
+}
+
+func (a *adapter) UpdateOrderStatus(id int, from, to domain.OrderStatus) error {
+	column, ok := orderStatusTimestamps[to]
+	if !ok {
+		return domain.ErrIllegalOrderStatus
+	}
+
+	// the status condition protects against concurrent transitions
+	res, err := a.db.Exec(
+		fmt.Sprintf(`UPDATE orders
+				SET status = $1, %s = now(), updated_at = now()
+				WHERE id = $2 AND status = $3`, column),
+		to,
+		id,
+		from,
+	)
+	if err != nil {
+		a.logger.WithError(err).Error("Error while updating order status!")
+		return domain.ErrInternalDatabase
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		a.logger.WithError(err).Error("Error while getting affected rows!")
+		return domain.ErrInternalDatabase
+	}
+
+	if affected == 0 {
+		return domain.ErrIllegalOrderStatus
+	}
+
+	return nil
+}
+
+func (a *adapter) CancelOrder(id int, from domain.OrderStatus, cancelledBy int, refundAmount domain.Money) error {
+	res, err := a.db.Exec(
+		`UPDATE orders
+				SET status = $1, cancelled_at = now(), cancelled_by = $2, refund_amount = $3::numeric / 100, updated_at = now()
+				WHERE id = $4 AND status = $5`,
+		domain.OrderStatusCancelled,
+		cancelledBy,
+		int64(refundAmount),
+		id,
+		from,
+	)
+	if err != nil {
+		a.logger.WithError(err).Error("Error while cancelling order!")
+		return domain.ErrInternalDatabase
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		a.logger.WithError(err).Error("Error while getting affected rows!")
+		return domain.ErrInternalDatabase
+	}
+
+	if affected == 0 {
+		return domain.ErrIllegalOrderStatus
+	}
+
+	return nil
+}
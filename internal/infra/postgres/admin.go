@@ -0,0 +1,180 @@
+package postgres
+
+import (
+	"backend/internal/domain"
+	"backend/internal/infra/postgres/models"
+)
+
+func (a *adapter) GetUsers(limit, offset int) ([]*domain.User, int, error) {
+	var users models.Users
+	if err := a.db.Select(&users,
+		`SELECT id, login, first_name, last_name, email, password_hash, salt, language, email_verified_at,
+					totp_secret, totp_enabled_at, role, blocked_at, block_reason, avatar_key, created_at, deleted_at
+				FROM users
+				ORDER BY id
+				LIMIT $1 OFFSET $2`,
+		limit,
+		offset); err != nil {
+		a.logger.WithError(err).Error("Error while getting users with pagination!")
+		return nil, 0, domain.ErrInternalDatabase
+	}
+
+	var count int
+	if err := a.db.Get(&count, `SELECT count(id) FROM users`); err != nil {
+		a.logger.WithError(err).Error("Error while getting user count!")
+		return nil, 0, domain.ErrInternalDatabase
+	}
+
+	return users.Domain(), count, nil
+}
+
+func (a *adapter) BlockUser(id int, reason string) error {
+	res, err := a.db.Exec(
+		`UPDATE users
+				SET blocked_at = coalesce(blocked_at, now()), block_reason = $1
+				WHERE id = $2`,
+		reason,
+		id,
+	)
+	if err != nil {
+		a.logger.WithError(err).Error("Error while blocking user!")
+		return domain.ErrInternalDatabase
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		a.logger.WithError(err).Error("Error while getting affected rows!")
+		return domain.ErrInternalDatabase
+	}
+
+	if affected == 0 {
+		return domain.ErrNoSuchUser
+	}
+
+	return nil
+}
+
+func (a *adapter) UnblockUser(id int) error {
+	res, err := a.db.Exec(
+		`UPDATE users
+				SET blocked_at = NULL, block_reason = NULL
+				WHERE id = $1`,
+		id,
+	)
+	if err != nil {
+		a.logger.WithError(err).Error("Error while unblocking user!")
+		return domain.ErrInternalDatabase
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		a.logger.WithError(err).Error("Error while getting affected rows!")
+		return domain.ErrInternalDatabase
+	}
+
+	if affected == 0 {
+		return domain.ErrNoSuchUser
+	}
+
+	return nil
+}
+
+func (a *adapter) SetUserRole(id int, role domain.Role) error {
+	res, err := a.db.Exec(
+		`UPDATE users SET role = $1 WHERE id = $2`,
+		role,
+		id,
+	)
+	if err != nil {
+		a.logger.WithError(err).Error("Error while setting user role!")
+		return domain.ErrInternalDatabase
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		a.logger.WithError(err).Error("Error while getting affected rows!")
+		return domain.ErrInternalDatabase
+	}
+
+	if affected == 0 {
+		return domain.ErrNoSuchUser
+	}
+
+	return nil
+}
+
+func (a *adapter) HideProduct(id int, reason string) error {
+	res, err := a.db.Exec(
+		`UPDATE products
+				SET hidden_at = coalesce(hidden_at, now()), hidden_reason = $1, updated_at = now()
+				WHERE id = $2`,
+		reason,
+		id,
+	)
+	if err != nil {
+		a.logger.WithError(err).Error("Error while hiding product!")
+		return domain.ErrInternalDatabase
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		a.logger.WithError(err).Error("Error while getting affected rows!")
+		return domain.ErrInternalDatabase
+	}
+
+	if affected == 0 {
+		return domain.ErrNoSuchProduct
+	}
+
+	return nil
+}
+
+func (a *adapter) UnhideProduct(id int) error {
+	res, err := a.db.Exec(
+		`UPDATE products
+				SET hidden_at = NULL, hidden_reason = NULL, updated_at = now()
+				WHERE id = $1`,
+		id,
+	)
+	if err != nil {
+		a.logger.WithError(err).Error("Error while unhiding product!")
+		return domain.ErrInternalDatabase
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		a.logger.WithError(err).Error("Error while getting affected rows!")
+		return domain.ErrInternalDatabase
+	}
+
+	if affected == 0 {
+		return domain.ErrNoSuchProduct
+	}
+
+	return nil
+}
+
+func (a *adapter) GetAllOrders(limit, offset int) ([]*domain.Order, int, error) {
+	var orders models.Orders
+	if err := a.db.Select(&orders,
+		`SELECT id, user_id, product_id, order_start, order_end,
+       			(price * 100)::bigint AS price,
+       			status, created_at, approved_at, rejected_at, activated_at, completed_at, cancelled_at,
+       			cancelled_by, (refund_amount * 100)::bigint AS refund_amount
+				FROM orders
+				ORDER BY created_at DESC, id DESC
+				LIMIT $1 OFFSET $2`,
+		limit,
+		offset); err != nil {
+		a.logger.WithError(err).Error("Error while getting all orders!")
+		return nil, 0, domain.ErrInternalDatabase
+	}
+
+	var count int
+	if err := a.db.Get(&count, `SELECT count(id) FROM orders`); err != nil {
+		a.logger.WithError(err).Error("Error while getting order count!")
+		return nil, 0, domain.ErrInternalDatabase
+	}
+
+	return orders.Domain(), count, nil
+}
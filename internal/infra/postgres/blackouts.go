@@ -0,0 +1,163 @@
+package postgres
+
+import (
+	"backend/internal/domain"
+	"backend/internal/infra/postgres/models"
+	"database/sql"
+	"errors"
+	"github.com/jmoiron/sqlx"
+	"time"
+)
+
+func (a *adapter) SaveBlackout(blackout *domain.Blackout) (int, error) {
+	var id int
+
+	err := a.inTx(func(tx *sqlx.Tx) error {
+		if err := a.lockProduct(tx, blackout.ProductID); err != nil {
+			return err
+		}
+
+		if err := a.checkBlackoutIsFree(tx, blackout); err != nil {
+			return err
+		}
+
+		if err := tx.Get(
+			&id,
+			`INSERT INTO product_blackouts (product_id, starts_at, ends_at, reason)
+				VALUES ($1, $2, $3, $4)
+				RETURNING id`,
+			blackout.ProductID,
+			blackout.From,
+			blackout.To,
+			blackout.Reason,
+		); err != nil {
+			a.logger.WithError(err).Error("Error while saving blackout!")
+			return domain.ErrInternalDatabase
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (a *adapter) GetBlackoutByID(id int) (*domain.Blackout, error) {
+	var blackout models.Blackout
+
+	if err := a.db.Get(
+		&blackout,
+		`SELECT id, product_id, starts_at, ends_at, reason, created_at
+				FROM product_blackouts
+				WHERE id = $1`,
+		id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNoSuchBlackout
+		}
+		a.logger.WithError(err).Error("Error while getting blackout by id!")
+		return nil, domain.ErrInternalDatabase
+	}
+
+	return blackout.Domain(), nil
+}
+
+// GetBlackouts returns blackouts of the product intersecting the period,
+// all blackouts of the product are returned if the period is not set.
+func (a *adapter) GetBlackouts(productID int, from, to time.Time) ([]*domain.Blackout, error) {
+	var blackouts models.Blackouts
+
+	if from.IsZero() || to.IsZero() {
+		if err := a.db.Select(&blackouts,
+			`SELECT id, product_id, starts_at, ends_at, reason, created_at
+					FROM product_blackouts
+					WHERE product_id = $1
+					ORDER BY starts_at`,
+			productID,
+		); err != nil {
+			a.logger.WithError(err).Error("Error while getting blackouts!")
+			return nil, domain.ErrInternalDatabase
+		}
+	} else {
+		if err := a.db.Select(&blackouts,
+			`SELECT id, product_id, starts_at, ends_at, reason, created_at
+					FROM product_blackouts
+					WHERE product_id = $1
+					  AND tstzrange(starts_at, ends_at) && tstzrange($2::timestamptz, $3::timestamptz)
+					ORDER BY starts_at`,
+			productID,
+			from,
+			to,
+		); err != nil {
+			a.logger.WithError(err).Error("Error while getting blackouts!")
+			return nil, domain.ErrInternalDatabase
+		}
+	}
+
+	return blackouts.Domain(), nil
+}
+
+func (a *adapter) UpdateBlackout(blackout *domain.Blackout) error {
+	return a.inTx(func(tx *sqlx.Tx) error {
+		if err := a.lockProduct(tx, blackout.ProductID); err != nil {
+			return err
+		}
+
+		if err := a.checkBlackoutIsFree(tx, blackout); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(
+			`UPDATE product_blackouts
+				SET starts_at = $1, ends_at = $2, reason = $3
+				WHERE id = $4`,
+			blackout.From,
+			blackout.To,
+			blackout.Reason,
+			blackout.ID,
+		); err != nil {
+			a.logger.WithError(err).Error("Error while updating blackout!")
+			return domain.ErrInternalDatabase
+		}
+
+		return nil
+	})
+}
+
+func (a *adapter) DeleteBlackout(id int) error {
+	if _, err := a.db.Exec(
+		`DELETE FROM product_blackouts WHERE id = $1`,
+		id,
+	); err != nil {
+		a.logger.WithError(err).Error("Error while deleting blackout!")
+		return domain.ErrInternalDatabase
+	}
+
+	return nil
+}
+
+// checkBlackoutIsFree makes sure the blackout does not hide already booked orders.
+func (a *adapter) checkBlackoutIsFree(tx *sqlx.Tx, blackout *domain.Blackout) error {
+	var booked bool
+	if err := tx.Get(
+		&booked,
+		`SELECT EXISTS(SELECT 1
+				FROM orders
+				WHERE product_id = $1
+				  AND status NOT IN ('rejected', 'cancelled', 'completed')
+				  AND tstzrange(order_start, order_end) && tstzrange($2::timestamptz, $3::timestamptz))`,
+		blackout.ProductID,
+		blackout.From,
+		blackout.To,
+	); err != nil {
+		a.logger.WithError(err).Error("Error while checking product orders!")
+		return domain.ErrInternalDatabase
+	}
+
+	if booked {
+		return domain.ErrProductUnavailable
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package postgres
+
+import (
+	"backend/internal/domain"
+	"backend/internal/infra/postgres/models"
+	"strconv"
+	"strings"
+)
+
+// productSortOrders are the ORDER BY clauses of the sorts, relevance is only used along with a search.
+var productSortOrders = map[domain.ProductSort]string{
+	domain.ProductSortRelevance: `id`,
+	domain.ProductSortPriceAsc:  `per_hour, id`,
+	domain.ProductSortPriceDesc: `per_hour DESC, id`,
+	domain.ProductSortNewest:    `created_at DESC, id DESC`,
+	domain.ProductSortRating: `(SELECT avg(r.rating)
+					FROM reviews r
+					WHERE r.subject_id = products.owner_id) DESC NULLS LAST, id`,
+}
+
+// queryArgs collects the positional arguments of a query.
+type queryArgs []interface{}
+
+// add appends the argument and returns its placeholder.
+func (q *queryArgs) add(v interface{}) string {
+	*q = append(*q, v)
+	return "$" + strconv.Itoa(len(*q))
+}
+
+// GetProductsWithPagination finds the listed products by the query. Only the fixed SQL fragments
+// are put into the statement, the values of the query are always passed as arguments.
+// The search matches the words of the names and descriptions, and the misspelled names by their trigram similarity.
+func (a *adapter) GetProductsWithPagination(limit, offset int, query *domain.ProductQuery) ([]*domain.Product, int, error) {
+	var args queryArgs
+	where := []string{`archived_at IS NULL`, `hidden_at IS NULL`}
+	columns := `id, owner_id, name, per_hour, per_day, per_week, description, cancellation_policy, category, archived_at, hidden_at`
+	order := productSortOrders[query.Sort]
+
+	if query.Search != "" {
+		search := args.add(query.Search)
+		where = append(where, `(search_vector @@ product_search_query(`+search+`) OR `+search+` <% name)`)
+		columns += `,
+					ts_headline((product_search_dictionaries())[1],
+					            replace(replace(replace(description, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'),
+					            product_search_query(` + search + `),
+					            'StartSel=<mark>, StopSel=</mark>, MaxWords=30, MinWords=10, MaxFragments=2') AS snippet`
+		if query.Sort == domain.ProductSortRelevance {
+			order = `ts_rank_cd(search_vector, product_search_query(` + search + `)) + word_similarity(` + search + `, name) DESC, id`
+		}
+	}
+
+	if query.MinPerHour != nil {
+		where = append(where, `per_hour >= `+args.add(*query.MinPerHour))
+	}
+
+	if query.MaxPerHour != nil {
+		where = append(where, `per_hour <= `+args.add(*query.MaxPerHour))
+	}
+
+	if query.Category != "" {
+		where = append(where, `category = `+args.add(query.Category))
+	}
+
+	if query.OwnerID != nil {
+		where = append(where, `owner_id = `+args.add(*query.OwnerID))
+	}
+
+	if query.AvailableFrom != nil && query.AvailableTo != nil {
+		window := `tstzrange(` + args.add(*query.AvailableFrom) + `::timestamptz, ` + args.add(*query.AvailableTo) + `::timestamptz)`
+		where = append(where,
+			`NOT EXISTS(SELECT 1
+					FROM orders o
+					WHERE o.product_id = products.id
+					  AND o.status NOT IN ('rejected', 'cancelled')
+					  AND tstzrange(o.order_start, o.order_end) && `+window+`)`,
+			`NOT EXISTS(SELECT 1
+					FROM product_blackouts b
+					WHERE b.product_id = products.id
+					  AND tstzrange(b.starts_at, b.ends_at) && `+window+`)`,
+		)
+	}
+
+	conditions := strings.Join(where, "\n\t\t\t\t  AND ")
+
+	var count int
+	if err := a.db.Get(&count, `SELECT count(id) FROM products WHERE `+conditions, args...); err != nil {
+		a.logger.WithError(err).Error("Error while getting product count!")
+		return nil, 0, domain.ErrInternalDatabase
+	}
+
+	pageArgs := append(queryArgs{}, args...)
+	limitArg, offsetArg := pageArgs.add(limit), pageArgs.add(offset)
+
+	var p models.Products
+	if err := a.db.Select(&p,
+		`SELECT `+columns+`
+				FROM products
+				WHERE `+conditions+`
+				ORDER BY `+order+`
+				LIMIT `+limitArg+` OFFSET `+offsetArg,
+		pageArgs...); err != nil {
+		a.logger.WithError(err).Error("Error while getting products with pagination!")
+		return nil, 0, domain.ErrInternalDatabase
+	}
+
+	for _, v := range p {
+		if err := a.loadProductPhotos(v, true); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return p.Domain(), count, nil
+}
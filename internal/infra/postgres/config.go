@@ -17,6 +17,8 @@ type Config struct {
 	ConnMaxLifeTime time.Duration `long:"conn-max-life-time" env:"CONN_MAX_LIFE_TIME" default:"5m" description:"database max connection life time"`
 
 	MigrationsSourceURL string `long:"migrations-source-url" env:"MIGRATIONS_SOURCE_URL" default:"file://migrations"`
+
+	SearchDictionaries []string `long:"search-dictionary" env:"SEARCH_DICTIONARIES" env-delim:"," default:"russian" default:"english" description:"text search configurations the products are indexed with"`
 }
 
 func (c *Config) ConnectionString() string {
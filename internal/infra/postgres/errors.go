@@ -0,0 +1,31 @@
+package postgres
+
+import (
+	"errors"
+	"github.com/jackc/pgconn"
+)
+
+// PostgreSQL error codes, see https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	codeForeignKeyViolation = "23503"
+	codeUniqueViolation     = "23505"
+	codeExclusionViolation  = "23P01"
+)
+
+func pgErrorCode(err error) string {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code
+	}
+
+	return ""
+}
+
+func pgConstraintName(err error) string {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.ConstraintName
+	}
+
+	return ""
+}
@@ -0,0 +1,161 @@
+package postgres
+
+import (
+	"backend/internal/domain"
+	"backend/internal/infra/postgres/models"
+	"database/sql"
+	"errors"
+	"github.com/jmoiron/sqlx"
+)
+
+func (a *adapter) SaveUserWithIdentity(user *domain.User, identity *domain.Identity, emailVerified bool) (int, error) {
+	var id int
+	err := a.inTx(func(tx *sqlx.Tx) error {
+		userID, err := a.insertUser(tx, user, emailVerified)
+		if err != nil {
+			return err
+		}
+
+		identity.UserID = userID
+		if err := a.insertIdentity(tx, identity); err != nil {
+			return err
+		}
+
+		id = userID
+		return nil
+	})
+
+	return id, err
+}
+
+func (a *adapter) SaveIdentity(identity *domain.Identity) error {
+	return a.insertIdentity(a.db, identity)
+}
+
+func (a *adapter) insertIdentity(e sqlx.Execer, identity *domain.Identity) error {
+	if _, err := e.Exec(
+		`INSERT INTO user_identities (user_id, provider, subject, email)
+				VALUES ($1, $2, $3, $4)`,
+		identity.UserID,
+		identity.Provider,
+		identity.Subject,
+		identity.Email,
+	); err != nil {
+		if pgErrorCode(err) == codeUniqueViolation {
+			return domain.ErrIdentityTaken
+		}
+		a.logger.WithError(err).Error("Error while saving identity!")
+		return domain.ErrInternalDatabase
+	}
+
+	return nil
+}
+
+func (a *adapter) GetIdentity(provider, subject string) (*domain.Identity, error) {
+	var identity models.Identity
+
+	if err := a.db.Get(
+		&identity,
+		`SELECT id, user_id, provider, subject, email, created_at
+				FROM user_identities
+				WHERE provider = $1 AND subject = $2`,
+		provider,
+		subject); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNoSuchIdentity
+		}
+		a.logger.WithError(err).Error("Error while getting identity!")
+		return nil, domain.ErrInternalDatabase
+	}
+
+	return identity.Domain(), nil
+}
+
+func (a *adapter) GetIdentities(userID int) ([]*domain.Identity, error) {
+	var identities models.Identities
+
+	if err := a.db.Select(&identities,
+		`SELECT id, user_id, provider, subject, email, created_at
+				FROM user_identities
+				WHERE user_id = $1
+				ORDER BY id`,
+		userID,
+	); err != nil {
+		a.logger.WithError(err).Error("Error while getting identities!")
+		return nil, domain.ErrInternalDatabase
+	}
+
+	return identities.Domain(), nil
+}
+
+func (a *adapter) DeleteIdentity(id int) error {
+	if _, err := a.db.Exec(
+		`DELETE FROM user_identities
+				WHERE id = $1`,
+		id,
+	); err != nil {
+		a.logger.WithError(err).Error("Error while deleting identity!")
+		return domain.ErrInternalDatabase
+	}
+
+	return nil
+}
+
+func (a *adapter) SaveOIDCLogin(login *domain.OIDCLogin) error {
+	if _, err := a.db.Exec(
+		`INSERT INTO oidc_logins (state_hash, browser_token_hash, provider, code_verifier, nonce, user_id, expires_at)
+				VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		login.StateHash,
+		login.BrowserTokenHash,
+		login.Provider,
+		login.CodeVerifier,
+		login.Nonce,
+		login.UserID,
+		login.ExpiresAt,
+	); err != nil {
+		a.logger.WithError(err).Error("Error while saving OIDC login!")
+		return domain.ErrInternalDatabase
+	}
+
+	return nil
+}
+
+func (a *adapter) GetOIDCLoginByStateHash(hash []byte) (*domain.OIDCLogin, error) {
+	var login models.OIDCLogin
+
+	if err := a.db.Get(
+		&login,
+		`SELECT id, state_hash, browser_token_hash, provider, code_verifier, nonce, user_id, expires_at, used_at
+				FROM oidc_logins
+				WHERE state_hash = $1`,
+		hash); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrInvalidToken
+		}
+		a.logger.WithError(err).Error("Error while getting OIDC login!")
+		return nil, domain.ErrInternalDatabase
+	}
+
+	return login.Domain(), nil
+}
+
+func (a *adapter) UseOIDCLogin(id int) (bool, error) {
+	res, err := a.db.Exec(
+		`UPDATE oidc_logins
+				SET used_at = now()
+				WHERE id = $1 AND used_at IS NULL`,
+		id,
+	)
+	if err != nil {
+		a.logger.WithError(err).Error("Error while using OIDC login!")
+		return false, domain.ErrInternalDatabase
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		a.logger.WithError(err).Error("Error while getting affected rows!")
+		return false, domain.ErrInternalDatabase
+	}
+
+	return affected == 1, nil
+}
@@ -0,0 +1,98 @@
+package postgres
+
+import (
+	"backend/internal/domain"
+	"backend/internal/infra/postgres/models"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+func (a *adapter) GetLoginThrottle(key string) (*domain.LoginThrottle, error) {
+	var throttle models.LoginThrottle
+
+	if err := a.db.Get(
+		&throttle,
+		`SELECT key, failures, last_failure_at
+				FROM login_throttles
+				WHERE key = $1`,
+		key); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return &domain.LoginThrottle{Key: key}, nil
+		}
+		a.logger.WithError(err).Error("Error while getting login throttle!")
+		return nil, domain.ErrInternalDatabase
+	}
+
+	return throttle.Domain(), nil
+}
+
+func (a *adapter) RegisterLoginFailure(key string, window time.Duration) (*domain.LoginThrottle, error) {
+	var throttle models.LoginThrottle
+
+	if err := a.db.Get(
+		&throttle,
+		`INSERT INTO login_throttles (key, failures, last_failure_at)
+				VALUES ($1, 1, now())
+				ON CONFLICT (key) DO UPDATE
+				SET failures = CASE
+						WHEN login_throttles.last_failure_at > now() - $2::bigint * interval '1 microsecond'
+						THEN login_throttles.failures + 1
+						ELSE 1
+					END,
+					last_failure_at = now()
+				RETURNING key, failures, last_failure_at`,
+		key,
+		window.Microseconds(),
+	); err != nil {
+		a.logger.WithError(err).Error("Error while registering login failure!")
+		return nil, domain.ErrInternalDatabase
+	}
+
+	return throttle.Domain(), nil
+}
+
+func (a *adapter) UndoLoginFailure(key string) error {
+	if _, err := a.db.Exec(
+		`UPDATE login_throttles
+				SET failures = failures - 1
+				WHERE key = $1
+				  AND failures > 0`,
+		key,
+	); err != nil {
+		a.logger.WithError(err).Error("Error while undoing login failure!")
+		return domain.ErrInternalDatabase
+	}
+
+	return nil
+}
+
+func (a *adapter) ResetLoginFailures(key string) error {
+	if _, err := a.db.Exec(
+		`DELETE FROM login_throttles
+				WHERE key = $1`,
+		key,
+	); err != nil {
+		a.logger.WithError(err).Error("Error while resetting login failures!")
+		return domain.ErrInternalDatabase
+	}
+
+	return nil
+}
+
+func (a *adapter) SaveLoginAttempt(attempt *domain.LoginAttempt) error {
+	if _, err := a.db.Exec(
+		`INSERT INTO login_attempts (login, user_id, ip, user_agent, reason)
+				VALUES ($1, $2, $3, $4, $5)`,
+		attempt.Login,
+		attempt.UserID,
+		attempt.IP,
+		attempt.UserAgent,
+		attempt.Reason,
+	); err != nil {
+		a.logger.WithError(err).Error("Error while saving login attempt!")
+		return domain.ErrInternalDatabase
+	}
+
+	return nil
+}
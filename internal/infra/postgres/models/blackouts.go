@@ -0,0 +1,53 @@
+package models
+
+import (
+	"backend/internal/domain"
+	"time"
+)
+
+type Blackout struct {
+	ID        int       `db:"id"`
+	ProductID int       `db:"product_id"`
+	StartsAt  time.Time `db:"starts_at"`
+	EndsAt    time.Time `db:"ends_at"`
+	Reason    *string   `db:"reason"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func (b *Blackout) Domain() *domain.Blackout {
+	return &domain.Blackout{
+		ID:        b.ID,
+		ProductID: b.ProductID,
+		From:      b.StartsAt,
+		To:        b.EndsAt,
+		Reason:    b.Reason,
+		CreatedAt: b.CreatedAt,
+	}
+}
+
+type Blackouts []*Blackout
+
+func (bb Blackouts) Domain() []*domain.Blackout {
+	dd := make([]*domain.Blackout, 0)
+	for _, v := range bb {
+		dd = append(dd, v.Domain())
+	}
+
+	return dd
+}
+
+type Interval struct {
+	From time.Time `db:"starts_at"`
+	To   time.Time `db:"ends_at"`
+}
+
+type Intervals []*Interval
+
+func (ii Intervals) Domain() []domain.Interval {
+	dd := make([]domain.Interval, 0)
+	for _, v := range ii {
+		dd = append(dd, domain.Interval{From: v.From, To: v.To})
+	}
+
+	return dd
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"backend/internal/domain"
+	"time"
+)
+
+type Identity struct {
+	ID        int       `db:"id"`
+	UserID    int       `db:"user_id"`
+	Provider  string    `db:"provider"`
+	Subject   string    `db:"subject"`
+	Email     *string   `db:"email"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func (i *Identity) Domain() *domain.Identity {
+	return &domain.Identity{
+		ID:        i.ID,
+		UserID:    i.UserID,
+		Provider:  i.Provider,
+		Subject:   i.Subject,
+		Email:     i.Email,
+		CreatedAt: i.CreatedAt,
+	}
+}
+
+type Identities []*Identity
+
+func (ii Identities) Domain() []*domain.Identity {
+	dd := make([]*domain.Identity, 0)
+	for _, v := range ii {
+		dd = append(dd, v.Domain())
+	}
+
+	return dd
+}
+
+type OIDCLogin struct {
+	ID               int        `db:"id"`
+	StateHash        []byte     `db:"state_hash"`
+	BrowserTokenHash []byte     `db:"browser_token_hash"`
+	Provider         string     `db:"provider"`
+	CodeVerifier     string     `db:"code_verifier"`
+	Nonce            string     `db:"nonce"`
+	UserID           *int       `db:"user_id"`
+	ExpiresAt        time.Time  `db:"expires_at"`
+	UsedAt           *time.Time `db:"used_at"`
+}
+
+func (l *OIDCLogin) Domain() *domain.OIDCLogin {
+	return &domain.OIDCLogin{
+		ID:               l.ID,
+		StateHash:        l.StateHash,
+		BrowserTokenHash: l.BrowserTokenHash,
+		Provider:         l.Provider,
+		CodeVerifier:     l.CodeVerifier,
+		Nonce:            l.Nonce,
+		UserID:           l.UserID,
+		ExpiresAt:        l.ExpiresAt,
+		UsedAt:           l.UsedAt,
+	}
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"backend/internal/domain"
+	"time"
+)
+
+type LoginThrottle struct {
+	Key           string    `db:"key"`
+	Failures      int       `db:"failures"`
+	LastFailureAt time.Time `db:"last_failure_at"`
+}
+
+func (t *LoginThrottle) Domain() *domain.LoginThrottle {
+	return &domain.LoginThrottle{
+		Key:           t.Key,
+		Failures:      t.Failures,
+		LastFailureAt: t.LastFailureAt,
+	}
+}
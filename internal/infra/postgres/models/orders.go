@@ -6,22 +6,48 @@ import (
 )
 
 type Order struct {
-	ID         int       `db:"id"`
-	OrderStart time.Time `db:"order_start"`
-	OrderEnd   time.Time `db:"order_end"`
-	UserID     int       `db:"user_id"`
-	ProductID  int       `db:"product_id"`
-	Price      float64   `db:"price"`
+	ID          int        `db:"id"`
+	OrderStart  time.Time  `db:"order_start"`
+	OrderEnd    time.Time  `db:"order_end"`
+	UserID      int        `db:"user_id"`
+	ProductID   int        `db:"product_id"`
+	Price       int64      `db:"price"`
+	Status      string     `db:"status"`
+	CreatedAt   time.Time  `db:"created_at"`
+	ApprovedAt  *time.Time `db:"approved_at"`
+	RejectedAt  *time.Time `db:"rejected_at"`
+	ActivatedAt *time.Time `db:"activated_at"`
+	CompletedAt *time.Time `db:"completed_at"`
+	CancelledAt *time.Time `db:"cancelled_at"`
+
+	CancelledBy  *int   `db:"cancelled_by"`
+	RefundAmount *int64 `db:"refund_amount"`
 }
 
 func (o *Order) Domain() *domain.Order {
+	var refundAmount *domain.Money
+	if o.RefundAmount != nil {
+		m := domain.Money(*o.RefundAmount)
+		refundAmount = &m
+	}
+
 	return &domain.Order{
-		ID:         o.ID,
-		OrderStart: o.OrderStart,
-		OrderEnd:   o.OrderEnd,
-		UserID:     o.UserID,
-		ProductID:  o.ProductID,
-		Price:      o.Price,
+		ID:          o.ID,
+		OrderStart:  o.OrderStart,
+		OrderEnd:    o.OrderEnd,
+		UserID:      o.UserID,
+		ProductID:   o.ProductID,
+		Price:       domain.Money(o.Price),
+		Status:      domain.OrderStatus(o.Status),
+		CreatedAt:   o.CreatedAt,
+		ApprovedAt:  o.ApprovedAt,
+		RejectedAt:  o.RejectedAt,
+		ActivatedAt: o.ActivatedAt,
+		CompletedAt: o.CompletedAt,
+		CancelledAt: o.CancelledAt,
+
+		CancelledBy:  o.CancelledBy,
+		RefundAmount: refundAmount,
 	}
 }
 
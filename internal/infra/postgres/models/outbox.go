@@ -0,0 +1,32 @@
+package models
+
+import "backend/internal/domain"
+
+type Email struct {
+	ID        int    `db:"id"`
+	Recipient string `db:"recipient"`
+	Subject   string `db:"subject"`
+	Body      string `db:"body"`
+	Attempts  int    `db:"attempts"`
+}
+
+func (e *Email) Domain() *domain.Email {
+	return &domain.Email{
+		ID:       e.ID,
+		To:       e.Recipient,
+		Subject:  e.Subject,
+		Body:     e.Body,
+		Attempts: e.Attempts,
+	}
+}
+
+type Emails []*Email
+
+func (ee Emails) Domain() []*domain.Email {
+	dd := make([]*domain.Email, 0)
+	for _, v := range ee {
+		dd = append(dd, v.Domain())
+	}
+
+	return dd
+}
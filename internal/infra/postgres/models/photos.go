@@ -0,0 +1,105 @@
+package models
+
+import (
+	"backend/internal/domain"
+	"time"
+)
+
+type Photo struct {
+	ID            int       `db:"id"`
+	ProductID     int       `db:"product_id"`
+	Photo         *string   `db:"photo"`
+	BlobKey       *string   `db:"blob_key"`
+	ContentType   *string   `db:"content_type"`
+	Size          *int64    `db:"size"`
+	Width         *int      `db:"width"`
+	Height        *int      `db:"height"`
+	Blurhash      *string   `db:"blurhash"`
+	DominantColor *string   `db:"dominant_color"`
+	Position      int       `db:"position"`
+	Cover         bool      `db:"cover"`
+	Caption       *string   `db:"caption"`
+	CreatedAt     time.Time `db:"created_at"`
+
+	Variants PhotoVariants `db:"-"`
+}
+
+func (p *Photo) Domain() *domain.Photo {
+	d := &domain.Photo{
+		ID:        p.ID,
+		ProductID: p.ProductID,
+		BlobKey:   p.BlobKey,
+		Variants:  p.Variants.Domain(),
+		Position:  p.Position,
+		Cover:     p.Cover,
+		Caption:   p.Caption,
+		CreatedAt: p.CreatedAt,
+	}
+
+	if p.Photo != nil {
+		d.URL = *p.Photo
+	}
+	if p.ContentType != nil {
+		d.ContentType = *p.ContentType
+	}
+	if p.Size != nil {
+		d.Size = *p.Size
+	}
+	if p.Width != nil {
+		d.Width = *p.Width
+	}
+	if p.Height != nil {
+		d.Height = *p.Height
+	}
+	if p.Blurhash != nil {
+		d.Blurhash = *p.Blurhash
+	}
+	if p.DominantColor != nil {
+		d.DominantColor = *p.DominantColor
+	}
+
+	return d
+}
+
+type Photos []*Photo
+
+func (pp Photos) Domain() []*domain.Photo {
+	dd := make([]*domain.Photo, 0)
+	for _, v := range pp {
+		dd = append(dd, v.Domain())
+	}
+
+	return dd
+}
+
+type PhotoVariant struct {
+	PhotoID     int    `db:"photo_id"`
+	Name        string `db:"name"`
+	BlobKey     string `db:"blob_key"`
+	ContentType string `db:"content_type"`
+	Width       int    `db:"width"`
+	Height      int    `db:"height"`
+	Size        int64  `db:"size"`
+}
+
+func (v *PhotoVariant) Domain() *domain.PhotoVariant {
+	return &domain.PhotoVariant{
+		Name:        domain.PhotoVariantName(v.Name),
+		BlobKey:     v.BlobKey,
+		ContentType: v.ContentType,
+		Width:       v.Width,
+		Height:      v.Height,
+		Size:        v.Size,
+	}
+}
+
+type PhotoVariants []*PhotoVariant
+
+func (vv PhotoVariants) Domain() []*domain.PhotoVariant {
+	dd := make([]*domain.PhotoVariant, 0)
+	for _, v := range vv {
+		dd = append(dd, v.Domain())
+	}
+
+	return dd
+}
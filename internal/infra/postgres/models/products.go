@@ -1,14 +1,25 @@
 package models
 
-import "backend/internal/domain"
+import (
+	"backend/internal/domain"
+	"time"
+)
 
 type Product struct {
 	ID          int      `json:"id"`
 	OwnerID     int      `db:"owner_id"`
 	Name        string   `db:"name"`
 	PerHour     float64  `db:"per_hour"`
+	PerDay      *float64 `db:"per_day"`
+	PerWeek     *float64 `db:"per_week"`
 	Description *string  `db:"description"`
-	Photos      []string `db:"photos"`
+	Photos      Photos   `db:"-"`
+
+	CancellationPolicy string     `db:"cancellation_policy"`
+	Category           string     `db:"category"`
+	ArchivedAt         *time.Time `db:"archived_at"`
+	HiddenAt           *time.Time `db:"hidden_at"`
+	Snippet            *string    `db:"snippet"`
 }
 
 func (p *Product) Domain() *domain.Product {
@@ -17,8 +28,16 @@ func (p *Product) Domain() *domain.Product {
 		OwnerID:     p.OwnerID,
 		Name:        p.Name,
 		PerHour:     p.PerHour,
+		PerDay:      p.PerDay,
+		PerWeek:     p.PerWeek,
 		Description: p.Description,
-		Photos:      p.Photos,
+		Photos:      p.Photos.Domain(),
+
+		CancellationPolicy: domain.CancellationPolicy(p.CancellationPolicy),
+		Category:           domain.ProductCategory(p.Category),
+		ArchivedAt:         p.ArchivedAt,
+		HiddenAt:           p.HiddenAt,
+		Snippet:            p.Snippet,
 	}
 }
 
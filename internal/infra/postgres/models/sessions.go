@@ -0,0 +1,70 @@
+package models
+
+import (
+	"backend/internal/domain"
+	"time"
+)
+
+type Session struct {
+	ID           int        `db:"id"`
+	UserID       int        `db:"user_id"`
+	UserAgent    *string    `db:"user_agent"`
+	IP           *string    `db:"ip"`
+	CreatedAt    time.Time  `db:"created_at"`
+	LastUsedAt   time.Time  `db:"last_used_at"`
+	ExpiresAt    time.Time  `db:"expires_at"`
+	RevokedAt    *time.Time `db:"revoked_at"`
+	RevokeReason *string    `db:"revoke_reason"`
+}
+
+func (s *Session) Domain() *domain.Session {
+	d := &domain.Session{
+		ID:           s.ID,
+		UserID:       s.UserID,
+		CreatedAt:    s.CreatedAt,
+		LastUsedAt:   s.LastUsedAt,
+		ExpiresAt:    s.ExpiresAt,
+		RevokedAt:    s.RevokedAt,
+		RevokeReason: s.RevokeReason,
+	}
+
+	if s.UserAgent != nil {
+		d.UserAgent = *s.UserAgent
+	}
+	if s.IP != nil {
+		d.IP = *s.IP
+	}
+
+	return d
+}
+
+type Sessions []*Session
+
+func (ss Sessions) Domain() []*domain.Session {
+	dd := make([]*domain.Session, 0)
+	for _, v := range ss {
+		dd = append(dd, v.Domain())
+	}
+
+	return dd
+}
+
+type RefreshToken struct {
+	ID        int        `db:"id"`
+	SessionID int        `db:"session_id"`
+	TokenHash []byte     `db:"token_hash"`
+	CreatedAt time.Time  `db:"created_at"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	UsedAt    *time.Time `db:"used_at"`
+}
+
+func (t *RefreshToken) Domain() *domain.RefreshToken {
+	return &domain.RefreshToken{
+		ID:        t.ID,
+		SessionID: t.SessionID,
+		Hash:      t.TokenHash,
+		CreatedAt: t.CreatedAt,
+		ExpiresAt: t.ExpiresAt,
+		UsedAt:    t.UsedAt,
+	}
+}
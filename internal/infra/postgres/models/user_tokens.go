@@ -0,0 +1,28 @@
+package models
+
+import (
+	"backend/internal/domain"
+	"time"
+)
+
+type UserToken struct {
+	ID        int        `db:"id"`
+	UserID    int        `db:"user_id"`
+	Purpose   string     `db:"purpose"`
+	TokenHash []byte     `db:"token_hash"`
+	CreatedAt time.Time  `db:"created_at"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	UsedAt    *time.Time `db:"used_at"`
+}
+
+func (t *UserToken) Domain() *domain.UserToken {
+	return &domain.UserToken{
+		ID:        t.ID,
+		UserID:    t.UserID,
+		Purpose:   t.Purpose,
+		Hash:      t.TokenHash,
+		CreatedAt: t.CreatedAt,
+		ExpiresAt: t.ExpiresAt,
+		UsedAt:    t.UsedAt,
+	}
+}
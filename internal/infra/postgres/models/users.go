@@ -1,25 +1,59 @@
 package models
 
-import "backend/internal/domain"
+import (
+	"backend/internal/domain"
+	"time"
+)
 
 type User struct {
-	ID           int    `db:"id"`
-	FirstName    string `db:"first_name"`
-	LastName     string `db:"last_name"`
-	Login        string `db:"login"`
-	Email        string `db:"email"`
-	PasswordHash []byte `db:"password_hash"`
-	Salt         []byte `db:"salt"`
+	ID              int        `db:"id"`
+	FirstName       string     `db:"first_name"`
+	LastName        string     `db:"last_name"`
+	Login           string     `db:"login"`
+	Email           string     `db:"email"`
+	PasswordHash    []byte     `db:"password_hash"`
+	Salt            []byte     `db:"salt"`
+	Language        *string    `db:"language"`
+	EmailVerifiedAt *time.Time `db:"email_verified_at"`
+	TOTPSecret      []byte     `db:"totp_secret"`
+	TOTPEnabledAt   *time.Time `db:"totp_enabled_at"`
+	Role            string     `db:"role"`
+	BlockedAt       *time.Time `db:"blocked_at"`
+	BlockReason     *string    `db:"block_reason"`
+	AvatarKey       *string    `db:"avatar_key"`
+	CreatedAt       time.Time  `db:"created_at"`
+	DeletedAt       *time.Time `db:"deleted_at"`
 }
 
 func (u *User) Domain() *domain.User {
 	return &domain.User{
-		ID:           u.ID,
-		FirstName:    u.FirstName,
-		LastName:     u.LastName,
-		Login:        u.Login,
-		Email:        u.Email,
-		PasswordHash: u.PasswordHash,
-		Salt:         u.Salt,
+		ID:              u.ID,
+		FirstName:       u.FirstName,
+		LastName:        u.LastName,
+		Login:           u.Login,
+		Email:           u.Email,
+		PasswordHash:    u.PasswordHash,
+		Salt:            u.Salt,
+		Language:        u.Language,
+		EmailVerifiedAt: u.EmailVerifiedAt,
+		TOTPSecret:      u.TOTPSecret,
+		TOTPEnabledAt:   u.TOTPEnabledAt,
+		Role:            domain.Role(u.Role),
+		BlockedAt:       u.BlockedAt,
+		BlockReason:     u.BlockReason,
+		AvatarKey:       u.AvatarKey,
+		CreatedAt:       u.CreatedAt,
+		DeletedAt:       u.DeletedAt,
 	}
 }
+
+type Users []*User
+
+func (uu Users) Domain() []*domain.User {
+	dd := make([]*domain.User, 0)
+	for _, v := range uu {
+		dd = append(dd, v.Domain())
+	}
+
+	return dd
+}
@@ -0,0 +1,156 @@
+package postgres
+
+import (
+	"backend/internal/domain"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestAdapter connects to the database of the SHARITO_TEST_POSTGRES_* variables and migrates it,
+// the test is skipped if SHARITO_TEST_POSTGRES_HOST is not set.
+func newTestAdapter(t *testing.T) *adapter {
+	host := os.Getenv("SHARITO_TEST_POSTGRES_HOST")
+	if host == "" {
+		t.Skip("SHARITO_TEST_POSTGRES_HOST is not set")
+	}
+
+	port, err := strconv.Atoi(envOr("SHARITO_TEST_POSTGRES_PORT", "5432"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	migrations, err := filepath.Abs("../../../migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+
+	db, err := NewAdapter(logger, &Config{
+		Host:                host,
+		Port:                port,
+		User:                envOr("SHARITO_TEST_POSTGRES_USER", "postgres"),
+		Password:            envOr("SHARITO_TEST_POSTGRES_PASSWORD", "postgres"),
+		Name:                envOr("SHARITO_TEST_POSTGRES_NAME", "sharito_test"),
+		MaxOpenConns:        25,
+		MaxIdleConns:        10,
+		ConnMaxLifeTime:     time.Minute,
+		MigrationsSourceURL: "file://" + filepath.ToSlash(migrations),
+		SearchDictionaries:  []string{"russian", "english"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := db.(*adapter)
+	t.Cleanup(func() { _ = a.db.Close() })
+
+	return a
+}
+
+func envOr(name, value string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+
+	return value
+}
+
+// newTestUser saves a user with a unique login, it is removed with its orders and products after the test.
+func newTestUser(t *testing.T, a *adapter) int {
+	login := fmt.Sprintf("test-%d", time.Now().UnixNano())
+
+	id, err := a.SaveUser(&domain.User{
+		Login:        login,
+		FirstName:    "Test",
+		LastName:     "User",
+		Email:        login + "@example.com",
+		PasswordHash: []byte("hash"),
+		Salt:         []byte("salt"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		for _, query := range []string{
+			`DELETE FROM orders WHERE user_id = $1 OR product_id IN (SELECT id FROM products WHERE owner_id = $1)`,
+			`DELETE FROM products WHERE owner_id = $1`,
+			`DELETE FROM users WHERE id = $1`,
+		} {
+			if _, err := a.db.Exec(query, id); err != nil {
+				t.Errorf("Error while removing test user: %v", err)
+			}
+		}
+	})
+
+	return id
+}
+
+func TestRentProductConcurrently(t *testing.T) {
+	a := newTestAdapter(t)
+
+	ownerID := newTestUser(t, a)
+	renterID := newTestUser(t, a)
+
+	productID, err := a.SaveProduct(&domain.Product{
+		OwnerID:            ownerID,
+		Name:               "Drill",
+		PerHour:            100,
+		CancellationPolicy: domain.CancellationPolicyFlexible,
+		Category:           domain.ProductCategoryTools,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// every interval overlaps all the others, so only one of the bookings may succeed
+	const renters = 20
+	start := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+
+	var wg sync.WaitGroup
+	errs := make([]error, renters)
+	ready := make(chan struct{})
+	for i := 0; i < renters; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-ready
+
+			from := start.Add(time.Duration(i) * time.Minute)
+			errs[i] = a.RentProduct(productID, renterID, from, from.Add(2*time.Hour), domain.Money(20000))
+		}(i)
+	}
+	close(ready)
+	wg.Wait()
+
+	succeeded := 0
+	for i, err := range errs {
+		switch err {
+		case nil:
+			succeeded++
+		case domain.ErrProductUnavailable:
+		default:
+			t.Errorf("RentProduct() #%d error = %v, want nil or %v", i, err, domain.ErrProductUnavailable)
+		}
+	}
+	if succeeded != 1 {
+		t.Errorf("%d of %d concurrent bookings succeeded, want 1", succeeded, renters)
+	}
+
+	var orders int
+	if err := a.db.Get(&orders, `SELECT count(*) FROM orders WHERE product_id = $1`, productID); err != nil {
+		t.Fatal(err)
+	}
+	if orders != 1 {
+		t.Errorf("%d orders saved, want 1", orders)
+	}
+}
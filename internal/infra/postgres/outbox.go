@@ -0,0 +1,82 @@
+package postgres
+
+import (
+	"backend/internal/domain"
+	"backend/internal/infra/postgres/models"
+	"time"
+)
+
+// outboxClaimLease postpones the claimed emails, so that an instance crashed while sending
+// does not block them forever.
+const outboxClaimLease = 5 * time.Minute
+
+func (a *adapter) EnqueueEmail(email *domain.Email) error {
+	if _, err := a.db.Exec(
+		`INSERT INTO email_outbox (recipient, subject, body)
+				VALUES ($1, $2, $3)`,
+		email.To,
+		email.Subject,
+		email.Body,
+	); err != nil {
+		a.logger.WithError(err).Error("Error while enqueuing email!")
+		return domain.ErrInternalDatabase
+	}
+
+	return nil
+}
+
+func (a *adapter) ClaimPendingEmails(limit, maxAttempts int) ([]*domain.Email, error) {
+	var emails models.Emails
+
+	if err := a.db.Select(&emails,
+		`UPDATE email_outbox
+				SET attempts = attempts + 1, next_attempt_at = $1
+				WHERE id IN (
+					SELECT id
+					FROM email_outbox
+					WHERE sent_at IS NULL AND attempts < $2 AND next_attempt_at <= now()
+					ORDER BY next_attempt_at
+					LIMIT $3
+					FOR UPDATE SKIP LOCKED
+				)
+				RETURNING id, recipient, subject, body, attempts`,
+		time.Now().Add(outboxClaimLease),
+		maxAttempts,
+		limit,
+	); err != nil {
+		a.logger.WithError(err).Error("Error while claiming pending emails!")
+		return nil, domain.ErrInternalDatabase
+	}
+
+	return emails.Domain(), nil
+}
+
+func (a *adapter) MarkEmailSent(id int) error {
+	if _, err := a.db.Exec(
+		`UPDATE email_outbox
+				SET sent_at = now(), last_error = NULL
+				WHERE id = $1`,
+		id,
+	); err != nil {
+		a.logger.WithError(err).Error("Error while marking email sent!")
+		return domain.ErrInternalDatabase
+	}
+
+	return nil
+}
+
+func (a *adapter) MarkEmailFailed(id int, reason string, retryAt time.Time) error {
+	if _, err := a.db.Exec(
+		`UPDATE email_outbox
+				SET last_error = $1, next_attempt_at = $2
+				WHERE id = $3`,
+		reason,
+		retryAt,
+		id,
+	); err != nil {
+		a.logger.WithError(err).Error("Error while marking email failed!")
+		return domain.ErrInternalDatabase
+	}
+
+	return nil
+}
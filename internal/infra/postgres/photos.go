@@ -0,0 +1,253 @@
+package postgres
+
+import (
+	"backend/internal/domain"
+	"backend/internal/infra/postgres/models"
+	"database/sql"
+	"errors"
+	"github.com/jmoiron/sqlx"
+)
+
+// loadProductPhotos fills in the photos of the product with their variants in their order,
+// only the main one if mainOnly is set. The main photo is the cover one, or the first one if there is no cover.
+func (a *adapter) loadProductPhotos(product *models.Product, mainOnly bool) error {
+	query := `SELECT id, product_id, photo, blob_key, content_type, size, width, height, blurhash, dominant_color,
+					position, cover, caption, created_at
+				FROM product_photos
+				WHERE product_id = $1
+				ORDER BY position, id`
+	if mainOnly {
+		query = `SELECT id, product_id, photo, blob_key, content_type, size, width, height, blurhash, dominant_color,
+					position, cover, caption, created_at
+				FROM product_photos
+				WHERE product_id = $1
+				ORDER BY cover DESC, position, id
+				LIMIT 1`
+	}
+
+	if err := a.db.Select(&product.Photos, query, product.ID); err != nil {
+		a.logger.WithError(err).Error("Error while getting photos by product_id!")
+		return domain.ErrInternalDatabase
+	}
+
+	if len(product.Photos) == 0 {
+		return nil
+	}
+
+	ids := make([]int, 0, len(product.Photos))
+	photos := make(map[int]*models.Photo, len(product.Photos))
+	for _, v := range product.Photos {
+		ids = append(ids, v.ID)
+		photos[v.ID] = v
+	}
+
+	var variants models.PhotoVariants
+	if err := a.db.Select(&variants,
+		`SELECT photo_id, name, blob_key, content_type, width, height, size
+				FROM product_photo_variants
+				WHERE photo_id = ANY($1)
+				ORDER BY photo_id, width`,
+		ids); err != nil {
+		a.logger.WithError(err).Error("Error while getting photo variants by photo_id!")
+		return domain.ErrInternalDatabase
+	}
+
+	for _, v := range variants {
+		photos[v.PhotoID].Variants = append(photos[v.PhotoID].Variants, v)
+	}
+
+	return nil
+}
+
+func (a *adapter) SaveProductPhoto(photo *domain.Photo, maxPhotos int) (int, error) {
+	var id int
+	err := a.inTx(func(tx *sqlx.Tx) error {
+		// the lock serializes the uploads of the product, so that the count and the positions stay consistent.
+		// lockProduct is not used, the photos of the hidden products remain editable
+		var productID int
+		if err := tx.Get(
+			&productID,
+			`SELECT id FROM products WHERE id = $1 AND archived_at IS NULL FOR UPDATE`,
+			photo.ProductID,
+		); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return domain.ErrNoSuchProduct
+			}
+			a.logger.WithError(err).Error("Error while locking product!")
+			return domain.ErrInternalDatabase
+		}
+
+		var count int
+		if err := tx.Get(&count, `SELECT count(id) FROM product_photos WHERE product_id = $1`, photo.ProductID); err != nil {
+			a.logger.WithError(err).Error("Error while getting product photo count!")
+			return domain.ErrInternalDatabase
+		}
+
+		if count >= maxPhotos {
+			return domain.ErrTooManyPhotos
+		}
+
+		if err := tx.Get(
+			&id,
+			`INSERT INTO product_photos (product_id, blob_key, content_type, size, width, height, blurhash, dominant_color, position)
+					VALUES ($1, $2, $3, $4, $5, $6, $7, $8,
+					        (SELECT coalesce(max(position) + 1, 0) FROM product_photos WHERE product_id = $1))
+					RETURNING id`,
+			photo.ProductID,
+			photo.BlobKey,
+			photo.ContentType,
+			photo.Size,
+			photo.Width,
+			photo.Height,
+			photo.Blurhash,
+			photo.DominantColor,
+		); err != nil {
+			a.logger.WithError(err).Error("Error while saving product photo!")
+			return domain.ErrInternalDatabase
+		}
+
+		for _, v := range photo.Variants {
+			if _, err := tx.Exec(
+				`INSERT INTO product_photo_variants (photo_id, name, blob_key, content_type, width, height, size)
+						VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+				id,
+				v.Name,
+				v.BlobKey,
+				v.ContentType,
+				v.Width,
+				v.Height,
+				v.Size,
+			); err != nil {
+				a.logger.WithError(err).Error("Error while saving product photo variant!")
+				return domain.ErrInternalDatabase
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (a *adapter) CountProductPhotos(productID int) (int, error) {
+	var count int
+	if err := a.db.Get(
+		&count,
+		`SELECT count(id) FROM product_photos WHERE product_id = $1`,
+		productID,
+	); err != nil {
+		a.logger.WithError(err).Error("Error while getting product photo count!")
+		return 0, domain.ErrInternalDatabase
+	}
+
+	return count, nil
+}
+
+func (a *adapter) ReorderProductPhotos(productID int, photoIDs []int) error {
+	res, err := a.db.Exec(
+		`UPDATE product_photos p
+				SET position = o.position - 1
+				FROM unnest($2::integer[]) WITH ORDINALITY AS o(id, position)
+				WHERE p.id = o.id AND p.product_id = $1`,
+		productID,
+		photoIDs,
+	)
+	if err != nil {
+		a.logger.WithError(err).Error("Error while reordering product photos!")
+		return domain.ErrInternalDatabase
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		a.logger.WithError(err).Error("Error while getting affected rows!")
+		return domain.ErrInternalDatabase
+	}
+
+	if int(affected) != len(photoIDs) {
+		return domain.ErrInvalidPhotoOrder
+	}
+
+	return nil
+}
+
+func (a *adapter) SetCoverPhoto(productID, photoID int) error {
+	return a.inTx(func(tx *sqlx.Tx) error {
+		// the old cover is cleared first, the unique index is checked row by row
+		if _, err := tx.Exec(
+			`UPDATE product_photos SET cover = false WHERE product_id = $1 AND cover`,
+			productID,
+		); err != nil {
+			a.logger.WithError(err).Error("Error while clearing cover photo!")
+			return domain.ErrInternalDatabase
+		}
+
+		res, err := tx.Exec(
+			`UPDATE product_photos SET cover = true WHERE id = $1 AND product_id = $2`,
+			photoID,
+			productID,
+		)
+		if err != nil {
+			a.logger.WithError(err).Error("Error while setting cover photo!")
+			return domain.ErrInternalDatabase
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			a.logger.WithError(err).Error("Error while getting affected rows!")
+			return domain.ErrInternalDatabase
+		}
+
+		if affected == 0 {
+			return domain.ErrNoSuchPhoto
+		}
+
+		return nil
+	})
+}
+
+func (a *adapter) UpdatePhotoCaption(id int, caption *string) error {
+	res, err := a.db.Exec(
+		`UPDATE product_photos SET caption = $1 WHERE id = $2`,
+		caption,
+		id,
+	)
+	if err != nil {
+		a.logger.WithError(err).Error("Error while updating photo caption!")
+		return domain.ErrInternalDatabase
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		a.logger.WithError(err).Error("Error while getting affected rows!")
+		return domain.ErrInternalDatabase
+	}
+
+	if affected == 0 {
+		return domain.ErrNoSuchPhoto
+	}
+
+	return nil
+}
+
+func (a *adapter) DeleteProductPhoto(id int) error {
+	res, err := a.db.Exec(`DELETE FROM product_photos WHERE id = $1`, id)
+	if err != nil {
+		a.logger.WithError(err).Error("Error while deleting product photo!")
+		return domain.ErrInternalDatabase
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		a.logger.WithError(err).Error("Error while getting affected rows!")
+		return domain.ErrInternalDatabase
+	}
+
+	if affected == 0 {
+		return domain.ErrNoSuchPhoto
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package postgres
+
+import (
+	"backend/internal/domain"
+)
+
+func (a *adapter) SaveReview(review *domain.Review) (int, error) {
+	var id int
+	if err := a.db.Get(
+		&id,
+		`INSERT INTO reviews (order_id, author_id, subject_id, rating, comment)
+				VALUES ($1, $2, $3, $4, $5)
+				RETURNING id`,
+		review.OrderID,
+		review.AuthorID,
+		review.SubjectID,
+		review.Rating,
+		review.Comment,
+	); err != nil {
+		if pgErrorCode(err) == codeUniqueViolation && pgConstraintName(err) == "reviews_order_author_key" {
+			return 0, domain.ErrAlreadyReviewed
+		}
+		a.logger.WithError(err).Error("Error while saving review!")
+		return 0, domain.ErrInternalDatabase
+	}
+
+	return id, nil
+}
+
+func (a *adapter) GetRatingSummary(userID int) (*domain.RatingSummary, error) {
+	var summary struct {
+		Average float64 `db:"average"`
+		Count   int     `db:"count"`
+	}
+
+	if err := a.db.Get(
+		&summary,
+		`SELECT coalesce(avg(rating), 0)::float8 AS average, count(id) AS count
+				FROM reviews
+				WHERE subject_id = $1`,
+		userID,
+	); err != nil {
+		a.logger.WithError(err).Error("Error while getting rating summary!")
+		return nil, domain.ErrInternalDatabase
+	}
+
+	return &domain.RatingSummary{
+		Average: summary.Average,
+		Count:   summary.Count,
+	}, nil
+}
@@ -0,0 +1,67 @@
+package postgres
+
+import (
+	"backend/internal/domain"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"strings"
+)
+
+// syncSearchDictionaries replaces the text search configurations the products are indexed with
+// by the configured ones and rebuilds the search vectors, if they differ.
+func (a *adapter) syncSearchDictionaries() error {
+	if len(a.config.SearchDictionaries) == 0 {
+		return fmt.Errorf("no search dictionaries are configured")
+	}
+
+	var current []string
+	if err := a.db.Select(&current, `SELECT unnest(product_search_dictionaries())::text`); err != nil {
+		return fmt.Errorf("cannot get search dictionaries: %w", err)
+	}
+
+	if strings.Join(current, ",") == strings.Join(a.config.SearchDictionaries, ",") {
+		return nil
+	}
+
+	// the names are put into the function body, so only the existing configurations are accepted
+	var known int
+	if err := a.db.Get(
+		&known,
+		`SELECT count(DISTINCT cfgname) FROM pg_ts_config WHERE cfgname = ANY($1)`,
+		a.config.SearchDictionaries,
+	); err != nil {
+		return fmt.Errorf("cannot check search dictionaries: %w", err)
+	}
+
+	if known != len(a.config.SearchDictionaries) {
+		return fmt.Errorf("unknown search dictionaries among %v", a.config.SearchDictionaries)
+	}
+
+	literals := make([]string, 0, len(a.config.SearchDictionaries))
+	for _, v := range a.config.SearchDictionaries {
+		literals = append(literals, "'"+strings.ReplaceAll(v, "'", "''")+"'")
+	}
+
+	a.logger.WithField("dictionaries", a.config.SearchDictionaries).Info("Rebuilding product search vectors!")
+
+	return a.inTx(func(tx *sqlx.Tx) error {
+		if _, err := tx.Exec(
+			`CREATE OR REPLACE FUNCTION product_search_dictionaries() RETURNS regconfig[]
+					LANGUAGE sql
+					IMMUTABLE AS
+				$$
+				SELECT ARRAY [` + strings.Join(literals, ", ") + `]::regconfig[]
+				$$`,
+		); err != nil {
+			a.logger.WithError(err).Error("Error while replacing search dictionaries!")
+			return domain.ErrInternalDatabase
+		}
+
+		if _, err := tx.Exec(`UPDATE products SET search_vector = product_search_vector(name, description)`); err != nil {
+			a.logger.WithError(err).Error("Error while rebuilding product search vectors!")
+			return domain.ErrInternalDatabase
+		}
+
+		return nil
+	})
+}
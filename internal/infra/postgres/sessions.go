@@ -0,0 +1,165 @@
+package postgres
+
+import (
+	"backend/internal/domain"
+	"backend/internal/infra/postgres/models"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+func (a *adapter) SaveSession(session *domain.Session) (int, error) {
+	var id int
+	if err := a.db.Get(
+		&id,
+		`INSERT INTO sessions (user_id, user_agent, ip, expires_at)
+				VALUES ($1, $2, $3, $4)
+				RETURNING id`,
+		session.UserID,
+		session.UserAgent,
+		session.IP,
+		session.ExpiresAt,
+	); err != nil {
+		a.logger.WithError(err).Error("Error while saving session!")
+		return 0, domain.ErrInternalDatabase
+	}
+
+	return id, nil
+}
+
+func (a *adapter) GetSessionByID(id int) (*domain.Session, error) {
+	var session models.Session
+
+	if err := a.db.Get(
+		&session,
+		`SELECT id, user_id, user_agent, ip, created_at, last_used_at, expires_at, revoked_at, revoke_reason
+				FROM sessions
+				WHERE id = $1`,
+		id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNoSuchSession
+		}
+		a.logger.WithError(err).Error("Error while getting session by id!")
+		return nil, domain.ErrInternalDatabase
+	}
+
+	return session.Domain(), nil
+}
+
+func (a *adapter) GetActiveSessions(userID int) ([]*domain.Session, error) {
+	var sessions models.Sessions
+
+	if err := a.db.Select(&sessions,
+		`SELECT id, user_id, user_agent, ip, created_at, last_used_at, expires_at, revoked_at, revoke_reason
+				FROM sessions
+				WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > now()
+				ORDER BY last_used_at DESC`,
+		userID,
+	); err != nil {
+		a.logger.WithError(err).Error("Error while getting sessions!")
+		return nil, domain.ErrInternalDatabase
+	}
+
+	return sessions.Domain(), nil
+}
+
+func (a *adapter) TouchSession(id int, expiresAt time.Time) error {
+	if _, err := a.db.Exec(
+		`UPDATE sessions
+				SET last_used_at = now(), expires_at = $1
+				WHERE id = $2`,
+		expiresAt,
+		id,
+	); err != nil {
+		a.logger.WithError(err).Error("Error while touching session!")
+		return domain.ErrInternalDatabase
+	}
+
+	return nil
+}
+
+func (a *adapter) RevokeSession(id int, reason string) error {
+	if _, err := a.db.Exec(
+		`UPDATE sessions
+				SET revoked_at = now(), revoke_reason = $1
+				WHERE id = $2 AND revoked_at IS NULL`,
+		reason,
+		id,
+	); err != nil {
+		a.logger.WithError(err).Error("Error while revoking session!")
+		return domain.ErrInternalDatabase
+	}
+
+	return nil
+}
+
+func (a *adapter) RevokeUserSessions(userID, exceptID int, reason string) error {
+	if _, err := a.db.Exec(
+		`UPDATE sessions
+				SET revoked_at = now(), revoke_reason = $1
+				WHERE user_id = $2 AND id <> $3 AND revoked_at IS NULL`,
+		reason,
+		userID,
+		exceptID,
+	); err != nil {
+		a.logger.WithError(err).Error("Error while revoking user sessions!")
+		return domain.ErrInternalDatabase
+	}
+
+	return nil
+}
+
+func (a *adapter) SaveRefreshToken(token *domain.RefreshToken) error {
+	if _, err := a.db.Exec(
+		`INSERT INTO refresh_tokens (session_id, token_hash, expires_at)
+				VALUES ($1, $2, $3)`,
+		token.SessionID,
+		token.Hash,
+		token.ExpiresAt,
+	); err != nil {
+		a.logger.WithError(err).Error("Error while saving refresh token!")
+		return domain.ErrInternalDatabase
+	}
+
+	return nil
+}
+
+func (a *adapter) GetRefreshTokenByHash(hash []byte) (*domain.RefreshToken, error) {
+	var token models.RefreshToken
+
+	if err := a.db.Get(
+		&token,
+		`SELECT id, session_id, token_hash, created_at, expires_at, used_at
+				FROM refresh_tokens
+				WHERE token_hash = $1`,
+		hash); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrUnauthorized
+		}
+		a.logger.WithError(err).Error("Error while getting refresh token!")
+		return nil, domain.ErrInternalDatabase
+	}
+
+	return token.Domain(), nil
+}
+
+func (a *adapter) UseRefreshToken(id int) (bool, error) {
+	res, err := a.db.Exec(
+		`UPDATE refresh_tokens
+				SET used_at = now()
+				WHERE id = $1 AND used_at IS NULL`,
+		id,
+	)
+	if err != nil {
+		a.logger.WithError(err).Error("Error while using refresh token!")
+		return false, domain.ErrInternalDatabase
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		a.logger.WithError(err).Error("Error while getting affected rows!")
+		return false, domain.ErrInternalDatabase
+	}
+
+	return affected == 1, nil
+}
@@ -0,0 +1,133 @@
+package postgres
+
+import (
+	"backend/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+func (a *adapter) SetTOTPSecret(userID int, secret []byte) error {
+	res, err := a.db.Exec(
+		`UPDATE users
+				SET totp_secret = $1, totp_last_step = NULL
+				WHERE id = $2 AND totp_enabled_at IS NULL`,
+		secret,
+		userID,
+	)
+	if err != nil {
+		a.logger.WithError(err).Error("Error while setting TOTP secret!")
+		return domain.ErrInternalDatabase
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		a.logger.WithError(err).Error("Error while getting affected rows!")
+		return domain.ErrInternalDatabase
+	}
+	if affected == 0 {
+		return domain.ErrTwoFactorEnabled
+	}
+
+	return nil
+}
+
+func (a *adapter) EnableTOTP(userID int) error {
+	if _, err := a.db.Exec(
+		`UPDATE users
+				SET totp_enabled_at = now()
+				WHERE id = $1 AND totp_secret IS NOT NULL`,
+		userID,
+	); err != nil {
+		a.logger.WithError(err).Error("Error while enabling TOTP!")
+		return domain.ErrInternalDatabase
+	}
+
+	return nil
+}
+
+func (a *adapter) DisableTOTP(userID int) error {
+	return a.inTx(func(tx *sqlx.Tx) error {
+		if _, err := tx.Exec(
+			`UPDATE users
+					SET totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = NULL
+					WHERE id = $1`,
+			userID,
+		); err != nil {
+			a.logger.WithError(err).Error("Error while disabling TOTP!")
+			return domain.ErrInternalDatabase
+		}
+
+		if _, err := tx.Exec(`DELETE FROM recovery_codes WHERE user_id = $1`, userID); err != nil {
+			a.logger.WithError(err).Error("Error while deleting recovery codes!")
+			return domain.ErrInternalDatabase
+		}
+
+		return nil
+	})
+}
+
+func (a *adapter) UseTOTPStep(userID int, step int64) (bool, error) {
+	res, err := a.db.Exec(
+		`UPDATE users
+				SET totp_last_step = $1
+				WHERE id = $2 AND (totp_last_step IS NULL OR totp_last_step < $1)`,
+		step,
+		userID,
+	)
+	if err != nil {
+		a.logger.WithError(err).Error("Error while using TOTP step!")
+		return false, domain.ErrInternalDatabase
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		a.logger.WithError(err).Error("Error while getting affected rows!")
+		return false, domain.ErrInternalDatabase
+	}
+
+	return affected == 1, nil
+}
+
+func (a *adapter) ReplaceRecoveryCodes(userID int, hashes [][]byte) error {
+	return a.inTx(func(tx *sqlx.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM recovery_codes WHERE user_id = $1`, userID); err != nil {
+			a.logger.WithError(err).Error("Error while deleting recovery codes!")
+			return domain.ErrInternalDatabase
+		}
+
+		for _, hash := range hashes {
+			if _, err := tx.Exec(
+				`INSERT INTO recovery_codes (user_id, code_hash)
+						VALUES ($1, $2)`,
+				userID,
+				hash,
+			); err != nil {
+				a.logger.WithError(err).Error("Error while saving recovery code!")
+				return domain.ErrInternalDatabase
+			}
+		}
+
+		return nil
+	})
+}
+
+func (a *adapter) UseRecoveryCode(userID int, hash []byte) (bool, error) {
+	res, err := a.db.Exec(
+		`UPDATE recovery_codes
+				SET used_at = now()
+				WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`,
+		userID,
+		hash,
+	)
+	if err != nil {
+		a.logger.WithError(err).Error("Error while using recovery code!")
+		return false, domain.ErrInternalDatabase
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		a.logger.WithError(err).Error("Error while getting affected rows!")
+		return false, domain.ErrInternalDatabase
+	}
+
+	return affected == 1, nil
+}
@@ -0,0 +1,53 @@
+package postgres
+
+import (
+	"backend/internal/domain"
+	"database/sql"
+	"errors"
+	"github.com/jmoiron/sqlx"
+)
+
+// inTx runs fn inside a database transaction, which is rolled back if fn returns an error.
+func (a *adapter) inTx(fn func(tx *sqlx.Tx) error) error {
+	tx, err := a.db.Beginx()
+	if err != nil {
+		a.logger.WithError(err).Error("Error while trying to begin a database transaction!")
+		return domain.ErrInternalDatabase
+	}
+
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			a.logger.WithError(err).Error("Error while trying to rollback a database transaction!")
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		a.logger.WithError(err).Error("Error while trying to commit a database transaction!")
+		return domain.ErrInternalDatabase
+	}
+
+	return nil
+}
+
+// lockProduct locks the product row until the end of the transaction,
+// serializing bookings and blackout changes of the product. Archived and hidden products cannot be locked.
+func (a *adapter) lockProduct(tx *sqlx.Tx, productID int) error {
+	var id int
+	if err := tx.Get(
+		&id,
+		`SELECT id FROM products WHERE id = $1 AND archived_at IS NULL AND hidden_at IS NULL FOR UPDATE`,
+		productID,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrNoSuchProduct
+		}
+		a.logger.WithError(err).Error("Error while locking product!")
+		return domain.ErrInternalDatabase
+	}
+
+	return nil
+}
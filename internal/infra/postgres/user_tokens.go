@@ -0,0 +1,80 @@
+package postgres
+
+import (
+	"backend/internal/domain"
+	"backend/internal/infra/postgres/models"
+	"database/sql"
+	"errors"
+)
+
+func (a *adapter) SaveUserToken(token *domain.UserToken) error {
+	if _, err := a.db.Exec(
+		`INSERT INTO user_tokens (user_id, purpose, token_hash, expires_at)
+				VALUES ($1, $2, $3, $4)`,
+		token.UserID,
+		token.Purpose,
+		token.Hash,
+		token.ExpiresAt,
+	); err != nil {
+		a.logger.WithError(err).Error("Error while saving user token!")
+		return domain.ErrInternalDatabase
+	}
+
+	return nil
+}
+
+func (a *adapter) GetUserTokenByHash(purpose string, hash []byte) (*domain.UserToken, error) {
+	var token models.UserToken
+
+	if err := a.db.Get(
+		&token,
+		`SELECT id, user_id, purpose, token_hash, created_at, expires_at, used_at
+				FROM user_tokens
+				WHERE purpose = $1 AND token_hash = $2`,
+		purpose,
+		hash); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrInvalidToken
+		}
+		a.logger.WithError(err).Error("Error while getting user token!")
+		return nil, domain.ErrInternalDatabase
+	}
+
+	return token.Domain(), nil
+}
+
+func (a *adapter) UseUserToken(id int) (bool, error) {
+	res, err := a.db.Exec(
+		`UPDATE user_tokens
+				SET used_at = now()
+				WHERE id = $1 AND used_at IS NULL`,
+		id,
+	)
+	if err != nil {
+		a.logger.WithError(err).Error("Error while using user token!")
+		return false, domain.ErrInternalDatabase
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		a.logger.WithError(err).Error("Error while getting affected rows!")
+		return false, domain.ErrInternalDatabase
+	}
+
+	return affected == 1, nil
+}
+
+func (a *adapter) RevokeUserTokens(userID int, purpose string) error {
+	if _, err := a.db.Exec(
+		`UPDATE user_tokens
+				SET used_at = now()
+				WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL`,
+		userID,
+		purpose,
+	); err != nil {
+		a.logger.WithError(err).Error("Error while revoking user tokens!")
+		return domain.ErrInternalDatabase
+	}
+
+	return nil
+}
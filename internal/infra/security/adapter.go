@@ -4,12 +4,12 @@ import (
 	"backend/internal/domain"
 	"bytes"
 	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
 	"github.com/dgrijalva/jwt-go"
-	"github.com/go-chi/jwtauth"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/crypto/argon2"
-	"io/ioutil"
-	"os"
 	"strconv"
 	"time"
 )
@@ -19,45 +19,65 @@ type adapter struct {
 	config *Config
 
 	// jwt
-	jwtAuth *jwtauth.JWTAuth
+	keys *keyManager
 }
 
-func NewAdapter(logger *logrus.Logger, config *Config) (domain.Security, error) {
+// NewAdapter starts the rotation of the JWT signing keys, the access tokens live for accessTokenTTL.
+func NewAdapter(logger *logrus.Logger, config *Config, accessTokenTTL time.Duration) (domain.Security, error) {
+	if err := config.validate(accessTokenTTL); err != nil {
+		logger.WithError(err).Error("Invalid security config!")
+		return nil, err
+	}
+
 	a := &adapter{
 		logger: logger,
 		config: config,
 	}
 
-	// Read JWT signing key
-	fileWithAccessToken, err := os.Open(a.config.JWTPrivateKey)
+	keys, err := newKeyManager(logger, config)
 	if err != nil {
-		a.logger.WithError(err).Error("Error while opening file!")
+		a.logger.WithError(err).Error("Error while loading JWT keys!")
 		return nil, err
 	}
-	//noinspection ALL
-	defer fileWithAccessToken.Close()
+	a.keys = keys
 
-	bts, err := ioutil.ReadAll(fileWithAccessToken)
-	if err != nil {
-		a.logger.WithError(err).Error("Error while reading file!")
-		return nil, err
-	}
-
-	jwtAuth := jwtauth.New(jwt.SigningMethodHS256.Name, bts, nil)
-	a.jwtAuth = jwtAuth
+	go keys.run()
 
 	return a, nil
 }
 
-func (a *adapter) GenerateNewJWT(id int, duration time.Duration) (string, error) {
-	claims := jwt.StandardClaims{
-		ExpiresAt: time.Now().Add(duration).Unix(),
-		IssuedAt:  time.Now().Unix(),
-		Issuer:    "sharito",
-		Subject:   strconv.Itoa(id),
+// Close stops the rotation of the JWT signing keys.
+func (a *adapter) Close() error {
+	a.keys.close()
+	return nil
+}
+
+const issuer = "sharito"
+
+type claims struct {
+	jwt.StandardClaims
+	SessionID string `json:"sid"`
+	Role      string `json:"role,omitempty"`
+}
+
+func (a *adapter) GenerateNewJWT(c *domain.TokenClaims, duration time.Duration) (string, error) {
+	claims := claims{
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: time.Now().Add(duration).Unix(),
+			IssuedAt:  time.Now().Unix(),
+			Issuer:    issuer,
+			Subject:   strconv.Itoa(c.UserID),
+		},
+		SessionID: strconv.Itoa(c.SessionID),
+		Role:      string(c.Role),
 	}
 
-	_, tokenString, err := a.jwtAuth.Encode(claims)
+	key := a.keys.current()
+
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.kid
+
+	tokenString, err := token.SignedString(key.private)
 	if err != nil {
 		a.logger.WithError(err).Error("Error while encoding JWT token!")
 		return "", domain.ErrJWT
@@ -66,6 +86,85 @@ func (a *adapter) GenerateNewJWT(id int, duration time.Duration) (string, error)
 	return tokenString, nil
 }
 
+func (a *adapter) ParseJWT(tokenString string) (*domain.TokenClaims, error) {
+	var c claims
+
+	if _, err := jwt.ParseWithClaims(tokenString, &c, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+
+		key, ok := a.keys.find(kid)
+		if !ok {
+			return nil, fmt.Errorf("unknown key id %q", kid)
+		}
+
+		// the algorithm is dictated by the key, not by the token
+		if token.Method.Alg() != key.method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method %q", token.Method.Alg())
+		}
+
+		return key.private.Public(), nil
+	}); err != nil {
+		a.logger.WithError(err).Debug("Error while parsing JWT token!")
+		return nil, domain.ErrUnauthorized
+	}
+
+	if c.Issuer != issuer {
+		return nil, domain.ErrUnauthorized
+	}
+
+	userID, err := strconv.Atoi(c.Subject)
+	if err != nil {
+		return nil, domain.ErrUnauthorized
+	}
+
+	sessionID, err := strconv.Atoi(c.SessionID)
+	if err != nil {
+		return nil, domain.ErrUnauthorized
+	}
+
+	// the tokens issued before the roles were introduced belong to plain users
+	role := domain.Role(c.Role)
+	if role == "" {
+		role = domain.RoleUser
+	}
+
+	return &domain.TokenClaims{
+		UserID:    userID,
+		SessionID: sessionID,
+		Role:      role,
+	}, nil
+}
+
+func (a *adapter) PublicKeys() []*domain.PublicKey {
+	keys := a.keys.all()
+
+	res := make([]*domain.PublicKey, 0, len(keys))
+	for _, key := range keys {
+		res = append(res, &domain.PublicKey{
+			KID:       key.kid,
+			Algorithm: key.method.Alg(),
+			Key:       key.private.Public(),
+		})
+	}
+
+	return res
+}
+
+func (a *adapter) GenerateOpaqueToken() (string, error) {
+	bts, err := getRandomBytes(32)
+	if err != nil {
+		a.logger.WithError(err).Error("cannot get random bytes for token")
+		return "", domain.ErrInternalSecurity
+	}
+
+	return base64.RawURLEncoding.EncodeToString(bts), nil
+}
+
+func (a *adapter) HashOpaqueToken(token string) []byte {
+	hash := sha256.Sum256([]byte(token))
+	return hash[:]
+}
+
 func (a *adapter) HashPassword(password string) ([]byte, []byte, error) {
 	salt, err := getRandomBytes(64)
 	if err != nil {
@@ -1,5 +1,34 @@
 package security
 
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
 type Config struct {
-	JWTPrivateKey string `long:"jwt-private-key" env:"JWT_PRIVATE_KEY" description:"Path to JWT private key" required:"yes"`
+	JWTKeysDir            string        `long:"jwt-keys-dir" env:"JWT_KEYS_DIR" description:"Directory with JWT signing keys shared by all instances" required:"yes"`
+	JWTAlgorithm          string        `long:"jwt-algorithm" env:"JWT_ALGORITHM" description:"Algorithm of new JWT signing keys" choice:"RS256" choice:"EdDSA" default:"EdDSA"`
+	JWTKeyRotation        time.Duration `long:"jwt-key-rotation" env:"JWT_KEY_ROTATION" description:"JWT signing key lifetime before a new key is generated" default:"720h"`
+	JWTKeyOverlap         time.Duration `long:"jwt-key-overlap" env:"JWT_KEY_OVERLAP" description:"Period old JWT keys remain valid for verification after the rotation" default:"24h"`
+	JWTKeysReloadInterval time.Duration `long:"jwt-keys-reload-interval" env:"JWT_KEYS_RELOAD_INTERVAL" description:"Interval of reloading JWT keys from the directory" default:"1m"`
+}
+
+// validate checks that a retired key stays valid until the access tokens signed with it expire.
+// The retired key keeps signing until the next one is active, two reload intervals after it is created.
+func (c *Config) validate(accessTokenTTL time.Duration) error {
+	if c.JWTKeysReloadInterval <= 0 {
+		return errors.New("jwt keys reload interval must be positive")
+	}
+
+	if c.JWTKeyRotation <= 0 {
+		return errors.New("jwt key rotation must be positive")
+	}
+
+	if c.JWTKeyOverlap <= accessTokenTTL+2*c.JWTKeysReloadInterval {
+		return fmt.Errorf("jwt key overlap %v must be longer than the access token TTL %v and two reload intervals %v",
+			c.JWTKeyOverlap, accessTokenTTL, c.JWTKeysReloadInterval)
+	}
+
+	return nil
 }
@@ -0,0 +1,291 @@
+package security
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	crand "crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	keyFileExt       = ".pem"
+	keyCreatedHeader = "Created-At"
+	rsaKeyBits       = 2048
+)
+
+type signingKey struct {
+	kid       string
+	method    jwt.SigningMethod
+	private   crypto.Signer
+	createdAt time.Time
+}
+
+// keyManager keeps the JWT signing keys in a directory shared by all instances.
+// A new key is generated every rotation period, the previous keys remain
+// published for verification during the overlap period.
+type keyManager struct {
+	logger logrus.FieldLogger
+	config *Config
+
+	mu   sync.RWMutex
+	keys []*signingKey // sorted by creation time
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+func newKeyManager(logger logrus.FieldLogger, config *Config) (*keyManager, error) {
+	if _, ok := keyGenerators[config.JWTAlgorithm]; !ok {
+		return nil, fmt.Errorf("unsupported JWT algorithm %q", config.JWTAlgorithm)
+	}
+
+	if err := os.MkdirAll(config.JWTKeysDir, 0700); err != nil {
+		return nil, err
+	}
+
+	m := &keyManager{
+		logger: logger,
+		config: config,
+		stop:   make(chan struct{}),
+	}
+
+	if err := m.rotate(); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// run rotates the keys periodically, picking up the keys generated by the other instances.
+func (m *keyManager) run() {
+	ticker := time.NewTicker(m.config.JWTKeysReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+			if err := m.rotate(); err != nil {
+				m.logger.WithError(err).Error("Error while rotating JWT keys!")
+			}
+		}
+	}
+}
+
+// close stops the rotation, it may be called more than once.
+func (m *keyManager) close() {
+	m.stopOnce.Do(func() {
+		close(m.stop)
+	})
+}
+
+// rotate reloads the keys, generates a new one if the newest key is too old and removes the expired ones.
+func (m *keyManager) rotate() error {
+	keys, err := m.load()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	if len(keys) == 0 || now.Sub(keys[len(keys)-1].createdAt) >= m.config.JWTKeyRotation {
+		key, err := m.generate(now)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, key)
+		m.logger.WithField("kid", key.kid).Info("New JWT signing key generated.")
+	}
+
+	// a key expires when the overlap has passed since the next key was created
+	active := make([]*signingKey, 0, len(keys))
+	for i, key := range keys {
+		if i < len(keys)-1 && now.Sub(keys[i+1].createdAt) > m.config.JWTKeyOverlap {
+			if err := os.Remove(m.path(key.kid)); err != nil && !os.IsNotExist(err) {
+				m.logger.WithError(err).WithField("kid", key.kid).Error("Error while removing expired JWT key!")
+			}
+			continue
+		}
+		active = append(active, key)
+	}
+
+	m.mu.Lock()
+	m.keys = active
+	m.mu.Unlock()
+
+	return nil
+}
+
+// current returns the key tokens are signed with. A fresh key is used only after
+// the activation delay, so that the other instances have loaded it by then.
+// The oldest key is used while none of the newer ones is active yet.
+func (m *keyManager) current() *signingKey {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for i := len(m.keys) - 1; i > 0; i-- {
+		if time.Since(m.keys[i].createdAt) >= m.config.JWTKeysReloadInterval*2 {
+			return m.keys[i]
+		}
+	}
+
+	return m.keys[0]
+}
+
+func (m *keyManager) find(kid string) (*signingKey, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, key := range m.keys {
+		if key.kid == kid {
+			return key, true
+		}
+	}
+
+	return nil, false
+}
+
+func (m *keyManager) all() []*signingKey {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	keys := make([]*signingKey, len(m.keys))
+	copy(keys, m.keys)
+
+	return keys
+}
+
+func (m *keyManager) load() ([]*signingKey, error) {
+	files, err := ioutil.ReadDir(m.config.JWTKeysDir)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]*signingKey, 0)
+	for _, f := range files {
+		if f.IsDir() || filepath.Ext(f.Name()) != keyFileExt {
+			continue
+		}
+
+		key, err := m.read(strings.TrimSuffix(f.Name(), keyFileExt))
+		if err != nil {
+			m.logger.WithError(err).WithField("file", f.Name()).Error("Error while reading JWT key!")
+			continue
+		}
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].createdAt.Before(keys[j].createdAt)
+	})
+
+	return keys, nil
+}
+
+func (m *keyManager) read(kid string) (*signingKey, error) {
+	bts, err := ioutil.ReadFile(m.path(kid))
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(bts)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found")
+	}
+
+	createdAt, err := time.Parse(time.RFC3339, block.Headers[keyCreatedHeader])
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s header: %w", keyCreatedHeader, err)
+	}
+
+	private, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &signingKey{
+		kid:       kid,
+		createdAt: createdAt,
+	}
+
+	switch k := private.(type) {
+	case *rsa.PrivateKey:
+		key.method, key.private = jwt.SigningMethodRS256, k
+	case ed25519.PrivateKey:
+		key.method, key.private = SigningMethodEdDSA, k
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", private)
+	}
+
+	return key, nil
+}
+
+func (m *keyManager) generate(now time.Time) (*signingKey, error) {
+	kidBytes := make([]byte, 8)
+	if _, err := crand.Read(kidBytes); err != nil {
+		return nil, err
+	}
+
+	key := &signingKey{
+		kid:       hex.EncodeToString(kidBytes),
+		createdAt: now.UTC().Truncate(time.Second),
+	}
+
+	var err error
+	key.method, key.private, err = keyGenerators[m.config.JWTAlgorithm]()
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key.private)
+	if err != nil {
+		return nil, err
+	}
+
+	bts := pem.EncodeToMemory(&pem.Block{
+		Type:    "PRIVATE KEY",
+		Headers: map[string]string{keyCreatedHeader: key.createdAt.Format(time.RFC3339)},
+		Bytes:   der,
+	})
+
+	// the key is written under a temporary name first, so that the other instances never read a partial file
+	tmp := m.path(key.kid) + ".tmp"
+	if err := ioutil.WriteFile(tmp, bts, 0600); err != nil {
+		return nil, err
+	}
+
+	if err := os.Rename(tmp, m.path(key.kid)); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func (m *keyManager) path(kid string) string {
+	return filepath.Join(m.config.JWTKeysDir, kid+keyFileExt)
+}
+
+var keyGenerators = map[string]func() (jwt.SigningMethod, crypto.Signer, error){
+	jwt.SigningMethodRS256.Alg(): func() (jwt.SigningMethod, crypto.Signer, error) {
+		key, err := rsa.GenerateKey(crand.Reader, rsaKeyBits)
+		return jwt.SigningMethodRS256, key, err
+	},
+	SigningMethodEdDSA.Alg(): func() (jwt.SigningMethod, crypto.Signer, error) {
+		_, key, err := ed25519.GenerateKey(crand.Reader)
+		return SigningMethodEdDSA, key, err
+	},
+}
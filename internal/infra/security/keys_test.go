@@ -0,0 +1,116 @@
+package security
+
+import (
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func TestKeyManagerCurrent(t *testing.T) {
+	const reload = time.Minute
+	now := time.Now()
+
+	key := func(kid string, age time.Duration) *signingKey {
+		return &signingKey{kid: kid, createdAt: now.Add(-age)}
+	}
+
+	tests := []struct {
+		name string
+		keys []*signingKey
+		want string
+	}{
+		{
+			name: "only key",
+			keys: []*signingKey{key("fresh", 0)},
+			want: "fresh",
+		},
+		{
+			name: "fresh key is not active yet",
+			keys: []*signingKey{key("old", time.Hour), key("fresh", reload)},
+			want: "old",
+		},
+		{
+			name: "fresh key is active after two reloads",
+			keys: []*signingKey{key("old", time.Hour), key("fresh", 2*reload)},
+			want: "fresh",
+		},
+		{
+			name: "newest active key wins",
+			keys: []*signingKey{key("old", 2*time.Hour), key("active", time.Hour), key("fresh", 0)},
+			want: "active",
+		},
+		{
+			name: "oldest key is used while none of the newer is active",
+			keys: []*signingKey{key("old", time.Hour), key("newer", reload), key("fresh", 0)},
+			want: "old",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &keyManager{
+				config: &Config{JWTKeysReloadInterval: reload},
+				keys:   tt.keys,
+			}
+
+			if got := m.current().kid; got != tt.want {
+				t.Errorf("current() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		overlap time.Duration
+		reload  time.Duration
+		wantErr bool
+	}{
+		{name: "defaults", overlap: 24 * time.Hour, reload: time.Minute},
+		{name: "overlap covers the token and the activation", overlap: 18 * time.Minute, reload: time.Minute},
+		{name: "overlap shorter than the token", overlap: 10 * time.Minute, reload: time.Minute, wantErr: true},
+		{name: "overlap equal to the token", overlap: 15 * time.Minute, reload: time.Second, wantErr: true},
+		{name: "overlap ends before the activation", overlap: 16 * time.Minute, reload: time.Minute, wantErr: true},
+		{name: "no reload interval", overlap: 24 * time.Hour, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		c := &Config{JWTKeyRotation: 720 * time.Hour, JWTKeyOverlap: tt.overlap, JWTKeysReloadInterval: tt.reload}
+		if err := c.validate(15 * time.Minute); (err != nil) != tt.wantErr {
+			t.Errorf("validate(%s) error = %v, want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestKeyManagerClose(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+
+	m, err := newKeyManager(logger, &Config{
+		JWTKeysDir:            t.TempDir(),
+		JWTAlgorithm:          "EdDSA",
+		JWTKeyRotation:        time.Hour,
+		JWTKeyOverlap:         time.Hour,
+		JWTKeysReloadInterval: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		m.run()
+		close(done)
+	}()
+
+	m.close()
+	m.close()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("run() did not return after close()")
+	}
+}
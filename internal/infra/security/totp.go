@@ -0,0 +1,117 @@
+package security
+
+import (
+	"backend/internal/domain"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// TOTP parameters of RFC 6238 supported by all authenticator apps.
+const (
+	totpPeriod     = 30
+	totpDigits     = 6
+	totpSecretSize = 20
+	// totpSkew is the number of periods the code is accepted before and after the current one,
+	// it tolerates the clock drift of the devices
+	totpSkew = 1
+
+	totpIssuer = "Sharito"
+)
+
+// recoveryCodeAlphabet is the Crockford's base32, it has no letters easily mistaken for digits.
+const recoveryCodeAlphabet = "0123456789abcdefghjkmnpqrstvwxyz"
+
+// recoveryCodeReplacer normalizes the recovery code typed by the user.
+var recoveryCodeReplacer = strings.NewReplacer("-", "", " ", "", "o", "0", "i", "1", "l", "1")
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+func (a *adapter) GenerateTOTPSecret() ([]byte, error) {
+	secret, err := getRandomBytes(totpSecretSize)
+	if err != nil {
+		a.logger.WithError(err).Error("cannot get random bytes for TOTP secret")
+		return nil, domain.ErrInternalSecurity
+	}
+
+	return secret, nil
+}
+
+func (a *adapter) TOTPKeyURI(secret []byte, account string) string {
+	params := url.Values{}
+	params.Set("secret", totpEncoding.EncodeToString(secret))
+	params.Set("issuer", totpIssuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(totpDigits))
+	params.Set("period", fmt.Sprint(totpPeriod))
+
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + totpIssuer + ":" + account,
+		RawQuery: params.Encode(),
+	}
+
+	return u.String()
+}
+
+func (a *adapter) ValidateTOTP(secret []byte, code string, at time.Time) (int64, bool) {
+	code = strings.TrimSpace(code)
+	if len(code) != totpDigits {
+		return 0, false
+	}
+
+	step := at.Unix() / totpPeriod
+	for i := -totpSkew; i <= totpSkew; i++ {
+		if subtle.ConstantTimeCompare([]byte(totpCode(secret, step+int64(i))), []byte(code)) == 1 {
+			return step + int64(i), true
+		}
+	}
+
+	return 0, false
+}
+
+// totpCode computes the HOTP value of RFC 4226 for the time step.
+func totpCode(secret []byte, step int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < totpDigits; i++ {
+		mod *= 10
+	}
+
+	return fmt.Sprintf("%0*d", totpDigits, value%mod)
+}
+
+func (a *adapter) GenerateRecoveryCode() (string, error) {
+	bts, err := getRandomBytes(10)
+	if err != nil {
+		a.logger.WithError(err).Error("cannot get random bytes for recovery code")
+		return "", domain.ErrInternalSecurity
+	}
+
+	code := make([]byte, len(bts))
+	for i, b := range bts {
+		code[i] = recoveryCodeAlphabet[b&31]
+	}
+
+	return string(code[:5]) + "-" + string(code[5:]), nil
+}
+
+func (a *adapter) HashRecoveryCode(code string) []byte {
+	return a.HashOpaqueToken(recoveryCodeReplacer.Replace(strings.ToLower(code)))
+}
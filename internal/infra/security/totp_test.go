@@ -0,0 +1,62 @@
+package security
+
+import (
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA-1 seed of the test vectors of RFC 6238 Appendix B.
+var rfc6238Secret = []byte("12345678901234567890")
+
+func TestTOTPCode(t *testing.T) {
+	// the vectors have 8 digits, the 6 digit codes are their last digits
+	tests := []struct {
+		time int64
+		want string
+	}{
+		{time: 59, want: "287082"},          // 94287082
+		{time: 1111111109, want: "081804"},  // 07081804
+		{time: 1111111111, want: "050471"},  // 14050471
+		{time: 1234567890, want: "005924"},  // 89005924
+		{time: 2000000000, want: "279037"},  // 69279037
+		{time: 20000000000, want: "353130"}, // 65353130
+	}
+
+	for _, tt := range tests {
+		if got := totpCode(rfc6238Secret, tt.time/totpPeriod); got != tt.want {
+			t.Errorf("totpCode(%d) = %s, want %s", tt.time, got, tt.want)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	a := &adapter{}
+	at := time.Unix(1111111111, 0)
+	step := at.Unix() / totpPeriod
+
+	tests := []struct {
+		name     string
+		code     string
+		wantStep int64
+		wantOK   bool
+	}{
+		{name: "current step", code: totpCode(rfc6238Secret, step), wantStep: step, wantOK: true},
+		{name: "previous step", code: totpCode(rfc6238Secret, step-1), wantStep: step - 1, wantOK: true},
+		{name: "next step", code: totpCode(rfc6238Secret, step+1), wantStep: step + 1, wantOK: true},
+		{name: "two steps before", code: totpCode(rfc6238Secret, step-2)},
+		{name: "two steps after", code: totpCode(rfc6238Secret, step+2)},
+		{name: "surrounding spaces", code: " " + totpCode(rfc6238Secret, step) + " ", wantStep: step, wantOK: true},
+		{name: "8 digits", code: "14050471"},
+		{name: "empty", code: ""},
+		{name: "letters", code: "abcdef"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStep, gotOK := a.ValidateTOTP(rfc6238Secret, tt.code, at)
+			if gotOK != tt.wantOK || gotStep != tt.wantStep {
+				t.Errorf("ValidateTOTP(%q) = %d, %v, want %d, %v", tt.code, gotStep, gotOK, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
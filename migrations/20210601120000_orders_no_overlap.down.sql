@@ -0,0 +1,5 @@
+ALTER TABLE orders
+    DROP CONSTRAINT IF EXISTS orders_no_overlap;
+
+ALTER TABLE orders
+    DROP CONSTRAINT IF EXISTS orders_period_check;
@@ -0,0 +1,40 @@
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+-- the orders are not guessed about, the conflicting ones have to be fixed or deleted by hand
+DO
+$$
+    DECLARE
+        inverted    TEXT;
+        overlapping TEXT;
+    BEGIN
+        SELECT string_agg(id::TEXT, ', ' ORDER BY id)
+        INTO inverted
+        FROM orders
+        WHERE order_start >= order_end;
+
+        IF inverted IS NOT NULL THEN
+            RAISE EXCEPTION 'orders % do not end after they start, fix or delete them before adding orders_period_check',
+                inverted;
+        END IF;
+
+        SELECT string_agg(a.id || ' and ' || b.id, ', ' ORDER BY a.id, b.id)
+        INTO overlapping
+        FROM orders a
+                 JOIN orders b ON b.product_id = a.product_id AND b.id > a.id
+        WHERE a.order_start < b.order_end
+          AND b.order_start < a.order_end;
+
+        IF overlapping IS NOT NULL THEN
+            RAISE EXCEPTION 'orders % overlap, fix or delete them before adding orders_no_overlap', overlapping;
+        END IF;
+    END
+$$;
+
+ALTER TABLE orders
+    ADD CONSTRAINT orders_period_check CHECK (order_start < order_end);
+
+ALTER TABLE orders
+    ADD CONSTRAINT orders_no_overlap EXCLUDE USING gist (
+        product_id WITH =,
+        tstzrange(order_start, order_end) WITH &&
+    );
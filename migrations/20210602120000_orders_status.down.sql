@@ -0,0 +1,26 @@
+ALTER TABLE orders
+    DROP CONSTRAINT IF EXISTS orders_no_overlap;
+
+ALTER TABLE orders
+    DROP CONSTRAINT IF EXISTS orders_status_check;
+
+-- the rejected and cancelled orders may overlap the live ones, which the constraint
+-- without the status filter does not allow, and they were never bookings before the lifecycle
+DELETE
+FROM orders
+WHERE status IN ('rejected', 'cancelled');
+
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS status,
+    DROP COLUMN IF EXISTS approved_at,
+    DROP COLUMN IF EXISTS rejected_at,
+    DROP COLUMN IF EXISTS activated_at,
+    DROP COLUMN IF EXISTS completed_at,
+    DROP COLUMN IF EXISTS cancelled_at,
+    DROP COLUMN IF EXISTS updated_at;
+
+ALTER TABLE orders
+    ADD CONSTRAINT orders_no_overlap EXCLUDE USING gist (
+        product_id WITH =,
+        tstzrange(order_start, order_end) WITH &&
+    );
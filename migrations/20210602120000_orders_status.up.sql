@@ -0,0 +1,27 @@
+ALTER TABLE orders
+    ADD COLUMN status       TEXT NOT NULL DEFAULT 'pending',
+    ADD COLUMN approved_at  TIMESTAMPTZ,
+    ADD COLUMN rejected_at  TIMESTAMPTZ,
+    ADD COLUMN activated_at TIMESTAMPTZ,
+    ADD COLUMN completed_at TIMESTAMPTZ,
+    ADD COLUMN cancelled_at TIMESTAMPTZ,
+    ADD COLUMN updated_at   TIMESTAMPTZ DEFAULT now();
+
+ALTER TABLE orders
+    ADD CONSTRAINT orders_status_check
+        CHECK (status IN ('pending', 'approved', 'rejected', 'active', 'completed', 'cancelled'));
+
+-- orders created before the lifecycle existed were accepted right away
+UPDATE orders
+SET status      = 'approved',
+    approved_at = created_at;
+
+-- rejected and cancelled orders must not block the product
+ALTER TABLE orders
+    DROP CONSTRAINT IF EXISTS orders_no_overlap;
+
+ALTER TABLE orders
+    ADD CONSTRAINT orders_no_overlap EXCLUDE USING gist (
+        product_id WITH =,
+        tstzrange(order_start, order_end) WITH &&
+    ) WHERE (status NOT IN ('rejected', 'cancelled'));
@@ -0,0 +1,9 @@
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS refund_amount,
+    DROP COLUMN IF EXISTS cancelled_by;
+
+ALTER TABLE products
+    DROP CONSTRAINT IF EXISTS products_cancellation_policy_check;
+
+ALTER TABLE products
+    DROP COLUMN IF EXISTS cancellation_policy;
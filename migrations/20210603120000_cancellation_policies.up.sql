@@ -0,0 +1,10 @@
+ALTER TABLE products
+    ADD COLUMN cancellation_policy TEXT NOT NULL DEFAULT 'flexible';
+
+ALTER TABLE products
+    ADD CONSTRAINT products_cancellation_policy_check
+        CHECK (cancellation_policy IN ('flexible', 'moderate', 'strict'));
+
+ALTER TABLE orders
+    ADD COLUMN cancelled_by  INTEGER REFERENCES users (id),
+    ADD COLUMN refund_amount NUMERIC;
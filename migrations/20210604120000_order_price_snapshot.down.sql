@@ -0,0 +1,9 @@
+ALTER TABLE orders
+    ALTER COLUMN refund_amount TYPE NUMERIC;
+
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS price;
+
+ALTER TABLE products
+    DROP COLUMN IF EXISTS per_week,
+    DROP COLUMN IF EXISTS per_day;
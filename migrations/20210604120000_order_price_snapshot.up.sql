@@ -0,0 +1,18 @@
+ALTER TABLE products
+    ADD COLUMN per_day  NUMERIC,
+    ADD COLUMN per_week NUMERIC;
+
+ALTER TABLE orders
+    ADD COLUMN price NUMERIC(12, 2);
+
+-- snapshot the price of existing orders using the current hourly rate
+UPDATE orders o
+SET price = round(ceil(extract(EPOCH FROM o.order_end - o.order_start) / 3600) * p.per_hour, 2)
+FROM products p
+WHERE p.id = o.product_id;
+
+ALTER TABLE orders
+    ALTER COLUMN price SET NOT NULL;
+
+ALTER TABLE orders
+    ALTER COLUMN refund_amount TYPE NUMERIC(12, 2);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS product_blackouts
+(
+    id         INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    product_id INTEGER REFERENCES products (id) NOT NULL,
+    starts_at  TIMESTAMPTZ                      NOT NULL,
+    ends_at    TIMESTAMPTZ                      NOT NULL,
+    reason     TEXT,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    CONSTRAINT product_blackouts_period_check CHECK (starts_at < ends_at)
+);
+
+CREATE INDEX IF NOT EXISTS product_blackouts_period_idx
+    ON product_blackouts USING gist (product_id, tstzrange(starts_at, ends_at));
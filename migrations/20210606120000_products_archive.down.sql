@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS products_not_archived_idx;
+
+ALTER TABLE products
+    DROP COLUMN IF EXISTS archived_at,
+    DROP COLUMN IF EXISTS updated_at;
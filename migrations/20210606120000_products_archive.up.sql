@@ -0,0 +1,6 @@
+ALTER TABLE products
+    ADD COLUMN updated_at  TIMESTAMPTZ DEFAULT now(),
+    ADD COLUMN archived_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS products_not_archived_idx
+    ON products (id) WHERE archived_at IS NULL;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS users_email_key;
+
+DROP INDEX IF EXISTS users_login_key;
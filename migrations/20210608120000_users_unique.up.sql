@@ -0,0 +1,34 @@
+-- the accounts sharing a login or an email are not merged or renamed here, they have to be resolved by hand
+DO
+$$
+    DECLARE
+        logins TEXT;
+        emails TEXT;
+    BEGIN
+        SELECT string_agg(format('%s (users %s)', login, ids), ', ')
+        INTO logins
+        FROM (SELECT lower(login) AS login, string_agg(id::TEXT, ', ' ORDER BY id) AS ids
+              FROM users
+              GROUP BY lower(login)
+              HAVING count(*) > 1) duplicates;
+
+        IF logins IS NOT NULL THEN
+            RAISE EXCEPTION 'logins % are shared by several users ignoring case, rename the users before adding users_login_key', logins;
+        END IF;
+
+        SELECT string_agg(format('%s (users %s)', email, ids), ', ')
+        INTO emails
+        FROM (SELECT lower(email) AS email, string_agg(id::TEXT, ', ' ORDER BY id) AS ids
+              FROM users
+              GROUP BY lower(email)
+              HAVING count(*) > 1) duplicates;
+
+        IF emails IS NOT NULL THEN
+            RAISE EXCEPTION 'emails % are shared by several users ignoring case, change them before adding users_email_key', emails;
+        END IF;
+    END
+$$;
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_login_key ON users (lower(login));
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_key ON users (lower(email));
@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS sessions
+(
+    id            INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    user_id       INTEGER REFERENCES users (id) NOT NULL,
+    user_agent    TEXT,
+    ip            TEXT,
+    created_at    TIMESTAMPTZ DEFAULT now(),
+    last_used_at  TIMESTAMPTZ DEFAULT now(),
+    expires_at    TIMESTAMPTZ                   NOT NULL,
+    revoked_at    TIMESTAMPTZ,
+    revoke_reason TEXT
+);
+
+CREATE INDEX IF NOT EXISTS sessions_user_id_idx ON sessions (user_id);
+
+CREATE TABLE IF NOT EXISTS refresh_tokens
+(
+    id         INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    session_id INTEGER REFERENCES sessions (id) NOT NULL,
+    token_hash BYTEA                            NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    expires_at TIMESTAMPTZ                      NOT NULL,
+    used_at    TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS refresh_tokens_session_id_idx ON refresh_tokens (session_id);
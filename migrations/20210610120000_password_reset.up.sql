@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS user_tokens
+(
+    id         INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    user_id    INTEGER REFERENCES users (id) NOT NULL,
+    purpose    TEXT                          NOT NULL,
+    token_hash BYTEA                         NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    expires_at TIMESTAMPTZ                   NOT NULL,
+    used_at    TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS user_tokens_user_id_idx ON user_tokens (user_id, purpose);
+
+CREATE TABLE IF NOT EXISTS email_outbox
+(
+    id              INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    recipient       TEXT    NOT NULL,
+    subject         TEXT    NOT NULL,
+    body            TEXT    NOT NULL,
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ      DEFAULT now(),
+    last_error      TEXT,
+    created_at      TIMESTAMPTZ      DEFAULT now(),
+    sent_at         TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS email_outbox_pending_idx
+    ON email_outbox (next_attempt_at) WHERE sent_at IS NULL;
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS login_throttles
+(
+    key             TEXT PRIMARY KEY,
+    failures        INTEGER     NOT NULL,
+    last_failure_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS login_attempts
+(
+    id         INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    login      TEXT NOT NULL,
+    user_id    INTEGER REFERENCES users (id),
+    ip         TEXT,
+    user_agent TEXT,
+    reason     TEXT NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS login_attempts_user_id_idx ON login_attempts (user_id, created_at);
+CREATE INDEX IF NOT EXISTS login_attempts_ip_idx ON login_attempts (ip, created_at);
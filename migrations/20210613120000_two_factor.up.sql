@@ -0,0 +1,14 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS totp_secret     BYTEA,
+    ADD COLUMN IF NOT EXISTS totp_enabled_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS totp_last_step  BIGINT;
+
+CREATE TABLE IF NOT EXISTS recovery_codes
+(
+    id         INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    user_id    INTEGER REFERENCES users (id) NOT NULL,
+    code_hash  BYTEA                         NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    used_at    TIMESTAMPTZ,
+    UNIQUE (user_id, code_hash)
+);
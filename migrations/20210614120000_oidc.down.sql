@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS oidc_logins;
+
+DROP TABLE IF EXISTS user_identities;
+
+UPDATE users
+SET password_hash = '', salt = ''
+WHERE password_hash IS NULL;
+
+ALTER TABLE users
+    ALTER COLUMN password_hash SET NOT NULL,
+    ALTER COLUMN salt SET NOT NULL;
@@ -0,0 +1,30 @@
+-- the users registered with an external identity have no password
+ALTER TABLE users
+    ALTER COLUMN password_hash DROP NOT NULL,
+    ALTER COLUMN salt DROP NOT NULL;
+
+CREATE TABLE IF NOT EXISTS user_identities
+(
+    id         INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    user_id    INTEGER REFERENCES users (id) NOT NULL,
+    provider   TEXT                          NOT NULL,
+    subject    TEXT                          NOT NULL,
+    email      TEXT,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    CONSTRAINT user_identities_provider_subject_key UNIQUE (provider, subject)
+);
+
+CREATE INDEX IF NOT EXISTS user_identities_user_id_idx ON user_identities (user_id);
+
+CREATE TABLE IF NOT EXISTS oidc_logins
+(
+    id            INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    state_hash    BYTEA NOT NULL UNIQUE,
+    provider      TEXT  NOT NULL,
+    code_verifier TEXT  NOT NULL,
+    nonce         TEXT  NOT NULL,
+    user_id       INTEGER REFERENCES users (id),
+    created_at    TIMESTAMPTZ DEFAULT now(),
+    expires_at    TIMESTAMPTZ NOT NULL,
+    used_at       TIMESTAMPTZ
+);
@@ -0,0 +1,8 @@
+ALTER TABLE products
+    DROP COLUMN IF EXISTS hidden_reason,
+    DROP COLUMN IF EXISTS hidden_at;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS block_reason,
+    DROP COLUMN IF EXISTS blocked_at,
+    DROP COLUMN IF EXISTS role;
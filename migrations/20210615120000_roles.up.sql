@@ -0,0 +1,10 @@
+-- the first admin is appointed manually: UPDATE users SET role = 'admin' WHERE login = '...';
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS role         TEXT NOT NULL DEFAULT 'user'
+        CONSTRAINT users_role_check CHECK (role IN ('user', 'moderator', 'admin')),
+    ADD COLUMN IF NOT EXISTS blocked_at   TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS block_reason TEXT;
+
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS hidden_at     TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS hidden_reason TEXT;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS reviews
+(
+    id         INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    order_id   INTEGER REFERENCES orders (id) NOT NULL,
+    author_id  INTEGER REFERENCES users (id)  NOT NULL,
+    subject_id INTEGER REFERENCES users (id)  NOT NULL,
+    rating     SMALLINT                       NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    comment    TEXT,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    CONSTRAINT reviews_order_author_key UNIQUE (order_id, author_id)
+);
+
+CREATE INDEX IF NOT EXISTS reviews_subject_id_idx ON reviews (subject_id);
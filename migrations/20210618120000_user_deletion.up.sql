@@ -0,0 +1,3 @@
+-- the deleted users stay anonymised, so that their orders remain intact for the other party
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
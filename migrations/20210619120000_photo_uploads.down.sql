@@ -0,0 +1,8 @@
+DELETE FROM product_photos WHERE photo IS NULL;
+
+ALTER TABLE product_photos
+    DROP CONSTRAINT IF EXISTS product_photos_source_check,
+    DROP COLUMN IF EXISTS blob_key,
+    DROP COLUMN IF EXISTS content_type,
+    DROP COLUMN IF EXISTS size,
+    ALTER COLUMN photo SET NOT NULL;
@@ -0,0 +1,8 @@
+-- the uploaded photos are kept in the blob store,
+-- the photo column holds the external URLs of the photos added before the uploads
+ALTER TABLE product_photos
+    ALTER COLUMN photo DROP NOT NULL,
+    ADD COLUMN IF NOT EXISTS blob_key     TEXT UNIQUE,
+    ADD COLUMN IF NOT EXISTS content_type TEXT,
+    ADD COLUMN IF NOT EXISTS size         BIGINT,
+    ADD CONSTRAINT product_photos_source_check CHECK (photo IS NOT NULL OR blob_key IS NOT NULL);
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS product_photo_variants;
+
+ALTER TABLE product_photos
+    DROP COLUMN IF EXISTS width,
+    DROP COLUMN IF EXISTS height,
+    DROP COLUMN IF EXISTS blurhash,
+    DROP COLUMN IF EXISTS dominant_color;
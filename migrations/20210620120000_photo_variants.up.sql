@@ -0,0 +1,18 @@
+ALTER TABLE product_photos
+    ADD COLUMN IF NOT EXISTS width          INTEGER,
+    ADD COLUMN IF NOT EXISTS height         INTEGER,
+    ADD COLUMN IF NOT EXISTS blurhash       TEXT,
+    ADD COLUMN IF NOT EXISTS dominant_color TEXT;
+
+-- the resized copies of the uploaded photos, the external photos have none
+CREATE TABLE IF NOT EXISTS product_photo_variants
+(
+    photo_id     INTEGER REFERENCES product_photos (id) ON DELETE CASCADE NOT NULL,
+    name         TEXT                                                     NOT NULL,
+    blob_key     TEXT                                                     NOT NULL UNIQUE,
+    content_type TEXT                                                     NOT NULL,
+    width        INTEGER                                                  NOT NULL,
+    height       INTEGER                                                  NOT NULL,
+    size         BIGINT                                                   NOT NULL,
+    PRIMARY KEY (photo_id, name)
+);
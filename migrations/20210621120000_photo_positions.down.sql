@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS product_photos_cover_idx;
+
+ALTER TABLE product_photos
+    DROP COLUMN IF EXISTS position,
+    DROP COLUMN IF EXISTS cover,
+    DROP COLUMN IF EXISTS caption;
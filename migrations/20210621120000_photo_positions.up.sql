@@ -0,0 +1,16 @@
+ALTER TABLE product_photos
+    ADD COLUMN IF NOT EXISTS position INTEGER,
+    ADD COLUMN IF NOT EXISTS cover    BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS caption  TEXT;
+
+-- the photos keep the order they were added in
+UPDATE product_photos p
+SET position = o.position
+FROM (SELECT id, row_number() OVER (PARTITION BY product_id ORDER BY created_at, id) - 1 AS position
+      FROM product_photos) o
+WHERE p.id = o.id;
+
+ALTER TABLE product_photos
+    ALTER COLUMN position SET NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS product_photos_cover_idx ON product_photos (product_id) WHERE cover;
@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS products_name_trgm_idx;
+DROP INDEX IF EXISTS products_search_vector_idx;
+
+DROP TRIGGER IF EXISTS products_search_vector_update ON products;
+DROP FUNCTION IF EXISTS products_search_vector_update();
+
+ALTER TABLE products
+    DROP COLUMN IF EXISTS search_vector;
+
+DROP FUNCTION IF EXISTS product_search_query(TEXT);
+DROP FUNCTION IF EXISTS product_search_vector(TEXT, TEXT);
+DROP FUNCTION IF EXISTS product_search_dictionaries();
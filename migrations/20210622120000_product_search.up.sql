@@ -0,0 +1,76 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- the text search configurations the products are indexed with,
+-- the database adapter replaces the function when the configured ones change
+CREATE OR REPLACE FUNCTION product_search_dictionaries() RETURNS regconfig[]
+    LANGUAGE sql
+    IMMUTABLE AS
+$$
+SELECT ARRAY ['russian', 'english']::regconfig[]
+$$;
+
+-- the name weighs more than the description
+CREATE OR REPLACE FUNCTION product_search_vector(name TEXT, description TEXT) RETURNS tsvector
+    LANGUAGE plpgsql
+    IMMUTABLE AS
+$$
+DECLARE
+    dictionary regconfig;
+    result     tsvector := ''::tsvector;
+BEGIN
+    FOREACH dictionary IN ARRAY product_search_dictionaries()
+        LOOP
+            result := result ||
+                      setweight(to_tsvector(dictionary, coalesce(name, '')), 'A') ||
+                      setweight(to_tsvector(dictionary, coalesce(description, '')), 'B');
+        END LOOP;
+    RETURN result;
+END
+$$;
+
+-- the query matches the words stemmed by any of the dictionaries
+CREATE OR REPLACE FUNCTION product_search_query(search TEXT) RETURNS tsquery
+    LANGUAGE plpgsql
+    IMMUTABLE AS
+$$
+DECLARE
+    dictionary regconfig;
+    result     tsquery;
+BEGIN
+    FOREACH dictionary IN ARRAY product_search_dictionaries()
+        LOOP
+            IF result IS NULL THEN
+                result := websearch_to_tsquery(dictionary, search);
+            ELSE
+                result := result || websearch_to_tsquery(dictionary, search);
+            END IF;
+        END LOOP;
+    RETURN result;
+END
+$$;
+
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS search_vector tsvector;
+
+CREATE OR REPLACE FUNCTION products_search_vector_update() RETURNS trigger
+    LANGUAGE plpgsql AS
+$$
+BEGIN
+    NEW.search_vector := product_search_vector(NEW.name, NEW.description);
+    RETURN NEW;
+END
+$$;
+
+DROP TRIGGER IF EXISTS products_search_vector_update ON products;
+CREATE TRIGGER products_search_vector_update
+    BEFORE INSERT OR UPDATE OF name, description
+    ON products
+    FOR EACH ROW
+EXECUTE PROCEDURE products_search_vector_update();
+
+UPDATE products
+SET search_vector = product_search_vector(name, description);
+
+CREATE INDEX IF NOT EXISTS products_search_vector_idx ON products USING gin (search_vector);
+-- the trigram index lets the misspelled names match
+CREATE INDEX IF NOT EXISTS products_name_trgm_idx ON products USING gin (name gin_trgm_ops);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS products_owner_id_idx;
+DROP INDEX IF EXISTS products_per_hour_idx;
+DROP INDEX IF EXISTS products_category_idx;
+
+ALTER TABLE products
+    DROP CONSTRAINT IF EXISTS products_category_check,
+    DROP COLUMN IF EXISTS category;
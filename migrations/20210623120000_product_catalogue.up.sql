@@ -0,0 +1,10 @@
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS category TEXT NOT NULL DEFAULT 'other';
+
+ALTER TABLE products
+    ADD CONSTRAINT products_category_check
+        CHECK (category IN ('tools', 'electronics', 'sports', 'outdoor', 'vehicles', 'home', 'events', 'other'));
+
+CREATE INDEX IF NOT EXISTS products_category_idx ON products (category);
+CREATE INDEX IF NOT EXISTS products_per_hour_idx ON products (per_hour);
+CREATE INDEX IF NOT EXISTS products_owner_id_idx ON products (owner_id);
@@ -0,0 +1,6 @@
+-- the avatars were arbitrary URLs given by the users, now they are uploaded to the blob store like the photos
+ALTER TABLE users
+    DROP COLUMN IF EXISTS avatar;
+
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS avatar_key TEXT;
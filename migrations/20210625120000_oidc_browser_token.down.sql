@@ -0,0 +1,2 @@
+ALTER TABLE oidc_logins
+    DROP COLUMN IF EXISTS browser_token_hash;
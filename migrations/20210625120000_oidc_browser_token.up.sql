@@ -0,0 +1,3 @@
+-- ties the login to the browser that started it, the pending logins started before cannot be finished anymore
+ALTER TABLE oidc_logins
+    ADD COLUMN IF NOT EXISTS browser_token_hash BYTEA;